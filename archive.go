@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveFileRegex nom d'un fichier d'archive mensuel
+var archiveFileRegex = regexp.MustCompile(`^\d{4}-\d{2}\.json$`)
+
+// Archive tâches terminées d'un mois, dans archive/YYYY-MM.json
+type Archive struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Month         string `json:"month"`
+	Tasks         []Task `json:"tasks"`
+}
+
+// archiveDir retourne le répertoire des archives d'un fichier de tâches
+func archiveDir(filename string) string {
+	return sidecarDir(filename, "archive")
+}
+
+// archiveMonth retourne le mois d'archivage d'une tâche (mois de sa complétion)
+func archiveMonth(task Task) string {
+	if updated, err := parseTimestamp(task.Updated); err == nil {
+		return updated.Local().Format("2006-01")
+	}
+	return time.Now().Format("2006-01")
+}
+
+// readArchive lit une archive mensuelle (vide si absente)
+func readArchive(filename string) (*Archive, error) {
+	archive := &Archive{}
+	data, err := readDataFile(filename)
+	if os.IsNotExist(err) {
+		return archive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, archive); err != nil {
+		return nil, fmt.Errorf("archive %s illisible : %v", filename, err)
+	}
+	if archive.SchemaVersion > currentSchemaVersion {
+		return nil, fmt.Errorf("archive %s : %w", filename, errNewerSchema)
+	}
+	return archive, nil
+}
+
+// appendToArchive ajoute des tâches à une archive mensuelle (sans doublon d'UUID),
+// chiffrée comme le fichier de tâches si c n'est pas nil
+func appendToArchive(filename string, month string, tasks []Task, c *fileCipher) error {
+	archive, err := readArchive(filename)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool)
+	for _, task := range archive.Tasks {
+		known[taskKey(task)] = true
+	}
+	for _, task := range tasks {
+		if !known[taskKey(task)] {
+			archive.Tasks = append(archive.Tasks, task)
+		}
+	}
+
+	archive.SchemaVersion = currentSchemaVersion
+	archive.Month = month
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err == nil {
+		data, err = sealData(data, c)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, data, dataFileMode, "")
+}
+
+// Archive déplace les tâches terminées (avant before si non nul) vers les
+// archives mensuelles. Les archives sont écrites avant le fichier principal :
+// un crash laisse un doublon, jamais une perte.
+func (tm *TodoManager) Archive(before time.Time) (int, error) {
+	byMonth := make(map[string][]Task)
+	var remaining []Task
+	var archived int
+
+	for _, task := range tm.Tasks {
+		if task.IsClosed() && (before.IsZero() || completedBefore(task, before)) {
+			month := archiveMonth(task)
+			byMonth[month] = append(byMonth[month], task)
+			archived++
+			continue
+		}
+		remaining = append(remaining, task)
+	}
+
+	if archived == 0 {
+		fmt.Println("📝 Aucune tâche terminée à archiver")
+		return 0, nil
+	}
+
+	dir := archiveDir(tm.filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	for _, month := range sortedKeys(byMonth) {
+		filename := filepath.Join(dir, month+".json")
+		if err := appendToArchive(filename, month, byMonth[month], tm.cipher()); err != nil {
+			return 0, err
+		}
+		fmt.Printf("📦 %d tâche(s) archivée(s) dans %s\n", len(byMonth[month]), filename)
+	}
+
+	if remaining == nil {
+		remaining = []Task{}
+	}
+	tm.Tasks = remaining
+	tm.operation = fmt.Sprintf("archive (%d tâches)", archived)
+	if err := tm.save(); err != nil {
+		return 0, err
+	}
+	return archived, nil
+}
+
+// completedBefore indique si une tâche terminée l'a été avant une date
+func completedBefore(task Task, before time.Time) bool {
+	updated, err := parseTimestamp(task.Updated)
+	return err == nil && updated.Before(before)
+}
+
+// ArchivedTasks lit toutes les archives, de la plus ancienne à la plus récente.
+// Les tâches revenues dans le fichier principal (todo undo) sont ignorées.
+func (tm *TodoManager) ArchivedTasks() ([]Task, error) {
+	dir := archiveDir(tm.filename)
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if archiveFileRegex.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	live := make(map[string]bool, len(tm.Tasks))
+	for _, task := range tm.Tasks {
+		live[taskKey(task)] = true
+	}
+
+	var tasks []Task
+	for _, name := range names {
+		archive, err := readArchive(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range archive.Tasks {
+			if !live[taskKey(task)] {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// ListArchived affiche les tâches archivées correspondant au filtre
+func (tm *TodoManager) ListArchived(filter TaskFilter) error {
+	tasks, err := tm.ArchivedTasks()
+	if err != nil {
+		return err
+	}
+
+	filter.ShowDone = true
+	tm.printTaskList(filterTaskList(tasks, filter))
+	return nil
+}
+
+// Search cherche un texte (ou un tag) dans toutes les tâches, puis dans les archives
+func (tm *TodoManager) Search(text string) error {
+	filter := TaskFilter{ShowDone: true, Text: text}
+	found := filterTaskList(tm.Tasks, filter)
+
+	archived, err := tm.ArchivedTasks()
+	if err != nil {
+		return err
+	}
+	foundArchived := filterTaskList(archived, filter)
+
+	if len(found) == 0 && len(foundArchived) == 0 {
+		fmt.Printf("🔍 Aucune tâche ne contient '%s'\n", text)
+		return nil
+	}
+
+	if len(found) > 0 {
+		tm.printTaskList(found)
+	}
+	if len(foundArchived) > 0 {
+		fmt.Printf("%s📦 Archives (%d)%s\n", ColorBold, len(foundArchived), ColorReset)
+		tm.printTaskList(foundArchived)
+	}
+	return nil
+}
+
+// matchesText recherche insensible à la casse dans le texte et les tags
+func matchesText(task Task, text string) bool {
+	needle := strings.ToLower(text)
+	if strings.Contains(strings.ToLower(task.Text), needle) {
+		return true
+	}
+	for _, tag := range task.Tags {
+		if strings.Contains(strings.ToLower(tag), needle) {
+			return true
+		}
+	}
+	return false
+}
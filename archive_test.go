@@ -0,0 +1,106 @@
+// archive_test.go - Tests des archives de tâches terminées
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Tasks = []Task{
+		{ID: 1, UUID: "juin", Text: "Rapport de juin", Status: StatusDone, Tags: []string{"+rapport"}, Updated: "2025-06-30 18:00:00"},
+		{ID: 2, UUID: "juillet", Text: "Rapport de juillet", Status: StatusDone, Tags: []string{"+rapport"}, Updated: "2025-07-15 09:00:00"},
+		{ID: 3, UUID: "ouverte", Text: "Rapport d'août", Tags: []string{"+rapport"}, Updated: "2025-07-20 09:00:00"},
+	}
+	tm.NextID = 4
+
+	t.Run("avant une date", func(t *testing.T) {
+		before, _ := time.ParseInLocation("2006-01-02", "2025-07-01", time.Local)
+		archived, err := tm.Archive(before)
+		if err != nil || archived != 1 {
+			t.Fatalf("1 tâche archivée attendue, obtenu %d (%v)", archived, err)
+		}
+
+		archive, err := readArchive(filepath.Join(archiveDir(tm.filename), "2025-06.json"))
+		if err != nil || len(archive.Tasks) != 1 || archive.Tasks[0].UUID != "juin" {
+			t.Fatalf("Archive de juin inattendue: %+v (%v)", archive, err)
+		}
+		assertTaskCount(t, reloadManager(t, tm.filename), 2)
+	})
+
+	t.Run("toutes les tâches terminées", func(t *testing.T) {
+		archived, err := tm.Archive(time.Time{})
+		if err != nil || archived != 1 {
+			t.Fatalf("1 tâche archivée attendue, obtenu %d (%v)", archived, err)
+		}
+		if _, err := os.Stat(filepath.Join(archiveDir(tm.filename), "2025-07.json")); err != nil {
+			t.Errorf("Archive de juillet manquante: %v", err)
+		}
+
+		tm := reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 1)
+		assertTaskExists(t, tm, 3)
+	})
+
+	t.Run("consultation des archives", func(t *testing.T) {
+		tasks, err := tm.ArchivedTasks()
+		if err != nil {
+			t.Fatalf("ArchivedTasks: %v", err)
+		}
+		if len(tasks) != 2 || tasks[0].UUID != "juin" || tasks[1].UUID != "juillet" {
+			t.Errorf("Archives dans l'ordre chronologique attendues: %+v", tasks)
+		}
+
+		found := filterTaskList(tasks, TaskFilter{ShowDone: true, Text: "JUILLET"})
+		if len(found) != 1 {
+			t.Errorf("La recherche devrait trouver la tâche archivée: %+v", found)
+		}
+	})
+
+	t.Run("tâche revenue par undo ignorée", func(t *testing.T) {
+		if err := tm.Undo(1); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+		tm := reloadManager(t, tm.filename)
+		tasks, _ := tm.ArchivedTasks()
+		if len(tasks) != 1 {
+			t.Errorf("La tâche restaurée ne doit pas apparaître deux fois: %+v", tasks)
+		}
+	})
+
+	t.Run("rien à archiver", func(t *testing.T) {
+		tm := newTestManager(filepath.Join(tempDir, "vide.json"), nil)
+		if archived, err := tm.Archive(time.Time{}); err != nil || archived != 0 {
+			t.Errorf("Aucune archive attendue, obtenu %d (%v)", archived, err)
+		}
+	})
+}
+
+func TestTaskFilter_Matches(t *testing.T) {
+	task := Task{Text: "Préparer la démo", Status: StatusDone, Priority: "high", Tags: []string{"+Dev", "@bureau"}}
+
+	tests := []struct {
+		name   string
+		filter TaskFilter
+		want   bool
+	}{
+		{"terminée masquée", TaskFilter{}, false},
+		{"terminée affichée", TaskFilter{ShowDone: true}, true},
+		{"projet sans casse", TaskFilter{ShowDone: true, Project: "dev"}, true},
+		{"contexte absent", TaskFilter{ShowDone: true, Context: "maison"}, false},
+		{"priorité", TaskFilter{ShowDone: true, Priority: "low"}, false},
+		{"texte", TaskFilter{ShowDone: true, Text: "démo"}, true},
+		{"texte dans un tag", TaskFilter{ShowDone: true, Text: "bureau"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Matches(task); got != tt.want {
+			t.Errorf("%s: attendu %v, obtenu %v", tt.name, tt.want, got)
+		}
+	}
+}
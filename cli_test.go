@@ -64,8 +64,8 @@ func setupCLITest(t *testing.T) *CLITestHelper {
 
 // compileBinary compile le binaire de test
 func (h *CLITestHelper) compileBinary(t *testing.T) {
-	// CORRECTION: Compiler seulement main.go et import.go, pas les tests
-	cmd := exec.Command("go", "build", "-o", h.binaryPath, "main.go", "import.go")
+	// Compiler le paquet (go build ignore les fichiers _test.go)
+	cmd := exec.Command("go", "build", "-o", h.binaryPath, ".")
 	cmd.Env = append(os.Environ(), "HOME="+h.tempDir, "USERPROFILE="+h.tempDir)
 
 	output, err := cmd.CombinedOutput()
@@ -77,11 +77,16 @@ func (h *CLITestHelper) compileBinary(t *testing.T) {
 // runCommand exécute une commande todo et retourne la sortie
 // runCommand exécute une commande todo et retourne la sortie
 func (h *CLITestHelper) runCommand(args ...string) (string, string, int, error) {
-	cmd := exec.Command(h.binaryPath, args...)
-	cmd.Env = append(os.Environ(), "HOME="+h.tempDir, "USERPROFILE="+h.tempDir)
+	return h.runCommandIn(h.tempDir, args...)
+}
 
-	// AJOUTER CETTE LIGNE ICI ⬇️
-	cmd.Dir = h.tempDir
+// runCommandIn exécute une commande todo depuis le répertoire dir
+func (h *CLITestHelper) runCommandIn(dir string, args ...string) (string, string, int, error) {
+	cmd := exec.Command(h.binaryPath, args...)
+	cmd.Env = append(os.Environ(), "HOME="+h.tempDir, "USERPROFILE="+h.tempDir,
+		"TODO_FILE=", "TODO_DIR=", "XDG_DATA_HOME=", "XDG_CONFIG_HOME=",
+		"TODO_PASSPHRASE=", "TODO_KEY_FILE=", "XDG_RUNTIME_DIR="+h.tempDir)
+	cmd.Dir = dir
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -537,6 +542,653 @@ func TestCLI_Import(t *testing.T) {
 	})
 }
 
+func TestCLI_Lists(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Tâche perso")
+	h.assertCommandSuccess(t, "--list", "work", "add", "Tâche travail")
+	h.assertCommandSuccess(t, "--list=work", "add", "Release notes")
+
+	t.Run("listes séparées", func(t *testing.T) {
+		output := h.assertCommandSuccess(t, "list")
+		if strings.Contains(output, "Tâche travail") {
+			t.Errorf("La liste par défaut ne doit pas contenir les tâches de 'work': %s", output)
+		}
+		output = h.assertCommandSuccess(t, "--list=work", "list")
+		if !strings.Contains(output, "Tâche travail") || strings.Contains(output, "Tâche perso") {
+			t.Errorf("Contenu inattendu de 'work': %s", output)
+		}
+	})
+
+	t.Run("énumération", func(t *testing.T) {
+		output := h.assertCommandSuccess(t, "lists")
+		if !strings.Contains(output, "default") || !strings.Contains(output, "work") {
+			t.Errorf("Listes manquantes: %s", output)
+		}
+	})
+
+	t.Run("déplacement", func(t *testing.T) {
+		output := h.assertCommandSuccess(t, "--list=work", "move", "2", "default")
+		if !strings.Contains(output, "[2] Release notes") {
+			t.Errorf("Nouvel ID attendu dans la liste cible: %s", output)
+		}
+		output = h.assertCommandSuccess(t, "list")
+		if !strings.Contains(output, "Release notes") {
+			t.Errorf("Tâche déplacée absente: %s", output)
+		}
+	})
+
+	t.Run("nom invalide", func(t *testing.T) {
+		h.assertCommandFails(t, 1, "--list=../x", "list")
+	})
+}
+
+func TestCLI_ProjectList(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	project := filepath.Join(h.tempDir, "projet")
+	subdir := filepath.Join(project, "src")
+	os.MkdirAll(subdir, 0755)
+
+	h.assertCommandSuccess(t, "add", "Tâche globale")
+
+	stdout, _, exitCode, _ := h.runCommandIn(project, "init")
+	if exitCode != 0 || !strings.Contains(stdout, "Liste de projet créée") {
+		t.Fatalf("init a échoué (code %d): %s", exitCode, stdout)
+	}
+
+	t.Run("liste du projet depuis un sous-répertoire", func(t *testing.T) {
+		h.runCommandIn(subdir, "add", "Tâche du projet")
+
+		stdout, stderr, _, _ := h.runCommandIn(subdir, "--verbose", "list")
+		if !strings.Contains(stdout, "Tâche du projet") || strings.Contains(stdout, "Tâche globale") {
+			t.Errorf("Liste de projet attendue: %s", stdout)
+		}
+		if !strings.Contains(stderr, filepath.Join(".todo", "todo.json")) || !strings.Contains(stderr, "projet") {
+			t.Errorf("--verbose devrait indiquer le fichier utilisé: %s", stderr)
+		}
+	})
+
+	t.Run("liste globale hors du projet", func(t *testing.T) {
+		output := h.assertCommandSuccess(t, "list")
+		if strings.Contains(output, "Tâche du projet") {
+			t.Errorf("La liste globale ne doit pas contenir les tâches du projet: %s", output)
+		}
+	})
+
+	t.Run("init en double", func(t *testing.T) {
+		_, _, exitCode, _ := h.runCommandIn(project, "init")
+		if exitCode != 1 {
+			t.Errorf("Un second init devrait échouer, code %d", exitCode)
+		}
+	})
+}
+
+func TestCLI_DataFile(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	custom := filepath.Join(h.tempDir, "ailleurs", "perso.json")
+
+	t.Run("option --file", func(t *testing.T) {
+		h.assertCommandSuccess(t, "--file", custom, "add", "Tâche personnelle")
+
+		if _, err := os.Stat(custom); err != nil {
+			t.Fatalf("Fichier --file non créé: %v", err)
+		}
+		if output := h.assertCommandSuccess(t, "list"); strings.Contains(output, "Tâche personnelle") {
+			t.Errorf("La liste globale ne doit pas contenir la tâche: %s", output)
+		}
+	})
+
+	t.Run("migration vers XDG", func(t *testing.T) {
+		h.assertCommandSuccess(t, "add", "Tâche globale")
+
+		output := h.assertCommandSuccess(t, "migrate", "--xdg")
+		if !strings.Contains(output, "Données déplacées") {
+			t.Errorf("Message de migration attendu: %s", output)
+		}
+
+		xdgFile := filepath.Join(h.tempDir, ".local", "share", "todo", "todo.json")
+		if _, err := os.Stat(xdgFile); err != nil {
+			t.Fatalf("Fichier non déplacé: %v", err)
+		}
+		if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "Tâche globale") {
+			t.Errorf("Les tâches devraient suivre le déplacement: %s", output)
+		}
+	})
+}
+
+func TestCLI_UndoRedo(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Tâche précieuse", "+important")
+	h.assertCommandSuccess(t, "add", "Autre tâche")
+	h.assertCommandSuccess(t, "reset")
+
+	output := h.assertCommandSuccess(t, "history")
+	if !strings.Contains(output, "clear (2 tâches)") || !strings.Contains(output, "add [1] Tâche précieuse") {
+		t.Errorf("Historique incomplet: %s", output)
+	}
+
+	output = h.assertCommandSuccess(t, "undo")
+	if !strings.Contains(output, "Annulé : clear") {
+		t.Errorf("Annulation attendue: %s", output)
+	}
+	output = h.assertCommandSuccess(t, "list")
+	if !strings.Contains(output, "Tâche précieuse") || !strings.Contains(output, "Autre tâche") {
+		t.Errorf("Les tâches devraient être restaurées: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "redo")
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "Aucune tâche") {
+		t.Errorf("Le reset devrait être rejoué: %s", output)
+	}
+
+	h.assertCommandFails(t, 1, "undo", "zéro")
+}
+
+func TestCLI_Trash(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Tâche à jeter")
+	h.assertCommandSuccess(t, "remove", "1")
+
+	output := h.assertCommandSuccess(t, "trash")
+	if !strings.Contains(output, "Tâche à jeter") || !strings.Contains(output, "supprimée le") {
+		t.Errorf("La corbeille devrait lister la tâche: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "restore", "1")
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "Tâche à jeter") {
+		t.Errorf("La tâche devrait être restaurée: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "clear", "--force")
+	output = h.assertCommandSuccess(t, "trash", "empty", "--older-than=30d")
+	if !strings.Contains(output, "Aucune tâche") {
+		t.Errorf("Une suppression récente doit être conservée: %s", output)
+	}
+	h.assertCommandSuccess(t, "trash", "empty")
+	if output := h.assertCommandSuccess(t, "trash"); !strings.Contains(output, "Corbeille vide") {
+		t.Errorf("La corbeille devrait être vide: %s", output)
+	}
+
+	h.assertCommandFails(t, 1, "restore", "1")
+	h.assertCommandFails(t, 1, "trash", "empty", "--older-than=bientôt")
+}
+
+func TestCLI_Archive(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Rétrospective sprint 12", "+equipe")
+	h.assertCommandSuccess(t, "add", "Préparer sprint 13", "+equipe")
+	h.assertCommandSuccess(t, "done", "1")
+
+	output := h.assertCommandSuccess(t, "archive")
+	if !strings.Contains(output, "1 tâche(s) archivée(s)") {
+		t.Errorf("Archivage attendu: %s", output)
+	}
+	matches, _ := filepath.Glob(filepath.Join(h.tempDir, ".todo", "archive", "*.json"))
+	if len(matches) != 1 {
+		t.Errorf("Un fichier d'archive attendu, obtenu %v", matches)
+	}
+
+	if output := h.assertCommandSuccess(t, "list", "--all"); strings.Contains(output, "Rétrospective") {
+		t.Errorf("La tâche archivée ne doit plus être dans le fichier principal: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "list", "--archived", "--project=equipe"); !strings.Contains(output, "Rétrospective") {
+		t.Errorf("list --archived devrait afficher la tâche: %s", output)
+	}
+
+	output = h.assertCommandSuccess(t, "search", "sprint")
+	if !strings.Contains(output, "Préparer sprint 13") || !strings.Contains(output, "Archives (1)") {
+		t.Errorf("La recherche devrait couvrir les archives: %s", output)
+	}
+
+	h.assertCommandFails(t, 1, "archive", "--before=hier")
+}
+
+func TestCLI_Snapshots(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Tâche précieuse", "+important")
+	h.assertCommandSuccess(t, "add", "Autre tâche")
+	h.assertCommandSuccess(t, "reset")
+
+	output := h.assertCommandSuccess(t, "snapshots")
+	if !strings.Contains(output, "hourly") || !strings.Contains(output, "daily") || !strings.Contains(output, "1 tâche(s)") {
+		t.Errorf("Instantanés horaire et quotidien attendus: %s", output)
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	output = h.assertCommandSuccess(t, "diff", "--since="+now)
+	if !strings.Contains(output, "- [1] Tâche précieuse") || strings.Contains(output, "Autre tâche") {
+		t.Errorf("Diff depuis l'instantané pris avant le deuxième ajout attendu: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "restore", "--at="+now)
+	output = h.assertCommandSuccess(t, "list")
+	if !strings.Contains(output, "Tâche précieuse") || strings.Contains(output, "Autre tâche") {
+		t.Errorf("L'état de l'instantané devrait être restauré: %s", output)
+	}
+
+	h.assertCommandFails(t, 1, "restore", "--at=2000-01-01 00:00")
+	h.assertCommandFails(t, 1, "diff")
+}
+
+func TestCLI_Encrypt(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	keyFile := filepath.Join(h.tempDir, "todo.key")
+	if err := ioutil.WriteFile(keyFile, []byte("secret partagé"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h.assertCommandSuccess(t, "add", "Relancer client@example.com", "+client")
+	h.assertCommandSuccess(t, "--key-file="+keyFile, "encrypt")
+
+	data, _ := ioutil.ReadFile(h.todoFile)
+	if strings.Contains(string(data), "client@example.com") {
+		t.Errorf("Le fichier chiffré ne doit pas contenir le texte des tâches: %s", data)
+	}
+
+	// Sans clé ni terminal : échec sans toucher au fichier
+	h.assertCommandFails(t, 1, "add", "Tâche perdue")
+	if after, _ := ioutil.ReadFile(h.todoFile); string(after) != string(data) {
+		t.Error("Le fichier chiffré a été réécrit sans clé")
+	}
+
+	h.assertCommandSuccess(t, "--key-file="+keyFile, "add", "Deuxième tâche")
+	output := h.assertCommandSuccess(t, "--key-file="+keyFile, "list")
+	if !strings.Contains(output, "Relancer client@example.com") || !strings.Contains(output, "Deuxième tâche") {
+		t.Errorf("Les tâches chiffrées devraient être listées: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "--key-file="+keyFile, "decrypt")
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "Deuxième tâche") {
+		t.Errorf("Le fichier déchiffré devrait être lisible sans clé: %s", output)
+	}
+	h.assertCommandSuccess(t, "lock")
+}
+
+func TestCLI_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git non disponible")
+	}
+
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	remote := filepath.Join(h.tempDir, "remote.git")
+	if output, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v %s", err, output)
+	}
+
+	h.assertCommandFails(t, 1, "git", "push")
+	h.assertCommandSuccess(t, "git", "init", remote)
+	h.assertCommandSuccess(t, "add", "Préparer CV", "+job")
+	h.assertCommandSuccess(t, "done", "1")
+
+	output := h.assertCommandSuccess(t, "git", "log")
+	if !strings.Contains(output, "done [1] Préparer CV") || !strings.Contains(output, "add [1] Préparer CV") {
+		t.Errorf("Un commit par opération attendu: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "git", "push")
+	if output := h.assertCommandSuccess(t, "git", "pull"); !strings.Contains(output, "Déjà à jour") {
+		t.Errorf("Pull sans changement distant: %s", output)
+	}
+}
+
+func TestCLI_Audit(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "--store=events", "add", "Préparer CV", "+job")
+	h.assertCommandSuccess(t, "--store=events", "add", "Relire contrat")
+	h.assertCommandSuccess(t, "--store=events", "done", "1")
+
+	output := h.assertCommandSuccess(t, "--store=events", "audit", "1")
+	if !strings.Contains(output, "TaskAdded") || !strings.Contains(output, "TaskCompleted") || strings.Contains(output, "Relire contrat") {
+		t.Errorf("Événements de la tâche 1 attendus: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "--store=events", "list", "--all"); !strings.Contains(output, "Relire contrat") {
+		t.Errorf("L'état doit être reconstruit depuis les événements: %s", output)
+	}
+
+	h.assertCommandFails(t, 1, "audit")
+	h.assertCommandFails(t, 1, "--store=events", "audit", "42")
+}
+
+func TestCLI_Subtasks(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Déménager", "+perso")
+	h.assertCommandSuccess(t, "add", "Réserver le camion", "--parent=1")
+	h.assertCommandSuccess(t, "add", "Faire les cartons", "--priority=high", "--parent=1")
+	h.assertCommandSuccess(t, "add", "Autre tâche")
+	h.assertCommandFails(t, 1, "add", "Orpheline", "--parent=42")
+
+	h.assertCommandSuccess(t, "done", "2")
+	output := h.assertCommandSuccess(t, "list", "--all")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var order []string
+	for _, line := range lines {
+		for _, text := range []string{"Déménager", "Faire les cartons", "Réserver le camion", "Autre tâche"} {
+			if strings.Contains(line, text) {
+				order = append(order, text)
+			}
+		}
+	}
+	if strings.Join(order, "|") != "Déménager|Faire les cartons|Réserver le camion|Autre tâche" {
+		t.Errorf("Sous-tâches attendues sous leur parente: %s", output)
+	}
+	if !strings.Contains(output, "[1/2]") || !strings.Contains(output, "└─ ") {
+		t.Errorf("Progression et indentation attendues: %s", output)
+	}
+
+	h.assertCommandFails(t, 1, "done", "1")
+	h.assertCommandSuccess(t, "done", "1", "--cascade")
+	if output := h.assertCommandSuccess(t, "list"); strings.Contains(output, "Faire les cartons") {
+		t.Errorf("La cascade doit terminer les sous-tâches: %s", output)
+	}
+}
+
+func TestCLI_Depends(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Écrire l'article")
+	h.assertCommandSuccess(t, "add", "Relire l'article")
+	h.assertCommandSuccess(t, "depends", "2", "1")
+	h.assertCommandFails(t, 1, "depends", "1", "2")
+	h.assertCommandFails(t, 1, "depends", "2")
+
+	output := h.assertCommandSuccess(t, "list", "--blocked")
+	if !strings.Contains(output, "Relire") || !strings.Contains(output, "[bloquée par: 1]") || strings.Contains(output, "Écrire") {
+		t.Errorf("Seule la tâche 2 devrait être bloquée: %s", output)
+	}
+	output = h.assertCommandSuccess(t, "list", "--ready")
+	if !strings.Contains(output, "Écrire") || strings.Contains(output, "Relire") {
+		t.Errorf("Seule la tâche 1 devrait être prête: %s", output)
+	}
+
+	if output := h.assertCommandSuccess(t, "done", "2"); !strings.Contains(output, "encore bloquée par [1]") {
+		t.Errorf("Avertissement attendu pour une tâche bloquée: %s", output)
+	}
+	h.assertCommandSuccess(t, "depends", "2", "1", "--remove")
+}
+
+func TestCLI_Recur(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Sortir les poubelles", "--due=2025-07-14", "--recur=weekly:mon,thu")
+	h.assertCommandFails(t, 1, "add", "Tâche", "--recur=yearly")
+
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "🔁 weekly:mon,thu") {
+		t.Errorf("Marqueur de récurrence attendu: %s", output)
+	}
+
+	output := h.assertCommandSuccess(t, "done", "1")
+	if !strings.Contains(output, "Prochaine occurrence : [2] Sortir les poubelles") {
+		t.Errorf("Occurrence suivante attendue: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "[2]") || strings.Contains(output, "[1]") {
+		t.Errorf("Seule l'occurrence suivante doit rester ouverte: %s", output)
+	}
+}
+
+func TestCLI_Status(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Écrire la doc")
+	h.assertCommandSuccess(t, "add", "Déployer")
+	h.assertCommandSuccess(t, "add", "Vieille idée")
+
+	h.assertCommandSuccess(t, "start", "1")
+	if output := h.assertCommandSuccess(t, "block", "2", "--reason=serveur indisponible"); !strings.Contains(output, "serveur indisponible") {
+		t.Errorf("Motif du blocage attendu: %s", output)
+	}
+	h.assertCommandSuccess(t, "cancel", "3")
+	h.assertCommandFails(t, 1, "status", "1", "inconnu")
+	h.assertCommandFails(t, 1, "start")
+
+	output := h.assertCommandSuccess(t, "list")
+	if !strings.Contains(output, "🔄 ") || !strings.Contains(output, "⛔ ") || strings.Contains(output, "Vieille idée") {
+		t.Errorf("Icônes de statut attendues, tâche annulée masquée: %s", output)
+	}
+	output = h.assertCommandSuccess(t, "list", "--status=cancelled,in-progress")
+	if !strings.Contains(output, "Vieille idée") || !strings.Contains(output, "Écrire la doc") || strings.Contains(output, "Déployer") {
+		t.Errorf("Filtre par statut inattendu: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "status", "2", "todo")
+	h.assertCommandSuccess(t, "export", filepath.Join(h.tempDir, "status.csv"))
+	data, _ := ioutil.ReadFile(filepath.Join(h.tempDir, "status.csv"))
+	if !strings.Contains(string(data), ",Status") || !strings.Contains(string(data), ",in-progress") {
+		t.Errorf("Colonne Status attendue dans l'export: %s", data)
+	}
+}
+
+func TestCLI_Dates(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	h.assertCommandSuccess(t, "add", "Préparer la rentrée", "--scheduled="+tomorrow)
+	h.assertCommandSuccess(t, "add", "Relancer le client", "--wait="+tomorrow)
+	h.assertCommandSuccess(t, "add", "Postuler au salon", "--until="+yesterday)
+	h.assertCommandFails(t, 1, "add", "Date invalide", "--wait=un jour")
+
+	output := h.assertCommandSuccess(t, "list")
+	if !strings.Contains(output, "[scheduled:"+tomorrow+"]") || strings.Contains(output, "Relancer") || strings.Contains(output, "Postuler") {
+		t.Errorf("Tâches en attente et expirées masquées attendues: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "list", "--waiting"); !strings.Contains(output, "Relancer le client") || strings.Contains(output, "Préparer") {
+		t.Errorf("list --waiting inattendu: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "list", "--ready"); strings.Contains(output, "Préparer") {
+		t.Errorf("Une tâche prévue demain n'est pas prête: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "list", "--all"); !strings.Contains(output, "[expired:"+yesterday+"]") {
+		t.Errorf("Tâche expirée attendue avec --all: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "export", filepath.Join(h.tempDir, "dates.csv"))
+	data, _ := ioutil.ReadFile(filepath.Join(h.tempDir, "dates.csv"))
+	if !strings.Contains(string(data), ",Scheduled,Wait,Until") || !strings.Contains(string(data), ","+tomorrow+",") {
+		t.Errorf("Colonnes de planification attendues dans l'export: %s", data)
+	}
+}
+
+func TestCLI_DueTime(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Appel fournisseur", "--due=2030-07-20 14:00")
+	h.assertCommandFails(t, 1, "add", "Heure invalide", "--due=2030-07-20 25:00")
+
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "[due:2030-07-20 14:00]") {
+		t.Errorf("Échéance affichée en heure locale attendue: %s", output)
+	}
+
+	data, _ := ioutil.ReadFile(h.todoFile)
+	if !strings.Contains(string(data), `"due": "2030-07-20T14:00:00`) || !strings.Contains(string(data), `"schemaVersion": 4`) {
+		t.Errorf("Échéance RFC 3339 attendue dans le fichier: %s", data)
+	}
+}
+
+func TestCLI_NaturalDates(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	today := time.Now()
+	h.assertCommandSuccess(t, "add", "Appeler la banque", "--due=demain")
+	h.assertCommandSuccess(t, "add", "Rapport annuel", "--due=in 2 weeks")
+	h.assertCommandFails(t, 1, "add", "Un jour", "--due=bientôt")
+
+	output := h.assertCommandSuccess(t, "list")
+	if !strings.Contains(output, "[due:"+today.AddDate(0, 0, 1).Format("2006-01-02")+"]") ||
+		!strings.Contains(output, "[due:"+today.AddDate(0, 0, 14).Format("2006-01-02")+"]") {
+		t.Errorf("Dates relatives converties attendues: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "list", "--due=+3d"); !strings.Contains(output, "Appeler la banque") || strings.Contains(output, "Rapport annuel") {
+		t.Errorf("list --due=+3d inattendu: %s", output)
+	}
+
+	h.assertCommandSuccess(t, "edit", "2", "--due=today")
+	if output := h.assertCommandSuccess(t, "list", "--due=today"); !strings.Contains(output, "Rapport annuel") {
+		t.Errorf("Échéance modifiée par edit attendue: %s", output)
+	}
+	h.assertCommandSuccess(t, "edit", "2", "--due=none")
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "Rapport annuel") || strings.Count(output, "[due:") != 1 {
+		t.Errorf("Échéance effacée attendue: %s", output)
+	}
+	h.assertCommandFails(t, 1, "edit", "2", "--due=jamais")
+}
+
+func TestCLI_TimeTracking(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Réviser Go", "+dev")
+	h.assertCommandSuccess(t, "add", "Écrire le rapport", "+doc", "+dev")
+	h.assertCommandSuccess(t, "add", "Ranger le bureau")
+
+	h.assertCommandFails(t, 1, "stop")
+	h.assertCommandSuccess(t, "start", "1")
+	if output := h.assertCommandSuccess(t, "list"); !strings.Contains(output, "▶️") {
+		t.Errorf("Minuteur en cours attendu dans la liste: %s", output)
+	}
+	if output := h.assertCommandSuccess(t, "start", "2"); !strings.Contains(output, "Minuteur arrêté : [1]") {
+		t.Errorf("Le minuteur précédent doit être arrêté: %s", output)
+	}
+	h.assertCommandSuccess(t, "stop")
+	h.assertCommandFails(t, 1, "stop")
+
+	h.assertCommandSuccess(t, "log", "2", "1h30")
+	h.assertCommandSuccess(t, "log", "3", "45m")
+	h.assertCommandFails(t, 1, "log", "2", "longtemps")
+	h.assertCommandFails(t, 1, "log", "42", "1h")
+
+	h.assertCommandSuccess(t, "timesheet", "--week")
+	output := h.assertCommandSuccess(t, "timesheet", "--since=-1d") // Indépendant du jour de la semaine
+	for _, want := range []string{"+dev", "1h30", "+doc", "(sans projet)", "45m", "Total", "2h15"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%q attendu dans la feuille de temps: %s", want, output)
+		}
+	}
+}
+
+func TestCLI_Estimates(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "add", "Refonte API", "+backend", "@bureau", "--estimate=2h")
+	h.assertCommandSuccess(t, "add", "Écran login", "+front", "@bureau", "--estimate=3pt")
+	h.assertCommandSuccess(t, "add", "Sans estimation", "+backend")
+	h.assertCommandFails(t, 1, "add", "Tâche floue", "--estimate=longtemps")
+
+	output := h.assertCommandSuccess(t, "list")
+	for _, want := range []string{"[est:2h00]", "[est:3pt]", "📊 Estimations", "+backend", "+front", "@bureau", "2h00 (reste 2h00) · 3pt (reste 3pt)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%q attendu dans la liste: %s", want, output)
+		}
+	}
+
+	h.assertCommandSuccess(t, "edit", "1", "--estimate=1h30")
+	h.assertCommandFails(t, 1, "edit", "1", "--estimate=0pt")
+	h.assertCommandSuccess(t, "log", "1", "2h")
+	h.assertCommandSuccess(t, "done", "1")
+	h.assertCommandSuccess(t, "edit", "2", "--estimate=none")
+	if output := h.assertCommandSuccess(t, "list"); strings.Contains(output, "[est:") {
+		t.Errorf("Plus aucune estimation attendue dans la liste: %s", output)
+	}
+
+	output = h.assertCommandSuccess(t, "accuracy")
+	for _, want := range []string{"Refonte API", "1h30", "2h00", "+33%"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%q attendu dans le rapport de précision: %s", want, output)
+		}
+	}
+}
+
+func TestCLI_Config(t *testing.T) {
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	h.assertCommandSuccess(t, "config", "set", "alias.t", "add")
+	h.assertCommandSuccess(t, "config", "set", "list.filter", "--priority=high")
+	h.assertCommandSuccess(t, "config", "set", "color.theme", "none")
+
+	t.Run("valeur enregistrée", func(t *testing.T) {
+		output := h.assertCommandSuccess(t, "config", "get", "alias.t")
+		if strings.TrimSpace(output) != "add" {
+			t.Errorf("Alias attendu 'add', obtenu: %s", output)
+		}
+		if _, err := os.Stat(filepath.Join(h.tempDir, ".config", "todo", "config")); err != nil {
+			t.Errorf("Fichier de configuration manquant: %v", err)
+		}
+	})
+
+	t.Run("alias et filtre par défaut", func(t *testing.T) {
+		h.assertCommandSuccess(t, "t", "Tâche urgente", "--priority=high")
+		h.assertCommandSuccess(t, "t", "Tâche banale")
+
+		output := h.assertCommandSuccess(t, "list")
+		if !strings.Contains(output, "Tâche urgente") || strings.Contains(output, "Tâche banale") {
+			t.Errorf("Le filtre par défaut devrait s'appliquer: %s", output)
+		}
+		if strings.Contains(output, "\033[") {
+			t.Errorf("Le thème none ne devrait produire aucune couleur: %q", output)
+		}
+
+		output = h.assertCommandSuccess(t, "list", "--priority=")
+		if !strings.Contains(output, "Tâche banale") {
+			t.Errorf("La ligne de commande devrait surcharger le filtre: %s", output)
+		}
+	})
+
+	t.Run("valeurs invalides refusées", func(t *testing.T) {
+		h.assertCommandFails(t, 1, "config", "set", "alias.list", "add")
+		h.assertCommandFails(t, 1, "config", "set", "import.conflict", "always")
+		h.assertCommandFails(t, 1, "config", "get", "export.path")
+	})
+}
+
 func TestCLI_Help(t *testing.T) {
 	h := setupCLITest(t)
 	defer h.cleanup()
@@ -830,6 +1482,51 @@ func TestE2E_StressTest(t *testing.T) {
 	})
 }
 
+func TestE2E_ConcurrentCommands(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Test de concurrence ignoré en mode court")
+	}
+
+	h := setupCLITest(t)
+	defer h.cleanup()
+	h.compileBinary(t)
+
+	t.Run("ajouts simultanés sans perte", func(t *testing.T) {
+		const workers = 10
+		errs := make(chan error, workers)
+
+		for i := 1; i <= workers; i++ {
+			go func(i int) {
+				_, stderr, exitCode, err := h.runCommand("--lock-timeout=30s", "add", fmt.Sprintf("Tâche concurrente %d", i))
+				if err == nil && exitCode != 0 {
+					err = fmt.Errorf("code %d: %s", exitCode, stderr)
+				}
+				errs <- err
+			}(i)
+		}
+		for i := 0; i < workers; i++ {
+			if err := <-errs; err != nil {
+				t.Errorf("Commande concurrente échouée: %v", err)
+			}
+		}
+
+		content, err := ioutil.ReadFile(h.todoFile)
+		if err != nil {
+			t.Fatalf("Impossible de lire todo.json: %v", err)
+		}
+		var data TodoManager
+		if err := json.Unmarshal(content, &data); err != nil {
+			t.Fatalf("todo.json invalide: %v", err)
+		}
+		if len(data.Tasks) != workers {
+			t.Errorf("Tâches attendues: %d, obtenues: %d", workers, len(data.Tasks))
+		}
+		if data.NextID != workers+1 {
+			t.Errorf("NextID attendu: %d, obtenu: %d", workers+1, data.NextID)
+		}
+	})
+}
+
 // Tests de robustesse
 
 func TestE2E_ErrorRecovery(t *testing.T) {
@@ -865,6 +1562,35 @@ func TestE2E_ErrorRecovery(t *testing.T) {
 		}
 	})
 
+	t.Run("fichier corrompu mis en quarantaine", func(t *testing.T) {
+		corruptedJSON := `{"tasks": [{"id": 1, "text": "Précieuse"`
+		if err := ioutil.WriteFile(h.todoFile, []byte(corruptedJSON), 0644); err != nil {
+			t.Fatalf("Impossible de corrompre le fichier: %v", err)
+		}
+
+		_, stderr, _, _ := h.runCommand("list")
+		if !strings.Contains(stderr, "todo doctor") {
+			t.Errorf("La corruption devrait être signalée sur stderr: %s", stderr)
+		}
+
+		h.assertCommandFails(t, 1, "doctor", "--check")
+
+		h.assertCommandSuccess(t, "add", "Nouvelle tâche")
+		quarantined, _ := filepath.Glob(h.todoFile + ".corrupt-*")
+		if len(quarantined) == 0 {
+			t.Fatal("Le fichier corrompu aurait dû être mis en quarantaine")
+		}
+		content, _ := ioutil.ReadFile(quarantined[len(quarantined)-1])
+		if string(content) != corruptedJSON {
+			t.Errorf("Contenu en quarantaine modifié: %s", content)
+		}
+
+		output := h.assertCommandSuccess(t, "doctor")
+		if !strings.Contains(output, "Aucun problème") {
+			t.Errorf("Le nouveau fichier devrait être sain: %s", output)
+		}
+	})
+
 	t.Run("gestion des permissions", func(t *testing.T) {
 		// Ce test est difficile à implémenter de manière portable
 		// mais pourrait être ajouté pour des tests spécifiques Unix
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config configuration au format INI ($XDG_CONFIG_HOME/todo/config) :
+//
+//	[core]
+//	store = json
+//
+//	[list]
+//	filter = --priority=high
+//
+//	[alias]
+//	t = add
+//
+//	[color]
+//	theme = default
+//	red = magenta
+//
+//	[export]
+//	path = ~/todo_export.csv
+//
+//	[import]
+//	conflict = newer
+//
+//	[history]
+//	size = 100
+//
+// Les clés s'écrivent "section.nom". Les commentaires et l'ordre du
+// fichier sont conservés par Set.
+type Config struct {
+	filename string
+	lines    []string
+	values   map[string]string
+	lineOf   map[string]int
+}
+
+// configKeys clés connues et leur description (alias.* et color.* à part)
+var configKeys = map[string]string{
+	"core.store":       "Stockage par défaut (json, journal, memory, encrypted, events)",
+	"list.filter":      "Options ajoutées par défaut à 'todo list' (ex: --priority=high)",
+	"color.theme":      "Thème de couleurs (default, bright, none)",
+	"export.path":      "Fichier d'export CSV par défaut",
+	"import.conflict":  "Stratégie de conflit par défaut (skip, update, newer)",
+	"history.size":     "Nombre d'opérations conservées pour todo undo (défaut: 100)",
+	"snapshots.hourly": "Instantanés horaires conservés, 0 pour désactiver (défaut: 24)",
+	"snapshots.daily":  "Instantanés quotidiens conservés, 0 pour désactiver (défaut: 7)",
+	"crypto.keyfile":   "Fichier de clé des fichiers chiffrés (défaut: $TODO_KEY_FILE)",
+	"crypto.cache":     "Durée du cache de la phrase secrète, 0 pour désactiver (défaut: 15m)",
+}
+
+// colorCodes couleurs utilisables dans la configuration
+var colorCodes = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "97",
+	"gray":    "37",
+	"bold":    "1",
+	"none":    "",
+}
+
+// ansiCodeRegex code ANSI brut (ex: 35, 1;36)
+var ansiCodeRegex = regexp.MustCompile(`^[0-9]+(;[0-9]+)*$`)
+
+// defaultConfigFile retourne le chemin du fichier de configuration
+// ($XDG_CONFIG_HOME/todo/config, défaut ~/.config/todo/config)
+func defaultConfigFile() string {
+	homeDir, _ := os.UserHomeDir()
+	dir, err := xdgDir("XDG_CONFIG_HOME", homeDir, ".config")
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "todo", "config")
+}
+
+// expandHome remplace le préfixe ~/ d'un chemin par le répertoire personnel
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[2:])
+}
+
+// LoadConfig lit un fichier de configuration. Un fichier absent donne une
+// configuration vide ; les lignes invalides sont ignorées et signalées.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return newConfig(filename, ""), err
+	}
+	return parseConfig(filename, string(data))
+}
+
+// newConfig crée une configuration vide
+func newConfig(filename string, content string) *Config {
+	c := &Config{
+		filename: filename,
+		values:   make(map[string]string),
+		lineOf:   make(map[string]int),
+	}
+	if content != "" {
+		c.lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+	return c
+}
+
+// parseConfig analyse le contenu d'un fichier de configuration
+func parseConfig(filename string, content string) (*Config, error) {
+	c := newConfig(filename, content)
+	var problems []string
+	section := ""
+
+	for i, raw := range c.lines {
+		line := strings.TrimSpace(raw)
+		if isConfigFiller(line) {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+
+		name, value, found := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			problems = append(problems, fmt.Sprintf("ligne %d: '%s' ignorée (attendu: clé = valeur)", i+1, line))
+			continue
+		}
+
+		key := strings.ToLower(name)
+		if section != "" {
+			key = section + "." + key
+		}
+		c.values[key] = unquote(strings.TrimSpace(value))
+		c.lineOf[key] = i
+	}
+
+	if len(problems) > 0 {
+		return c, fmt.Errorf("%s: %s", filename, strings.Join(problems, "; "))
+	}
+	return c, nil
+}
+
+// unquote retire les guillemets entourant une valeur
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// Get retourne la valeur d'une clé
+func (c *Config) Get(key string) (string, bool) {
+	value, exists := c.values[strings.ToLower(key)]
+	return value, exists
+}
+
+// GetDefault retourne la valeur d'une clé ou une valeur par défaut
+func (c *Config) GetDefault(key string, fallback string) string {
+	if value, exists := c.Get(key); exists && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Keys retourne les clés définies, triées
+func (c *Config) Keys() []string {
+	return sortedKeys(c.values)
+}
+
+// Set modifie une clé après validation, en conservant le reste du fichier
+func (c *Config) Set(key string, value string) error {
+	key = strings.ToLower(key)
+	if err := validateConfigValue(key, value); err != nil {
+		return err
+	}
+
+	section, name, _ := strings.Cut(key, ".")
+	line := fmt.Sprintf("%s = %s", name, value)
+
+	if index, exists := c.lineOf[key]; exists {
+		c.lines[index] = line
+	} else {
+		c.insertLine(section, line)
+	}
+
+	c.values[key] = value
+	return nil
+}
+
+// insertLine ajoute une ligne en fin de section (créée si besoin)
+func (c *Config) insertLine(section string, line string) {
+	start := -1
+	current := ""
+	for i, raw := range c.lines {
+		trimmed := strings.TrimSpace(raw)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			current = strings.ToLower(strings.TrimSpace(trimmed[1 : len(trimmed)-1]))
+			if current == section {
+				start = i
+			}
+		}
+	}
+
+	if start == -1 {
+		if len(c.lines) > 0 {
+			c.lines = append(c.lines, "")
+		}
+		c.lines = append(c.lines, "["+section+"]", line)
+		c.reindex()
+		return
+	}
+
+	// Fin de la section : avant l'en-tête suivant, sans les lignes vides
+	// ni les commentaires finaux (qui introduisent la section suivante)
+	end := len(c.lines)
+	for i := start + 1; i < len(c.lines); i++ {
+		trimmed := strings.TrimSpace(c.lines[i])
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			end = i
+			break
+		}
+	}
+	for end > start+1 && isConfigFiller(c.lines[end-1]) {
+		end--
+	}
+
+	c.lines = append(c.lines[:end], append([]string{line}, c.lines[end:]...)...)
+	c.reindex()
+}
+
+// isConfigFiller indique si une ligne est vide ou un commentaire
+func isConfigFiller(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";")
+}
+
+// reindex recalcule la position des clés après insertion
+func (c *Config) reindex() {
+	reparsed, _ := parseConfig(c.filename, strings.Join(c.lines, "\n"))
+	c.lineOf = reparsed.lineOf
+}
+
+// Save écrit la configuration
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.filename), 0755); err != nil {
+		return err
+	}
+	content := strings.Join(c.lines, "\n") + "\n"
+	return writeFileAtomic(c.filename, []byte(content), 0644, "")
+}
+
+// validateConfigValue vérifie une valeur avant de l'enregistrer
+func validateConfigValue(key string, value string) error {
+	section, name, _ := strings.Cut(key, ".")
+
+	switch {
+	case section == "alias":
+		if name == "" || isBuiltinCommand(name) {
+			return fmt.Errorf("alias '%s' invalide : ne peut pas remplacer une commande existante", name)
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 || !isBuiltinCommand(fields[0]) {
+			return fmt.Errorf("l'alias doit commencer par une commande todo (ex: add)")
+		}
+		return nil
+
+	case section == "color" && name != "theme":
+		if _, known := colorThemes["default"][name]; !known || name == "reset" {
+			return fmt.Errorf("couleur '%s' inconnue (red, green, yellow, blue, gray, bold)", name)
+		}
+		if _, err := colorSequence(value); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if _, known := configKeys[key]; !known {
+		return fmt.Errorf("clé '%s' inconnue", key)
+	}
+
+	switch key {
+	case "core.store":
+		_, err := newStore(value, "")
+		return err
+	case "color.theme":
+		if _, known := colorThemes[value]; !known {
+			return fmt.Errorf("thème '%s' inconnu (default, bright, none)", value)
+		}
+	case "import.conflict":
+		if value != "skip" && value != "update" && value != "newer" {
+			return fmt.Errorf("stratégie de conflit invalide. Utilisez 'skip', 'update' ou 'newer'")
+		}
+	case "history.size":
+		if size, err := strconv.Atoi(value); err != nil || size < 1 {
+			return fmt.Errorf("taille d'historique invalide '%s' (entier positif)", value)
+		}
+	case "crypto.cache":
+		if _, err := parseAge(value); err != nil {
+			return err
+		}
+	case "snapshots.hourly", "snapshots.daily":
+		if count, err := strconv.Atoi(value); err != nil || count < 0 {
+			return fmt.Errorf("nombre d'instantanés invalide '%s' (entier positif ou nul)", value)
+		}
+	case "list.filter":
+		for _, field := range strings.Fields(value) {
+			if !strings.HasPrefix(field, "-") {
+				return fmt.Errorf("filtre '%s' invalide (options de 'todo list' uniquement)", field)
+			}
+		}
+	}
+	return nil
+}
+
+// colorSequence convertit un nom de couleur ou un code ANSI en séquence d'échappement
+func colorSequence(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if code, known := colorCodes[value]; known {
+		if code == "" {
+			return "", nil
+		}
+		return "\033[" + code + "m", nil
+	}
+	if ansiCodeRegex.MatchString(value) {
+		return "\033[" + value + "m", nil
+	}
+	return "", fmt.Errorf("couleur '%s' invalide (nom ou code ANSI, ex: magenta, 1;36)", value)
+}
+
+// colorThemes thèmes de couleurs prédéfinis
+var colorThemes = map[string]map[string]string{
+	"default": {
+		"red": "\033[31m", "green": "\033[32m", "yellow": "\033[33m",
+		"blue": "\033[34m", "gray": "\033[37m", "bold": "\033[1m", "reset": "\033[0m",
+	},
+	"bright": {
+		"red": "\033[91m", "green": "\033[92m", "yellow": "\033[93m",
+		"blue": "\033[94m", "gray": "\033[90m", "bold": "\033[1m", "reset": "\033[0m",
+	},
+	"none": {
+		"red": "", "green": "", "yellow": "", "blue": "", "gray": "", "bold": "", "reset": "",
+	},
+}
+
+// applyColors applique le thème et les couleurs personnalisées
+func (c *Config) applyColors() {
+	theme, known := colorThemes[c.GetDefault("color.theme", "default")]
+	if !known {
+		theme = colorThemes["default"]
+	}
+
+	targets := map[string]*string{
+		"red": &ColorRed, "green": &ColorGreen, "yellow": &ColorYellow,
+		"blue": &ColorBlue, "gray": &ColorGray, "bold": &ColorBold, "reset": &ColorReset,
+	}
+	for name, target := range targets {
+		*target = theme[name]
+		if value, exists := c.Get("color." + name); exists && name != "reset" {
+			if sequence, err := colorSequence(value); err == nil {
+				*target = sequence
+			}
+		}
+	}
+}
+
+// expandAlias remplace un alias de commande par sa définition
+func (c *Config) expandAlias(args []string) []string {
+	if len(args) == 0 || isBuiltinCommand(args[0]) {
+		return args
+	}
+	definition, exists := c.Get("alias." + args[0])
+	if !exists {
+		return args
+	}
+	fields := strings.Fields(definition)
+	if len(fields) == 0 || !isBuiltinCommand(fields[0]) {
+		return args
+	}
+	return append(fields, args[1:]...)
+}
+
+// RunConfigCommand exécute 'todo config get|set|list'
+func RunConfigCommand(c *Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: todo config get <clé> | set <clé> <valeur> | list")
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: todo config get <clé>")
+		}
+		value, exists := c.Get(args[1])
+		if !exists {
+			return fmt.Errorf("clé '%s' non définie", args[1])
+		}
+		fmt.Println(value)
+
+	case "set":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: todo config set <clé> <valeur>")
+		}
+		value := strings.Join(args[2:], " ")
+		if err := c.Set(args[1], value); err != nil {
+			return err
+		}
+		if err := c.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("⚙️  %s = %s\n", strings.ToLower(args[1]), value)
+
+	case "list":
+		fmt.Printf("# %s\n", c.filename)
+		for _, key := range c.Keys() {
+			fmt.Printf("%s = %s\n", key, c.values[key])
+		}
+		if len(c.values) == 0 {
+			fmt.Println("# Aucune option définie. Clés disponibles :")
+			known := make([]string, 0, len(configKeys))
+			for key := range configKeys {
+				known = append(known, key)
+			}
+			sort.Strings(known)
+			for _, key := range known {
+				fmt.Printf("#   %-16s %s\n", key, configKeys[key])
+			}
+			fmt.Printf("#   %-16s %s\n", "alias.<nom>", "Alias de commande (ex: alias.t = add)")
+			fmt.Printf("#   %-16s %s\n", "color.<couleur>", "Couleur personnalisée (red, green, yellow, blue, gray, bold)")
+		}
+
+	default:
+		return fmt.Errorf("sous-commande '%s' inconnue (get, set, list)", args[0])
+	}
+
+	return nil
+}
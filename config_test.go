@@ -0,0 +1,165 @@
+// config_test.go - Tests du fichier de configuration
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleConfig = `# Configuration de test
+[list]
+filter = --priority=high
+
+[alias]
+t = add
+urgent = "list --priority=high"
+
+; Couleurs
+[color]
+theme = bright
+`
+
+func TestParseConfig(t *testing.T) {
+	c, err := parseConfig("config", sampleConfig+"ligne invalide\n")
+	if err == nil || !strings.Contains(err.Error(), "ligne 12") {
+		t.Errorf("La ligne invalide devrait être signalée: %v", err)
+	}
+
+	tests := map[string]string{
+		"list.filter":  "--priority=high",
+		"alias.t":      "add",
+		"alias.urgent": "list --priority=high",
+		"color.theme":  "bright",
+	}
+	for key, want := range tests {
+		if got, _ := c.Get(key); got != want {
+			t.Errorf("%s: attendu %q, obtenu %q", key, want, got)
+		}
+	}
+
+	if _, exists := c.Get("export.path"); exists {
+		t.Error("export.path ne devrait pas être défini")
+	}
+	if got := c.GetDefault("import.conflict", "skip"); got != "skip" {
+		t.Errorf("Valeur par défaut attendue, obtenu %q", got)
+	}
+}
+
+func TestConfig_SetPreservesFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "todo", "config")
+	c, _ := parseConfig(filename, sampleConfig)
+
+	if err := c.Set("color.theme", "none"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set("alias.l", "list --all"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set("import.conflict", "newer"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	content, _ := ioutil.ReadFile(filename)
+	for _, want := range []string{"# Configuration de test", "; Couleurs", "theme = none", "[import]\nconflict = newer"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%q attendu dans:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "bright") {
+		t.Errorf("L'ancienne valeur devrait être remplacée:\n%s", content)
+	}
+
+	reloaded, err := LoadConfig(filename)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got, _ := reloaded.Get("alias.l"); got != "list --all" {
+		t.Errorf("L'alias devrait être ajouté à la section [alias], obtenu %q", got)
+	}
+	if !strings.Contains(string(content), "urgent = \"list --priority=high\"\nl = list --all") {
+		t.Errorf("L'alias devrait suivre les alias existants:\n%s", content)
+	}
+}
+
+func TestValidateConfigValue(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"core.store", "journal", false},
+		{"core.store", "sqlite", true},
+		{"color.theme", "none", false},
+		{"color.theme", "rose", true},
+		{"color.red", "magenta", false},
+		{"color.red", "1;35", false},
+		{"color.red", "rouge", true},
+		{"color.pink", "red", true},
+		{"import.conflict", "update", false},
+		{"import.conflict", "always", true},
+		{"list.filter", "--all --project=dev", false},
+		{"list.filter", "dev", true},
+		{"alias.t", "add", false},
+		{"alias.list", "add", true},
+		{"alias.x", "inconnue", true},
+		{"inconnu.cle", "x", true},
+	}
+
+	for _, tt := range tests {
+		err := validateConfigValue(tt.key, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateConfigValue(%q, %q): erreur attendue %v, obtenu %v", tt.key, tt.value, tt.wantErr, err)
+		}
+	}
+}
+
+func TestConfigKeys_StoreKinds(t *testing.T) {
+	// La description affichée par 'todo config' suit les stockages acceptés
+	for _, kind := range []string{StoreJSON, StoreJournal, StoreMemory, StoreEncrypted, StoreEvents} {
+		if err := validateConfigValue("core.store", kind); err != nil {
+			t.Errorf("Stockage %s refusé: %v", kind, err)
+		}
+		if !strings.Contains(configKeys["core.store"], kind) {
+			t.Errorf("Stockage %s absent de la description: %q", kind, configKeys["core.store"])
+		}
+	}
+}
+
+func TestConfig_ExpandAlias(t *testing.T) {
+	c, _ := parseConfig("config", sampleConfig)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"t", "Ma tâche", "+dev"}, "add|Ma tâche|+dev"},
+		{[]string{"urgent", "--all"}, "list|--priority=high|--all"},
+		{[]string{"list", "--all"}, "list|--all"},
+		{[]string{"inconnu"}, "inconnu"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(c.expandAlias(tt.args), "|"); got != tt.want {
+			t.Errorf("expandAlias(%v): attendu %q, obtenu %q", tt.args, tt.want, got)
+		}
+	}
+}
+
+func TestConfig_ApplyColors(t *testing.T) {
+	defer newConfig("", "").applyColors()
+
+	c, _ := parseConfig("config", "[color]\ntheme = none\nred = 35\n")
+	c.applyColors()
+
+	if ColorGreen != "" || ColorReset != "" {
+		t.Errorf("Le thème none devrait désactiver les couleurs: %q %q", ColorGreen, ColorReset)
+	}
+	if ColorRed != "\033[35m" {
+		t.Errorf("Couleur personnalisée attendue, obtenu %q", ColorRed)
+	}
+}
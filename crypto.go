@@ -0,0 +1,656 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// encryptedFormat algorithme des fichiers chiffrés, en tête du fichier
+const encryptedFormat = "aes-256-gcm"
+
+// defaultKeyCacheTTL durée de vie par défaut d'une clé saisie au clavier
+const defaultKeyCacheTTL = 15 * time.Minute
+
+// errKeyUnavailable clé absente ou incorrecte : le fichier ne doit pas être réécrit
+var errKeyUnavailable = errors.New("fichier chiffré")
+
+// kdfParams paramètres de dérivation de la clé (scrypt)
+type kdfParams struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// defaultKDF paramètres scrypt des nouveaux fichiers (recommandation interactive)
+var defaultKDF = kdfParams{Name: "scrypt", N: 1 << 15, R: 8, P: 1}
+
+// encryptedFile enveloppe JSON d'un fichier chiffré
+type encryptedFile struct {
+	Encrypted string    `json:"encrypted"`
+	KDF       kdfParams `json:"kdf"`
+	Nonce     []byte    `json:"nonce"`
+	Data      []byte    `json:"data"`
+}
+
+// fileCipher clé dérivée d'un fichier : le sel est conservé d'une sauvegarde
+// à l'autre, seul le nonce change
+type fileCipher struct {
+	kdf  kdfParams
+	key  []byte
+	aead cipher.AEAD
+}
+
+// isEncrypted indique si des données sont une enveloppe chiffrée
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(`{"encrypted":`))
+}
+
+// deriveCipher dérive la clé AES-256 d'un secret selon les paramètres du fichier
+func deriveCipher(kdf kdfParams, secret []byte) (*fileCipher, error) {
+	if kdf.Name != "scrypt" {
+		return nil, fmt.Errorf("dérivation de clé '%s' inconnue", kdf.Name)
+	}
+	key, err := scrypt.Key(secret, kdf.Salt, kdf.N, kdf.R, kdf.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	return newFileCipher(kdf, key)
+}
+
+// newFileCipher prépare le chiffrement AES-GCM d'une clé déjà dérivée
+func newFileCipher(kdf kdfParams, key []byte) (*fileCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCipher{kdf: kdf, key: key, aead: aead}, nil
+}
+
+// additionalData authentifie l'en-tête : modifier les paramètres invalide le fichier
+func (c *fileCipher) additionalData() []byte {
+	return []byte(fmt.Sprintf("%s|%s|%d|%d|%d|%x", encryptedFormat, c.kdf.Name, c.kdf.N, c.kdf.R, c.kdf.P, c.kdf.Salt))
+}
+
+// seal chiffre des données dans une enveloppe JSON
+func (c *fileCipher) seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedFile{
+		Encrypted: encryptedFormat,
+		KDF:       c.kdf,
+		Nonce:     nonce,
+		Data:      c.aead.Seal(nil, nonce, plain, c.additionalData()),
+	})
+}
+
+// open déchiffre une enveloppe
+func (c *fileCipher) open(envelope *encryptedFile) ([]byte, error) {
+	if len(envelope.Nonce) != c.aead.NonceSize() {
+		return nil, fmt.Errorf("%w : nonce invalide", errKeyUnavailable)
+	}
+	plain, err := c.aead.Open(nil, envelope.Nonce, envelope.Data, c.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("%w : phrase secrète ou fichier de clé incorrect", errKeyUnavailable)
+	}
+	return plain, nil
+}
+
+// openData déchiffre des données si besoin et retourne le chiffrement utilisé
+// (nil pour des données en clair)
+func openData(data []byte) ([]byte, *fileCipher, error) {
+	if !isEncrypted(data) {
+		return data, nil, nil
+	}
+
+	var envelope encryptedFile
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, nil, fmt.Errorf("enveloppe chiffrée illisible : %v", err)
+	}
+	if envelope.Encrypted != encryptedFormat {
+		return nil, nil, fmt.Errorf("chiffrement '%s' non pris en charge", envelope.Encrypted)
+	}
+
+	c, err := keyring.cipherFor(envelope.KDF)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := c.open(&envelope)
+	if err != nil {
+		keyring.forget(envelope.KDF) // Clé en cache périmée : redemander la prochaine fois
+		return nil, nil, err
+	}
+	return plain, c, nil
+}
+
+// sealData chiffre des données si un chiffrement est actif
+func sealData(data []byte, c *fileCipher) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+	return c.seal(data)
+}
+
+// readDataFile lit un fichier de données, chiffré ou non
+func readDataFile(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err = openData(data)
+	return data, err
+}
+
+// Keyring fournit les clés des fichiers chiffrés : fichier de clé, phrase
+// secrète de l'environnement ou saisie au clavier, mise en cache quelques minutes
+type Keyring struct {
+	KeyFile  string
+	CacheTTL time.Duration
+	Prompt   func(prompt string) ([]byte, error)
+
+	ciphers map[string]*fileCipher // Clés dérivées pendant la commande, par sel
+}
+
+// keyring trousseau de la commande en cours
+var keyring = &Keyring{CacheTTL: defaultKeyCacheTTL, Prompt: promptPassphrase}
+
+// promptPassphrase lit une phrase secrète sans écho sur le terminal
+func promptPassphrase(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("%w : définissez $TODO_PASSPHRASE ou --key-file", errKeyUnavailable)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return passphrase, err
+}
+
+// secret retourne le secret de l'utilisateur et indique s'il a été saisi au clavier
+func (k *Keyring) secret(confirm bool) ([]byte, bool, error) {
+	keyFile := k.KeyFile
+	if keyFile == "" {
+		keyFile = os.Getenv("TODO_KEY_FILE")
+	}
+	if keyFile != "" {
+		data, err := ioutil.ReadFile(expandHome(keyFile))
+		if err != nil {
+			return nil, false, fmt.Errorf("fichier de clé : %v", err)
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			return nil, false, fmt.Errorf("fichier de clé %s vide", keyFile)
+		}
+		return data, false, nil
+	}
+
+	if passphrase := os.Getenv("TODO_PASSPHRASE"); passphrase != "" {
+		return []byte(passphrase), false, nil
+	}
+
+	passphrase, err := k.Prompt("🔑 Phrase secrète : ")
+	if err != nil {
+		return nil, false, err
+	}
+	if len(passphrase) == 0 {
+		return nil, false, fmt.Errorf("%w : phrase secrète vide", errKeyUnavailable)
+	}
+	if confirm {
+		again, err := k.Prompt("🔑 Confirmez la phrase secrète : ")
+		if err != nil {
+			return nil, false, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, false, fmt.Errorf("les phrases secrètes ne correspondent pas")
+		}
+	}
+	return passphrase, true, nil
+}
+
+// cipherFor retourne la clé d'un fichier : déjà dérivée, en cache, ou dérivée du secret
+func (k *Keyring) cipherFor(kdf kdfParams) (*fileCipher, error) {
+	id := base64.StdEncoding.EncodeToString(kdf.Salt)
+	if c, found := k.ciphers[id]; found {
+		return c, nil
+	}
+
+	if key := k.cachedKey(id); key != nil {
+		if c, err := newFileCipher(kdf, key); err == nil {
+			k.remember(id, c)
+			return c, nil
+		}
+	}
+
+	secret, prompted, err := k.secret(false)
+	if err != nil {
+		return nil, err
+	}
+	c, err := deriveCipher(kdf, secret)
+	if err != nil {
+		return nil, err
+	}
+	k.remember(id, c)
+	if prompted {
+		k.cacheKey(id, c.key)
+	}
+	return c, nil
+}
+
+// newCipher crée la clé d'un fichier à chiffrer, avec un nouveau sel
+func (k *Keyring) newCipher() (*fileCipher, error) {
+	kdf := defaultKDF
+	kdf.Salt = make([]byte, 16)
+	if _, err := rand.Read(kdf.Salt); err != nil {
+		return nil, err
+	}
+
+	secret, prompted, err := k.secret(true)
+	if err != nil {
+		return nil, err
+	}
+	c, err := deriveCipher(kdf, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	id := base64.StdEncoding.EncodeToString(kdf.Salt)
+	k.remember(id, c)
+	if prompted {
+		k.cacheKey(id, c.key)
+	}
+	return c, nil
+}
+
+// remember garde une clé dérivée pour la durée de la commande
+func (k *Keyring) remember(id string, c *fileCipher) {
+	if k.ciphers == nil {
+		k.ciphers = make(map[string]*fileCipher)
+	}
+	k.ciphers[id] = c
+}
+
+// forget oublie la clé d'un fichier (incorrecte)
+func (k *Keyring) forget(kdf kdfParams) {
+	id := base64.StdEncoding.EncodeToString(kdf.Salt)
+	delete(k.ciphers, id)
+	if cache, err := readKeyCache(); err == nil {
+		if _, found := cache[id]; found {
+			delete(cache, id)
+			writeKeyCache(cache)
+		}
+	}
+}
+
+// keyCacheEntry clé dérivée mise en cache après une saisie au clavier
+type keyCacheEntry struct {
+	Key     []byte    `json:"key"`
+	Expires time.Time `json:"expires"`
+}
+
+// keyCacheFile retourne le cache des clés : répertoire d'exécution de
+// l'utilisateur ($XDG_RUNTIME_DIR, en mémoire) ou répertoire temporaire privé,
+// créé si create est vrai
+func keyCacheFile(create bool) (string, error) {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		dir := filepath.Join(runtimeDir, "todo")
+		if create {
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return "", err
+			}
+		}
+		return filepath.Join(dir, "keys.json"), nil
+	}
+
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("todo-%d", os.Getuid()))
+	if create {
+		if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "keys.json"), checkPrivateDir(dir)
+}
+
+// checkPrivateDir refuse un répertoire du répertoire temporaire partagé
+// qu'un autre utilisateur aurait pu créer d'avance : lien symbolique,
+// propriétaire différent ou droits trop larges
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 || !info.IsDir() {
+		return fmt.Errorf("%s n'est pas un répertoire (lien symbolique ?), cache des clés désactivé", dir)
+	}
+	if err := checkPrivateOwner(dir, info); err != nil {
+		return fmt.Errorf("%v, cache des clés désactivé", err)
+	}
+	return nil
+}
+
+// readKeyCache lit les clés en cache encore valides
+func readKeyCache() (map[string]keyCacheEntry, error) {
+	cache := make(map[string]keyCacheEntry)
+	filename, err := keyCacheFile(false)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return make(map[string]keyCacheEntry), nil // Cache corrompu : ignoré
+	}
+
+	now := time.Now()
+	for id, entry := range cache {
+		if now.After(entry.Expires) {
+			delete(cache, id)
+		}
+	}
+	return cache, nil
+}
+
+// writeKeyCache écrit le cache, lisible par l'utilisateur seul (vide : supprimé)
+func writeKeyCache(cache map[string]keyCacheEntry) error {
+	filename, err := keyCacheFile(len(cache) > 0)
+	if os.IsNotExist(err) {
+		return nil // Rien à supprimer
+	}
+	if err != nil {
+		return err
+	}
+	if len(cache) == 0 {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return replaceFile(filename, data, 0600)
+}
+
+// cachedKey retourne une clé en cache non expirée
+func (k *Keyring) cachedKey(id string) []byte {
+	if k.CacheTTL <= 0 {
+		return nil
+	}
+	cache, err := readKeyCache()
+	if err != nil {
+		return nil
+	}
+	return cache[id].Key
+}
+
+// cacheKey met une clé en cache pour CacheTTL (les erreurs sont sans conséquence)
+func (k *Keyring) cacheKey(id string, key []byte) {
+	if k.CacheTTL <= 0 {
+		return
+	}
+	cache, err := readKeyCache()
+	if err != nil {
+		return
+	}
+	cache[id] = keyCacheEntry{Key: key, Expires: time.Now().Add(k.CacheTTL)}
+	writeKeyCache(cache)
+}
+
+// Forget vide le cache des clés (todo lock)
+func (k *Keyring) Forget() error {
+	k.ciphers = nil
+	return writeKeyCache(nil)
+}
+
+// sealedStore stockage dont le fichier peut être chiffré (json, journal)
+type sealedStore interface {
+	Store
+	sealedWith() *fileCipher
+	sealWith(c *fileCipher)
+}
+
+// cipher retourne le chiffrement du fichier de tâches, nil s'il est en clair
+func (tm *TodoManager) cipher() *fileCipher {
+	if store, ok := tm.storage().(sealedStore); ok {
+		return store.sealedWith()
+	}
+	return nil
+}
+
+// Encrypt chiffre le fichier de tâches et ses fichiers annexes (historique,
+// archives, sauvegardes d'avant migration, quarantaines), puis les autres
+// listes others encore en clair, avec la même clé. La sauvegarde .bak et les
+// instantanés en clair sont supprimés.
+func (tm *TodoManager) Encrypt(others []*TodoManager) error {
+	store, ok := tm.storage().(sealedStore)
+	if !ok {
+		return fmt.Errorf("seuls les stockages json et journal peuvent être chiffrés")
+	}
+	if store.sealedWith() != nil {
+		return fmt.Errorf("%s est déjà chiffré", tm.filename)
+	}
+	if err := checkLists(others); err != nil {
+		return err
+	}
+
+	c, err := keyring.newCipher()
+	if err != nil {
+		return err
+	}
+	removed, err := tm.encryptWith(c)
+	if err != nil {
+		return err
+	}
+	var lists int
+	for _, other := range others {
+		if other.cipher() != nil {
+			continue // Déjà chiffrée, avec sa propre clé
+		}
+		n, err := other.encryptWith(c)
+		if err != nil {
+			return fmt.Errorf("liste %s : %v", other.filename, err)
+		}
+		removed += n
+		lists++
+	}
+	tm.commitToRepo("encrypt")
+
+	fmt.Printf("🔒 %s chiffré (%s, clé dérivée par scrypt)\n", tm.filename, encryptedFormat)
+	if lists > 0 {
+		fmt.Printf("   %d autre(s) liste(s) chiffrée(s) avec la même clé\n", lists)
+	}
+	if removed > 0 {
+		fmt.Printf("   %d instantané(s) en clair supprimé(s)\n", removed)
+	}
+	if tm.repo != nil {
+		fmt.Println("⚠️ L'historique git conserve les versions en clair déjà commitées (et poussées) :")
+		fmt.Println("   seuls les prochains commits sont chiffrés. Recréez le dépôt pour les effacer.")
+	}
+	return nil
+}
+
+// encryptWith chiffre le fichier de tâches et ses annexes avec c, supprime la
+// sauvegarde .bak et les instantanés en clair. Retourne le nombre d'instantanés supprimés.
+func (tm *TodoManager) encryptWith(c *fileCipher) (int, error) {
+	if err := tm.convertData(c); err != nil {
+		return 0, err
+	}
+	if store, ok := tm.storage().(*JSONFileStore); ok {
+		if err := os.Remove(store.backupFilename()); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+	}
+	return removePlainSnapshots(snapshotDir(tm.filename))
+}
+
+// Decrypt réécrit en clair le fichier de tâches, ses fichiers annexes et les
+// autres listes others chiffrées
+func (tm *TodoManager) Decrypt(others []*TodoManager) error {
+	if tm.cipher() == nil {
+		return fmt.Errorf("%s n'est pas chiffré", tm.filename)
+	}
+	if err := checkLists(others); err != nil {
+		return err
+	}
+
+	var lists int
+	for _, manager := range append([]*TodoManager{tm}, others...) {
+		if manager.cipher() == nil {
+			continue // Liste déjà en clair
+		}
+		if err := manager.convertData(nil); err != nil {
+			return fmt.Errorf("liste %s : %v", manager.filename, err)
+		}
+		lists++
+	}
+	tm.commitToRepo("decrypt")
+
+	fmt.Printf("🔓 %s déchiffré\n", tm.filename)
+	if lists > 1 {
+		fmt.Printf("   %d autre(s) liste(s) déchiffrée(s)\n", lists-1)
+	}
+	return nil
+}
+
+// checkLists vérifie que les autres listes à convertir sont lisibles et
+// stockées en JSON ou en journal, avant de modifier quoi que ce soit
+func checkLists(others []*TodoManager) error {
+	for _, other := range others {
+		if other.loadErr != nil && !errors.Is(other.loadErr, os.ErrNotExist) {
+			return fmt.Errorf("liste %s illisible : %v", other.filename, other.loadErr)
+		}
+		if _, ok := other.storage().(sealedStore); !ok {
+			return fmt.Errorf("liste %s : seuls les stockages json et journal peuvent être chiffrés", other.filename)
+		}
+	}
+	return nil
+}
+
+// convertData réécrit le fichier de tâches, l'historique et les archives
+// avec le chiffrement c (nil : en clair)
+func (tm *TodoManager) convertData(c *fileCipher) error {
+	store := tm.storage().(sealedStore)
+	store.sealWith(c)
+	if err := store.Save(tm); err != nil {
+		return err
+	}
+
+	if filename := tm.historyFile(); filename != "" {
+		if _, err := os.Stat(filename); err == nil {
+			history, err := tm.loadHistory()
+			if err != nil {
+				return err
+			}
+			if err := history.save(filename, tm.historySize, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	dir := archiveDir(tm.filename)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if !archiveFileRegex.MatchString(entry.Name()) {
+			continue
+		}
+		if err := resealFile(filepath.Join(dir, entry.Name()), c); err != nil {
+			return fmt.Errorf("archive %s : %v", entry.Name(), err)
+		}
+	}
+
+	copies, err := dataFileCopies(tm.filename)
+	if err != nil {
+		return err
+	}
+	for _, filename := range copies {
+		if err := resealFile(filename, c); err != nil {
+			return fmt.Errorf("%s : %v", filepath.Base(filename), err)
+		}
+	}
+	return nil
+}
+
+// resealFile réécrit un fichier de données avec le chiffrement c (nil : en clair)
+func resealFile(filename string, c *fileCipher) error {
+	data, err := readDataFile(filename)
+	if err == nil {
+		data, err = sealData(data, c)
+	}
+	if err == nil {
+		err = writeFileAtomic(filename, data, dataFileMode, "")
+	}
+	return err
+}
+
+// dataFileCopies retourne les copies du fichier de tâches conservées à côté
+// de lui : sauvegardes d'avant migration (todo.json.vN.bak) et fichiers en
+// quarantaine (todo.json.corrupt-*)
+func dataFileCopies(filename string) ([]string, error) {
+	entries, err := ioutil.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	copyRegex := regexp.MustCompile(`^` + regexp.QuoteMeta(filepath.Base(filename)) + `\.(v\d+\.bak|corrupt-.+)$`)
+
+	var copies []string
+	for _, entry := range entries {
+		if !entry.IsDir() && copyRegex.MatchString(entry.Name()) {
+			copies = append(copies, filepath.Join(filepath.Dir(filename), entry.Name()))
+		}
+	}
+	return copies, nil
+}
+
+// removePlainSnapshots supprime les instantanés non chiffrés
+func removePlainSnapshots(dir string) (int, error) {
+	snapshots, err := listSnapshots(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int
+	for _, snapshot := range snapshots {
+		data, err := ioutil.ReadFile(snapshot.Path)
+		if err != nil || isEncrypted(data) {
+			continue
+		}
+		if err := os.Remove(snapshot.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,322 @@
+// crypto_test.go - Tests du chiffrement des fichiers de tâches
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTestKeyring remplace le trousseau par une saisie simulée et des
+// paramètres scrypt légers. Retourne le nombre de saisies effectuées.
+func useTestKeyring(t *testing.T, passphrase string, cacheTTL time.Duration) *int {
+	t.Helper()
+	t.Setenv("TODO_PASSPHRASE", "")
+	t.Setenv("TODO_KEY_FILE", "")
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	previousKeyring, previousKDF := keyring, defaultKDF
+	t.Cleanup(func() { keyring, defaultKDF = previousKeyring, previousKDF })
+
+	prompts := 0
+	defaultKDF.N = 1 << 10
+	keyring = &Keyring{CacheTTL: cacheTTL, Prompt: func(string) ([]byte, error) {
+		prompts++
+		return []byte(passphrase), nil
+	}}
+	return &prompts
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	useTestKeyring(t, "correct horse", 0)
+
+	tm.Add("Appeler Mme Martin", []string{"+client"}, "", "")
+	tm.Add("Écrire à client@example.com", nil, "", "")
+	tm.Done(1)
+
+	if err := tm.Encrypt(nil); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	t.Run("fichiers chiffrés", func(t *testing.T) {
+		for _, filename := range []string{tm.filename, tm.historyFile()} {
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("Lecture de %s: %v", filename, err)
+			}
+			if !isEncrypted(data) || strings.Contains(string(data), "Martin") {
+				t.Errorf("%s devrait être chiffré: %.80s", filepath.Base(filename), data)
+			}
+		}
+		if _, err := os.Stat(tm.filename + ".bak"); !os.IsNotExist(err) {
+			t.Error("La sauvegarde en clair devrait être supprimée")
+		}
+	})
+
+	t.Run("rechargement et modification", func(t *testing.T) {
+		keyring.ciphers = nil
+		tm := reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 2)
+
+		tm.Add("Tâche ajoutée après chiffrement", nil, "", "")
+		data, _ := ioutil.ReadFile(tm.filename)
+		if !isEncrypted(data) {
+			t.Error("Le fichier doit rester chiffré après une modification")
+		}
+		if err := tm.Undo(1); err != nil {
+			t.Errorf("Undo sur un historique chiffré: %v", err)
+		}
+	})
+
+	t.Run("phrase secrète incorrecte", func(t *testing.T) {
+		useTestKeyring(t, "mauvaise", 0)
+		before, _ := ioutil.ReadFile(tm.filename)
+
+		tm := NewTodoManagerWithStore(tm.filename, nil)
+		if !errors.Is(tm.loadErr, errKeyUnavailable) {
+			t.Fatalf("errKeyUnavailable attendue, obtenu %v", tm.loadErr)
+		}
+		after, _ := ioutil.ReadFile(tm.filename)
+		if string(before) != string(after) {
+			t.Error("Le fichier chiffré ne doit pas être modifié")
+		}
+	})
+
+	t.Run("déchiffrement", func(t *testing.T) {
+		keyring.ciphers = nil
+		tm := reloadManager(t, tm.filename)
+		if err := tm.Decrypt(nil); err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+
+		data, _ := ioutil.ReadFile(tm.filename)
+		if isEncrypted(data) || !strings.Contains(string(data), "Martin") {
+			t.Errorf("Fichier en clair attendu: %.80s", data)
+		}
+		history, _ := ioutil.ReadFile(tm.historyFile())
+		if isEncrypted(history) {
+			t.Error("L'historique devrait être en clair")
+		}
+		if err := tm.Decrypt(nil); err == nil {
+			t.Error("Un fichier en clair ne peut pas être déchiffré")
+		}
+	})
+}
+
+func TestEncrypt_Journal(t *testing.T) {
+	_, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	useTestKeyring(t, "correct horse", 0)
+
+	filename := filepath.Join(tempDir, "todo.json")
+	journal := storeFile(StoreJournal, filename)
+	newJournalManager := func() *TodoManager {
+		return NewTodoManagerWithStore(filename, NewJournalStore(journal))
+	}
+	journalLines := func() []string {
+		data, _ := ioutil.ReadFile(journal)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	tm := newJournalManager()
+	tm.Add("Appeler Mme Martin", nil, "", "")
+	tm.Add("Écrire à client@example.com", nil, "", "")
+	if err := tm.Encrypt(nil); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// Les entrées en clair disparaissent, chaque nouvelle ligne est chiffrée
+	tm.Add("Tâche ajoutée après chiffrement", nil, "", "")
+	lines := journalLines()
+	if len(lines) != 2 {
+		t.Fatalf("Journal compacté puis une entrée attendus, %d lignes", len(lines))
+	}
+	for _, line := range lines {
+		if !isEncrypted([]byte(line)) || strings.Contains(line, "Martin") {
+			t.Errorf("Entrée en clair dans le journal: %.80s", line)
+		}
+	}
+
+	keyring.ciphers = nil
+	tm = newJournalManager()
+	if tm.loadErr != nil {
+		t.Fatalf("Rechargement: %v", tm.loadErr)
+	}
+	assertTaskCount(t, tm, 3)
+
+	useTestKeyring(t, "mauvaise", 0)
+	if wrong := newJournalManager(); !errors.Is(wrong.loadErr, errKeyUnavailable) {
+		t.Errorf("errKeyUnavailable attendue, obtenu %v", wrong.loadErr)
+	}
+
+	useTestKeyring(t, "correct horse", 0)
+	if err := tm.Decrypt(nil); err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if lines := journalLines(); len(lines) != 1 || isEncrypted([]byte(lines[0])) || !strings.Contains(lines[0], "Martin") {
+		t.Errorf("Une entrée en clair attendue: %v", lines)
+	}
+}
+
+func TestEncrypt_KeyCache(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	prompts := useTestKeyring(t, "phrase", time.Minute)
+
+	tm.Add("Tâche", nil, "", "")
+	if err := tm.Encrypt(nil); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if *prompts != 2 {
+		t.Errorf("Saisie et confirmation attendues, obtenu %d saisie(s)", *prompts)
+	}
+
+	// Chaque commande repart d'un trousseau vide : la clé vient du cache
+	keyring.ciphers = nil
+	reloadManager(t, tm.filename)
+	if *prompts != 2 {
+		t.Errorf("La clé en cache devrait éviter une saisie, obtenu %d", *prompts)
+	}
+	if runtime.GOOS != "windows" {
+		cacheFile, _ := keyCacheFile(false)
+		if info, err := os.Stat(cacheFile); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Cache des clés en 0600 attendu: %v %v", info, err)
+		}
+	}
+
+	if err := keyring.Forget(); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	reloadManager(t, tm.filename)
+	if *prompts != 3 {
+		t.Errorf("Une saisie attendue après todo lock, obtenu %d", *prompts)
+	}
+}
+
+func TestEncrypt_CopiesAndLists(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	useTestKeyring(t, "correct horse", 0)
+	tm.Add("Appeler Mme Martin", nil, "", "")
+
+	copies := []string{tm.filename + ".v3.bak", tm.filename + ".corrupt-20250101-120000"}
+	for _, filename := range copies {
+		if err := ioutil.WriteFile(filename, []byte(`{"tasks": [{"text": "Martin"`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workFile, err := listFile(tm.filename, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTestManager(workFile, nil).Add("Rappeler Martin", nil, "", "")
+
+	if err := tm.Encrypt([]*TodoManager{reloadManager(t, workFile)}); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	for _, filename := range append(copies, workFile) {
+		data, _ := ioutil.ReadFile(filename)
+		if !isEncrypted(data) || strings.Contains(string(data), "Martin") {
+			t.Errorf("%s devrait être chiffré: %.80s", filepath.Base(filename), data)
+		}
+	}
+
+	keyring.ciphers = nil
+	if err := reloadManager(t, tm.filename).Decrypt([]*TodoManager{reloadManager(t, workFile)}); err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	for _, filename := range append(copies, workFile) {
+		if data, _ := ioutil.ReadFile(filename); isEncrypted(data) || !strings.Contains(string(data), "Martin") {
+			t.Errorf("%s devrait être en clair: %.80s", filepath.Base(filename), data)
+		}
+	}
+}
+
+func TestKeyCache_SharedTempDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Répertoire temporaire propre à l'utilisateur sous Windows")
+	}
+	tempDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", tempDir)
+	dir := filepath.Join(tempDir, fmt.Sprintf("todo-%d", os.Getuid()))
+	cache := map[string]keyCacheEntry{"sel": {Key: []byte("clé"), Expires: time.Now().Add(time.Minute)}}
+
+	t.Run("lien symbolique", func(t *testing.T) {
+		target := t.TempDir()
+		if err := os.Symlink(target, dir); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(dir)
+		if err := writeKeyCache(cache); err == nil {
+			t.Error("Un lien symbolique doit être refusé")
+		}
+		if _, err := os.Stat(filepath.Join(target, "keys.json")); !os.IsNotExist(err) {
+			t.Error("Aucune clé ne doit être écrite dans la cible du lien")
+		}
+	})
+
+	t.Run("droits trop larges", func(t *testing.T) {
+		if err := os.Mkdir(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+		os.Chmod(dir, 0777)
+		defer os.RemoveAll(dir)
+		if err := writeKeyCache(cache); err == nil {
+			t.Error("Un répertoire accessible aux autres doit être refusé")
+		}
+		if _, err := readKeyCache(); err == nil {
+			t.Error("Le cache d'un répertoire non privé ne doit pas être lu")
+		}
+	})
+
+	t.Run("répertoire créé par todo", func(t *testing.T) {
+		if err := writeKeyCache(cache); err != nil {
+			t.Fatalf("writeKeyCache: %v", err)
+		}
+		if info, err := os.Lstat(dir); err != nil || info.Mode().Perm() != 0700 {
+			t.Errorf("Répertoire en 0700 attendu: %v %v", info, err)
+		}
+		if read, err := readKeyCache(); err != nil || string(read["sel"].Key) != "clé" {
+			t.Errorf("Clé en cache attendue: %v %v", read, err)
+		}
+	})
+}
+
+func TestEncrypt_KeyFile(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	prompts := useTestKeyring(t, "", 0)
+
+	keyFile := filepath.Join(tempDir, "todo.key")
+	if err := ioutil.WriteFile(keyFile, []byte("clé aléatoire\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TODO_KEY_FILE", keyFile)
+
+	store := &JSONFileStore{filename: filepath.Join(tempDir, "chiffre.json"), encrypt: true}
+	encrypted := newTestManager(store.filename, store)
+	encrypted.Add("Tâche confidentielle", nil, "", "")
+
+	keyring.ciphers = nil
+	assertTaskCount(t, reloadManager(t, store.filename), 1)
+	if *prompts != 0 {
+		t.Errorf("Le fichier de clé ne doit pas demander de saisie, obtenu %d", *prompts)
+	}
+
+	if runtime.GOOS != "windows" {
+		for _, filename := range []string{store.filename, tm.filename} {
+			if info, err := os.Stat(filename); err == nil && info.Mode().Perm() != 0600 {
+				t.Errorf("%s : droits 0600 attendus, obtenu %o", filepath.Base(filename), info.Mode().Perm())
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// dates.go - Dates de planification : début prévu, attente et expiration
+package main
+
+import (
+	"time"
+)
+
+// compareDay compare le jour d'une date (avec ou sans heure) au jour de
+// now : -1 avant, 0 le même jour, 1 après. ok est faux si la date est vide
+// ou invalide.
+func compareDay(date string, now time.Time) (cmp int, ok bool) {
+	day, _, err := parseDue(date, now.Location())
+	if err != nil {
+		return 0, false
+	}
+	return startOfDay(day).Compare(startOfDay(now)), true
+}
+
+// isWaiting indique si une tâche est masquée jusqu'à sa date Wait
+func isWaiting(task Task, now time.Time) bool {
+	cmp, ok := compareDay(task.Wait, now)
+	return ok && cmp > 0
+}
+
+// isExpired indique si une tâche ouverte a dépassé sa date Until
+// (dernier jour où elle a un sens)
+func isExpired(task Task, now time.Time) bool {
+	cmp, ok := compareDay(task.Until, now)
+	return ok && cmp < 0 && !task.IsClosed()
+}
+
+// isScheduledLater indique si le début prévu d'une tâche est encore à venir
+func isScheduledLater(task Task, now time.Time) bool {
+	cmp, ok := compareDay(task.Scheduled, now)
+	return ok && cmp > 0
+}
+
+// planningLabels retourne les dates Scheduled, Wait et Until à afficher
+func planningLabels(task Task, now time.Time) string {
+	labels := ""
+	if task.Scheduled != "" {
+		color := ColorBlue
+		if !isScheduledLater(task, now) && !task.IsClosed() {
+			color = ColorYellow // Début prévu atteint
+		}
+		labels += " " + color + "[scheduled:" + formatDue(task.Scheduled) + "]" + ColorReset
+	}
+	if task.Wait != "" && isWaiting(task, now) {
+		labels += " " + ColorGray + "[wait:" + formatDue(task.Wait) + "]" + ColorReset
+	}
+	if task.Until != "" {
+		if isExpired(task, now) {
+			labels += " " + ColorRed + "[expired:" + formatDue(task.Until) + "]" + ColorReset
+		} else {
+			labels += " " + ColorGray + "[until:" + formatDue(task.Until) + "]" + ColorReset
+		}
+	}
+	return labels
+}
+
+// shiftDate décale une date (avec ou sans heure) d'un nombre de jours
+func shiftDate(date string, days int) string {
+	day, hasTime, err := parseDue(date, time.Local)
+	if err != nil {
+		return date
+	}
+	return formatDueValue(day.AddDate(0, 0, days), hasTime)
+}
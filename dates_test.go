@@ -0,0 +1,109 @@
+// dates_test.go - Tests des dates de planification
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanningDates(t *testing.T) {
+	now := time.Date(2025, 7, 15, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name                        string
+		task                        Task
+		waiting, expired, scheduled bool
+	}{
+		{"sans date", Task{}, false, false, false},
+		{"wait demain", Task{Wait: "2025-07-16"}, true, false, false},
+		{"wait aujourd'hui", Task{Wait: "2025-07-15"}, false, false, false},
+		{"until aujourd'hui", Task{Until: "2025-07-15"}, false, false, false},
+		{"until hier", Task{Until: "2025-07-14"}, false, true, false},
+		{"until hier, terminée", Task{Until: "2025-07-14", Status: StatusDone}, false, false, false},
+		{"scheduled demain", Task{Scheduled: "2025-07-16"}, false, false, true},
+		{"scheduled aujourd'hui", Task{Scheduled: "2025-07-15"}, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWaiting(tt.task, now); got != tt.waiting {
+				t.Errorf("isWaiting = %v, attendu %v", got, tt.waiting)
+			}
+			if got := isExpired(tt.task, now); got != tt.expired {
+				t.Errorf("isExpired = %v, attendu %v", got, tt.expired)
+			}
+			if got := isScheduledLater(tt.task, now); got != tt.scheduled {
+				t.Errorf("isScheduledLater = %v, attendu %v", got, tt.scheduled)
+			}
+		})
+	}
+}
+
+func TestPlanningDates_Filter(t *testing.T) {
+	today := time.Now()
+	tomorrow := today.AddDate(0, 0, 1).Format("2006-01-02")
+	yesterday := today.AddDate(0, 0, -1).Format("2006-01-02")
+
+	tasks := []Task{
+		{ID: 1, Status: StatusTodo},
+		{ID: 2, Status: StatusTodo, Wait: tomorrow},
+		{ID: 3, Status: StatusTodo, Until: yesterday},
+		{ID: 4, Status: StatusTodo, Scheduled: tomorrow},
+	}
+
+	ids := func(tasks []Task) []int {
+		var ids []int
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	if got := ids(filterTaskList(tasks, TaskFilter{})); len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Errorf("Tâches en attente et expirées masquées par défaut, obtenu %v", got)
+	}
+	if got := ids(filterTaskList(tasks, TaskFilter{Waiting: true})); len(got) != 1 || got[0] != 2 {
+		t.Errorf("--waiting: seule la tâche 2 attendue, obtenu %v", got)
+	}
+	if got := filterTaskList(tasks, TaskFilter{ShowDone: true}); len(got) != 4 {
+		t.Errorf("--all doit tout afficher, obtenu %v", ids(got))
+	}
+}
+
+func TestPlanningDates_Recur(t *testing.T) {
+	task := Task{Due: "2025-07-15", Recur: "weekly:tue", Scheduled: "2025-07-14", Wait: "2025-07-10", Until: "2025-07-20"}
+	completed := time.Date(2025, 7, 15, 18, 0, 0, 0, time.Local)
+
+	next, ok := nextOccurrence(task, completed)
+	if !ok {
+		t.Fatal("Occurrence suivante attendue")
+	}
+	if next.Due != "2025-07-22" || next.Scheduled != "2025-07-21" || next.Wait != "2025-07-17" || next.Until != "2025-07-27" {
+		t.Errorf("Les dates doivent garder leur écart avec l'échéance: %+v", next)
+	}
+}
+
+func TestPlanningDates_CSVRoundTrip(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	task := Task{Text: "Relancer le client", Scheduled: "2025-07-18", Wait: "2025-07-16", Until: "2025-07-31"}
+	if err := tm.AddTask(task, 0, ""); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+
+	csvFile := filepath.Join(tempDir, "export.csv")
+	if err := tm.ExportCSV(csvFile); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+
+	imported := newTestManager(filepath.Join(tempDir, "autre.json"), nil)
+	if _, err := imported.ImportCSV(csvFile, "merge", "skip", ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	assertTaskCount(t, imported, 1)
+	if got := imported.Tasks[0]; got.Scheduled != task.Scheduled || got.Wait != task.Wait || got.Until != task.Until {
+		t.Errorf("Dates perdues à l'import: %+v", got)
+	}
+}
@@ -0,0 +1,130 @@
+// depends.go - Dépendances entre tâches (todo depends, list --blocked/--ready)
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// openBlockers voir taskIndex.openBlockers (recherche ponctuelle)
+func (tm *TodoManager) openBlockers(task Task) []Task {
+	return tm.newTaskIndex().openBlockers(task)
+}
+
+// openBlockers retourne les tâches ouvertes dont dépend une tâche. Une
+// dépendance vers une tâche terminée, annulée, supprimée ou archivée ne bloque plus.
+func (index *taskIndex) openBlockers(task Task) []Task {
+	var blockers []Task
+	for _, uuid := range task.DependsOn {
+		if i, ok := index.byUUID[uuid]; ok && !index.tasks[i].IsClosed() {
+			blockers = append(blockers, index.tasks[i])
+		}
+	}
+	return blockers
+}
+
+// isBlocked voir taskIndex.isBlocked (recherche ponctuelle)
+func (tm *TodoManager) isBlocked(task Task) bool {
+	return tm.newTaskIndex().isBlocked(task)
+}
+
+// isBlocked indique si une tâche est bloquée (statut blocked) ou attend
+// une autre tâche ouverte
+func (index *taskIndex) isBlocked(task Task) bool {
+	return task.Status == StatusBlocked || len(index.openBlockers(task)) > 0
+}
+
+// dependsOn indique si la tâche uuid dépend, directement ou non, de target
+func (tm *TodoManager) dependsOn(uuid string, target string) bool {
+	index := tm.newTaskIndex()
+	seen := make(map[string]bool)
+	pending := []string{uuid}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		if current == target {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		if i, ok := index.byUUID[current]; ok {
+			pending = append(pending, index.tasks[i].DependsOn...)
+		}
+	}
+	return false
+}
+
+// AddDependency indique que la tâche id attend la tâche blockerID.
+// Une dépendance qui créerait un cycle est refusée.
+func (tm *TodoManager) AddDependency(id int, blockerID int) error {
+	i, j := tm.findTask(id), tm.findTask(blockerID)
+	if i < 0 {
+		return fmt.Errorf("tâche [%d] introuvable", id)
+	}
+	if j < 0 {
+		return fmt.Errorf("tâche [%d] introuvable", blockerID)
+	}
+	task, blocker := tm.Tasks[i], tm.Tasks[j]
+	if i == j {
+		return fmt.Errorf("une tâche ne peut pas dépendre d'elle-même")
+	}
+	for _, uuid := range task.DependsOn {
+		if uuid == blocker.UUID {
+			return fmt.Errorf("la tâche [%d] dépend déjà de [%d]", id, blockerID)
+		}
+	}
+	if tm.dependsOn(blocker.UUID, task.UUID) {
+		return fmt.Errorf("dépendance circulaire : [%d] dépend déjà de [%d]", blockerID, id)
+	}
+
+	tm.Tasks[i].DependsOn = append(append([]string(nil), task.DependsOn...), blocker.UUID)
+	tm.Tasks[i].Updated = timestamp(time.Now())
+	tm.operation = fmt.Sprintf("depends [%d] [%d] %s", id, blockerID, task.Text)
+	tm.save()
+
+	fmt.Printf("🔗 Tâche [%d] bloquée par [%d] %s\n", id, blockerID, blocker.Text)
+	return nil
+}
+
+// RemoveDependency supprime la dépendance de la tâche id envers blockerID
+func (tm *TodoManager) RemoveDependency(id int, blockerID int) error {
+	i, j := tm.findTask(id), tm.findTask(blockerID)
+	if i < 0 {
+		return fmt.Errorf("tâche [%d] introuvable", id)
+	}
+	if j < 0 {
+		return fmt.Errorf("tâche [%d] introuvable", blockerID)
+	}
+
+	var kept []string
+	for _, uuid := range tm.Tasks[i].DependsOn {
+		if uuid != tm.Tasks[j].UUID {
+			kept = append(kept, uuid)
+		}
+	}
+	if len(kept) == len(tm.Tasks[i].DependsOn) {
+		return fmt.Errorf("la tâche [%d] ne dépend pas de [%d]", id, blockerID)
+	}
+
+	tm.Tasks[i].DependsOn = kept
+	tm.Tasks[i].Updated = timestamp(time.Now())
+	tm.operation = fmt.Sprintf("depends --remove [%d] [%d] %s", id, blockerID, tm.Tasks[i].Text)
+	tm.save()
+
+	fmt.Printf("🔓 Tâche [%d] ne dépend plus de [%d]\n", id, blockerID)
+	return nil
+}
+
+// filterBlocked garde les tâches bloquées (blocked) ou prêtes (!blocked)
+func (tm *TodoManager) filterBlocked(tasks []Task, blocked bool) []Task {
+	index := tm.newTaskIndex()
+	var filtered []Task
+	for _, task := range tasks {
+		if index.isBlocked(task) == blocked {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,107 @@
+// depends_test.go - Tests des dépendances entre tâches
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDependencies(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Choisir un hébergeur", nil, "", "")
+	tm.Add("Configurer le DNS", nil, "", "")
+	tm.Add("Mettre en ligne", nil, "", "")
+
+	if err := tm.AddDependency(2, 1); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+	if err := tm.AddDependency(3, 2); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+
+	t.Run("refus des cycles", func(t *testing.T) {
+		for _, pair := range [][2]int{{1, 3}, {1, 2}, {2, 2}, {3, 2}} {
+			if err := tm.AddDependency(pair[0], pair[1]); err == nil {
+				t.Errorf("La dépendance %d -> %d doit être refusée", pair[0], pair[1])
+			}
+		}
+		if err := tm.AddDependency(4, 1); err == nil {
+			t.Error("Une tâche inexistante doit être refusée")
+		}
+	})
+
+	t.Run("bloquées et prêtes", func(t *testing.T) {
+		tm := reloadManager(t, tm.filename)
+		if tm.isBlocked(*assertTaskExists(t, tm, 1)) || !tm.isBlocked(*assertTaskExists(t, tm, 2)) {
+			t.Error("Seule la tâche 1 devrait être prête")
+		}
+		if got := tm.filterBlocked(tm.Tasks, true); len(got) != 2 {
+			t.Errorf("2 tâches bloquées attendues, obtenu %d", len(got))
+		}
+
+		tm.Done(1)
+		if tm.isBlocked(*assertTaskExists(t, tm, 2)) || !tm.isBlocked(*assertTaskExists(t, tm, 3)) {
+			t.Error("Terminer la tâche 1 doit débloquer la 2, pas la 3")
+		}
+	})
+
+	t.Run("survit à l'import", func(t *testing.T) {
+		csvFile := filepath.Join(tempDir, "export.csv")
+		if err := tm.ExportCSV(csvFile); err != nil {
+			t.Fatalf("ExportCSV: %v", err)
+		}
+
+		imported := newTestManager(filepath.Join(tempDir, "autre.json"), nil)
+		imported.Add("Tâche existante", nil, "", "")
+		if _, err := imported.ImportCSV(csvFile, "merge", "skip", ImportOptions{}); err != nil {
+			t.Fatalf("ImportCSV: %v", err)
+		}
+		// Les dépendances suivent les UUID, pas les IDs
+		for _, task := range imported.Tasks {
+			if task.UUID == tm.Tasks[2].UUID {
+				if blockers := imported.openBlockers(task); len(blockers) != 1 || blockers[0].UUID != tm.Tasks[1].UUID {
+					t.Errorf("La tâche importée doit être bloquée par « Configurer le DNS »: %+v", blockers)
+				}
+				return
+			}
+		}
+		t.Error("Tâche « Mettre en ligne » absente après import")
+	})
+
+	t.Run("suppression", func(t *testing.T) {
+		if err := tm.RemoveDependency(3, 2); err != nil {
+			t.Fatalf("RemoveDependency: %v", err)
+		}
+		if err := tm.RemoveDependency(3, 2); err == nil {
+			t.Error("Une dépendance absente ne peut pas être supprimée")
+		}
+		if err := tm.AddDependency(2, 3); err != nil {
+			t.Errorf("Sans la dépendance 3 -> 2, 2 -> 3 n'est plus un cycle: %v", err)
+		}
+	})
+}
+
+func TestDependsOn_LongChain(t *testing.T) {
+	// Chaque tâche attend la précédente : la recherche de cycle parcourt toute la chaîne
+	const count = 20000
+	tm := &TodoManager{}
+	for i := 0; i < count; i++ {
+		task := Task{ID: i + 1, UUID: fmt.Sprintf("t%d", i)}
+		if i > 0 {
+			task.DependsOn = []string{fmt.Sprintf("t%d", i-1)}
+		}
+		tm.Tasks = append(tm.Tasks, task)
+	}
+
+	start := time.Now()
+	if !tm.dependsOn(fmt.Sprintf("t%d", count-1), "t0") || tm.dependsOn("t0", "t1") {
+		t.Error("Dépendance transitive mal détectée")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Recherche de cycle trop lente sur %d tâches: %v", count, elapsed)
+	}
+}
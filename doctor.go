@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DoctorIssue problème détecté dans le fichier de tâches
+type DoctorIssue struct {
+	Severity string // "error" ou "warning"
+	Message  string
+}
+
+// DoctorReport résultat du diagnostic d'un fichier de tâches
+type DoctorReport struct {
+	Issues    []DoctorIssue
+	Malformed bool   // JSON illisible
+	Tasks     []Task // Tâches lisibles (récupérées si JSON illisible)
+	Trash     []Task // Corbeille (vide si JSON illisible)
+	NextID    int
+}
+
+// addIssue ajoute un problème au rapport
+func (r *DoctorReport) addIssue(severity string, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, DoctorIssue{Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+// diagnoseTodoData analyse le contenu brut d'un fichier de tâches
+func diagnoseTodoData(data []byte) *DoctorReport {
+	report := &DoctorReport{}
+
+	// Diagnostiquer au format courant (un ancien schéma n'est pas une erreur)
+	if migrated, err := migrateTodoData(data, nil); err == nil {
+		data = migrated
+	} else if errors.Is(err, errNewerSchema) {
+		report.addIssue("error", "%v", err)
+		return report
+	}
+
+	var parsed TodoManager
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		report.Malformed = true
+		report.Tasks, report.NextID = salvageTasks(data)
+		report.addIssue("error", "JSON invalide : %v (%d tâche(s) récupérable(s))", err, len(report.Tasks))
+	} else {
+		report.Tasks = parsed.Tasks
+		report.Trash = parsed.Trash
+		report.NextID = parsed.NextID
+	}
+
+	tm := &TodoManager{}
+	ids := make(map[int]int)
+	uuids := make(map[string]int)
+	maxID := 0
+
+	for _, task := range report.Tasks {
+		ids[task.ID]++
+		if task.ID > maxID {
+			maxID = task.ID
+		}
+		if task.ID <= 0 {
+			report.addIssue("error", "tâche '%s' : ID %d invalide", task.Text, task.ID)
+		}
+
+		if task.UUID == "" {
+			report.addIssue("error", "tâche [%d] : UUID manquant", task.ID)
+		} else {
+			uuids[task.UUID]++
+			if !tm.isValidUUID(task.UUID) {
+				report.addIssue("warning", "tâche [%d] : UUID '%s' mal formé", task.ID, task.UUID)
+			}
+		}
+
+		if !validStoredDate(task.Due) {
+			report.addIssue("error", "tâche [%d] : date limite '%s' invalide", task.ID, task.Due)
+		}
+		if task.Priority != "" && parsePriority(task.Priority) != task.Priority {
+			report.addIssue("error", "tâche [%d] : priorité '%s' invalide", task.ID, task.Priority)
+		}
+		if task.Created != "" && !tm.isValidDateTime(task.Created) {
+			report.addIssue("warning", "tâche [%d] : date de création '%s' invalide", task.ID, task.Created)
+		}
+		if task.Updated != "" && !tm.isValidDateTime(task.Updated) {
+			report.addIssue("warning", "tâche [%d] : date de mise à jour '%s' invalide", task.ID, task.Updated)
+		}
+		if _, ok := statusIcons[task.Status]; !ok {
+			report.addIssue("error", "tâche [%d] : statut '%s' invalide", task.ID, task.Status)
+		}
+		for _, field := range planningFields(&task) {
+			if !validStoredDate(*field.value) {
+				report.addIssue("error", "tâche [%d] : %s '%s' invalide", task.ID, field.label, *field.value)
+			}
+		}
+		if normalized, err := normalizeEstimate(task.Estimate); err != nil {
+			report.addIssue("error", "tâche [%d] : estimation '%s' invalide", task.ID, task.Estimate)
+		} else if normalized != task.Estimate {
+			report.addIssue("warning", "tâche [%d] : estimation '%s' non normalisée (%s)", task.ID, task.Estimate, normalized)
+		}
+	}
+
+	// Références vers une tâche inconnue : ni active, ni dans la corbeille, ni
+	// supprimée définitivement (une tâche archivée n'est plus connue du fichier)
+	known := removedUUIDs(report.Trash, parsed.Tombstones)
+	for uuid := range uuids {
+		known[uuid] = true
+	}
+	for _, task := range report.Tasks {
+		if task.Parent != "" && task.Parent == task.UUID {
+			report.addIssue("error", "tâche [%d] : tâche parente d'elle-même", task.ID)
+		} else if task.Parent != "" && !known[task.Parent] {
+			report.addIssue("warning", "tâche [%d] : tâche parente %s introuvable", task.ID, task.Parent)
+		}
+		for _, uuid := range task.DependsOn {
+			if uuid == task.UUID {
+				report.addIssue("error", "tâche [%d] : dépend d'elle-même", task.ID)
+			} else if !known[uuid] {
+				report.addIssue("warning", "tâche [%d] : dépendance %s introuvable", task.ID, uuid)
+			}
+		}
+	}
+
+	trashUUIDs := make(map[string]int)
+	for _, task := range report.Trash {
+		if task.UUID == "" {
+			report.addIssue("error", "corbeille : tâche [%d] '%s' sans UUID", task.ID, task.Text)
+			continue
+		}
+		trashUUIDs[task.UUID]++
+		if uuids[task.UUID] > 0 {
+			report.addIssue("error", "corbeille : UUID %s aussi présent parmi les tâches", task.UUID)
+		}
+		if !tm.isValidDateTime(task.Deleted) {
+			report.addIssue("warning", "corbeille : tâche [%d] : date de suppression '%s' invalide", task.ID, task.Deleted)
+		}
+	}
+	for uuid, count := range trashUUIDs {
+		if count > 1 {
+			report.addIssue("error", "corbeille : UUID %s utilisé par %d tâches", uuid, count)
+		}
+	}
+
+	for id, count := range ids {
+		if count > 1 {
+			report.addIssue("error", "ID %d utilisé par %d tâches", id, count)
+		}
+	}
+	for uuid, count := range uuids {
+		if count > 1 {
+			report.addIssue("error", "UUID %s utilisé par %d tâches", uuid, count)
+		}
+	}
+	if report.NextID <= maxID {
+		report.addIssue("error", "NextID (%d) inférieur ou égal au plus grand ID (%d)", report.NextID, maxID)
+	}
+
+	return report
+}
+
+// dateField date d'une tâche vérifiée par doctor, avec son libellé
+type dateField struct {
+	label string
+	value *string
+}
+
+// planningFields retourne les dates de planification d'une tâche
+func planningFields(task *Task) []dateField {
+	return []dateField{
+		{"date de début prévue", &task.Scheduled},
+		{"date d'attente", &task.Wait},
+		{"date d'expiration", &task.Until},
+	}
+}
+
+// removedUUIDs retourne les UUID de la corbeille et des suppressions définitives
+func removedUUIDs(trash []Task, tombstones []Tombstone) map[string]bool {
+	removed := make(map[string]bool)
+	for _, task := range trash {
+		if task.UUID != "" {
+			removed[task.UUID] = true
+		}
+	}
+	for _, tombstone := range tombstones {
+		removed[tombstone.UUID] = true
+	}
+	return removed
+}
+
+// salvageTasks récupère les tâches lisibles d'un JSON tronqué ou corrompu,
+// jusqu'au premier élément illisible
+func salvageTasks(data []byte) ([]Task, int) {
+	var tasks []Task
+	nextID := 0
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return tasks, nextID
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return tasks, nextID
+		}
+
+		switch key {
+		case "tasks":
+			if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+				return tasks, nextID
+			}
+			for dec.More() {
+				var task Task
+				if err := dec.Decode(&task); err != nil {
+					return tasks, nextID
+				}
+				tasks = append(tasks, task)
+			}
+			if _, err := dec.Token(); err != nil {
+				return tasks, nextID
+			}
+		case "nextId":
+			if err := dec.Decode(&nextID); err != nil {
+				return tasks, nextID
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return tasks, nextID
+			}
+		}
+	}
+
+	return tasks, nextID
+}
+
+// repairTasks corrige les problèmes réparables et décrit les corrections.
+// removed contient les UUID supprimés, que les références peuvent encore viser.
+func repairTasks(tasks []Task, nextID int, removed map[string]bool) ([]Task, int, []string) {
+	var actions []string
+	tm := &TodoManager{}
+	now := timestamp(time.Now())
+
+	maxID := 0
+	for _, task := range tasks {
+		if task.ID > maxID {
+			maxID = task.ID
+		}
+	}
+	if nextID <= maxID {
+		actions = append(actions, fmt.Sprintf("NextID %d → %d", nextID, maxID+1))
+		nextID = maxID + 1
+	}
+
+	seenIDs := make(map[int]bool)
+	seenUUIDs := make(map[string]bool)
+	repaired := make([]Task, 0, len(tasks))
+
+	for _, task := range tasks {
+		if task.ID <= 0 || seenIDs[task.ID] {
+			actions = append(actions, fmt.Sprintf("tâche '%s' : ID %d → %d", task.Text, task.ID, nextID))
+			task.ID = nextID
+			nextID++
+		}
+		seenIDs[task.ID] = true
+
+		if task.UUID == "" || seenUUIDs[task.UUID] {
+			task.UUID = generateUUID()
+			actions = append(actions, fmt.Sprintf("tâche [%d] : nouvel UUID %s", task.ID, task.UUID))
+		}
+		seenUUIDs[task.UUID] = true
+
+		if !validStoredDate(task.Due) {
+			actions = append(actions, fmt.Sprintf("tâche [%d] : date limite '%s' supprimée", task.ID, task.Due))
+			task.Due = ""
+		}
+		if task.Priority != "" && parsePriority(task.Priority) != task.Priority {
+			normalized := parsePriority(task.Priority)
+			actions = append(actions, fmt.Sprintf("tâche [%d] : priorité '%s' → '%s'", task.ID, task.Priority, normalized))
+			task.Priority = normalized
+		}
+		if task.Created != "" && !tm.isValidDateTime(task.Created) {
+			actions = append(actions, fmt.Sprintf("tâche [%d] : date de création réinitialisée", task.ID))
+			task.Created = now
+		}
+		if task.Updated != "" && !tm.isValidDateTime(task.Updated) {
+			actions = append(actions, fmt.Sprintf("tâche [%d] : date de mise à jour réinitialisée", task.ID))
+			task.Updated = task.Created
+		}
+		if _, ok := statusIcons[task.Status]; !ok {
+			status, err := parseStatus(task.Status)
+			if err != nil {
+				status = StatusTodo
+			}
+			actions = append(actions, fmt.Sprintf("tâche [%d] : statut '%s' → '%s'", task.ID, task.Status, status))
+			task.Status = status
+		}
+		for _, field := range planningFields(&task) {
+			if !validStoredDate(*field.value) {
+				actions = append(actions, fmt.Sprintf("tâche [%d] : %s '%s' supprimée", task.ID, field.label, *field.value))
+				*field.value = ""
+			}
+		}
+		if normalized, err := normalizeEstimate(task.Estimate); err != nil {
+			actions = append(actions, fmt.Sprintf("tâche [%d] : estimation '%s' supprimée", task.ID, task.Estimate))
+			task.Estimate = ""
+		} else if normalized != task.Estimate {
+			actions = append(actions, fmt.Sprintf("tâche [%d] : estimation '%s' → '%s'", task.ID, task.Estimate, normalized))
+			task.Estimate = normalized
+		}
+
+		repaired = append(repaired, task)
+	}
+
+	known := make(map[string]bool)
+	for uuid := range removed {
+		known[uuid] = true
+	}
+	for _, task := range repaired {
+		known[task.UUID] = true
+	}
+	for i := range repaired {
+		task := &repaired[i]
+		if task.Parent != "" && (task.Parent == task.UUID || !known[task.Parent]) {
+			actions = append(actions, fmt.Sprintf("tâche [%d] : rattachement à la tâche parente %s retiré", task.ID, task.Parent))
+			task.Parent = ""
+		}
+		var kept []string
+		for _, uuid := range task.DependsOn {
+			if uuid != task.UUID && known[uuid] {
+				kept = append(kept, uuid)
+			} else {
+				actions = append(actions, fmt.Sprintf("tâche [%d] : dépendance %s retirée", task.ID, uuid))
+			}
+		}
+		if len(kept) != len(task.DependsOn) {
+			task.DependsOn = kept
+		}
+	}
+
+	return repaired, nextID, actions
+}
+
+// repairTrash corrige la corbeille : UUID manquant, date de suppression
+// invalide, doublons et tâches encore présentes parmi les tâches actives
+func repairTrash(trash []Task, tasks []Task) ([]Task, []string) {
+	var actions []string
+	tm := &TodoManager{}
+	now := timestamp(time.Now())
+
+	active := make(map[string]bool)
+	for _, task := range tasks {
+		if task.UUID != "" {
+			active[task.UUID] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	repaired := make([]Task, 0, len(trash))
+	for _, task := range trash {
+		if task.UUID == "" {
+			task.UUID = generateUUID()
+			actions = append(actions, fmt.Sprintf("corbeille : tâche [%d] : nouvel UUID %s", task.ID, task.UUID))
+		}
+		if active[task.UUID] || seen[task.UUID] {
+			actions = append(actions, fmt.Sprintf("corbeille : tâche [%d] '%s' en double retirée", task.ID, task.Text))
+			continue
+		}
+		seen[task.UUID] = true
+
+		if !tm.isValidDateTime(task.Deleted) {
+			actions = append(actions, fmt.Sprintf("corbeille : tâche [%d] : date de suppression réinitialisée", task.ID))
+			task.Deleted = now
+		}
+		repaired = append(repaired, task)
+	}
+
+	return repaired, actions
+}
+
+// mergeBackupTasks complète les tâches récupérées avec celles de la sauvegarde
+func mergeBackupTasks(salvaged []Task, backup []Task) ([]Task, int) {
+	known := make(map[string]bool)
+	for _, task := range salvaged {
+		known[task.UUID] = true
+	}
+
+	added := 0
+	for _, task := range backup {
+		if task.UUID != "" && !known[task.UUID] {
+			salvaged = append(salvaged, task)
+			added++
+		}
+	}
+	return salvaged, added
+}
+
+// Doctor diagnostique le fichier de tâches et le répare si demandé.
+// Retourne false si des problèmes subsistent. Seul le stockage json (chiffré
+// ou non) est vérifié : les autres n'écrivent pas dans tm.filename.
+func (tm *TodoManager) Doctor(fix bool, check bool) bool {
+	if _, ok := tm.storage().(*JSONFileStore); !ok {
+		fmt.Println("❌ doctor ne s'applique qu'aux stockages json et encrypted (relancez avec --store=json)")
+		return false
+	}
+	fmt.Printf("🩺 Diagnostic de %s\n", tm.filename)
+
+	data, err := readDataFile(tm.filename)
+	if os.IsNotExist(err) {
+		fmt.Println("📝 Aucun fichier de tâches, rien à vérifier")
+		return true
+	}
+	if err != nil {
+		fmt.Printf("❌ Fichier illisible : %v\n", err)
+		return false
+	}
+
+	report := diagnoseTodoData(data)
+	if len(report.Issues) == 0 {
+		fmt.Printf("✅ Aucun problème détecté (%d tâches)\n", len(report.Tasks))
+		return true
+	}
+
+	for _, issue := range report.Issues {
+		icon := "❌"
+		if issue.Severity == "warning" {
+			icon = "⚠️"
+		}
+		fmt.Printf("%s %s\n", icon, issue.Message)
+	}
+
+	// Une sauvegarde valide complète les tâches perdues d'un JSON illisible
+	var backupTasks []Task
+	backupNextID := 0
+	if report.Malformed {
+		if backupData, err := readDataFile(tm.filename + ".bak"); err == nil {
+			backupReport := diagnoseTodoData(backupData)
+			if !backupReport.Malformed {
+				backupTasks = backupReport.Tasks
+				backupNextID = backupReport.NextID
+				fmt.Printf("💾 Sauvegarde valide trouvée : %s (%d tâches)\n", tm.filename+".bak", len(backupTasks))
+			}
+		}
+	}
+
+	if check {
+		return false
+	}
+	if !fix && !confirmRepair() {
+		fmt.Println("❌ Réparation annulée")
+		return false
+	}
+
+	tasks := report.Tasks
+	nextID := report.NextID
+	if backupTasks != nil {
+		var added int
+		tasks, added = mergeBackupTasks(tasks, backupTasks)
+		if added > 0 {
+			fmt.Printf("💾 %d tâche(s) restaurée(s) depuis la sauvegarde\n", added)
+		}
+		if backupNextID > nextID {
+			nextID = backupNextID
+		}
+	}
+
+	// Corbeille du fichier, ou de la sauvegarde chargée si le JSON est illisible
+	trash := report.Trash
+	if report.Malformed {
+		trash = tm.Trash
+	}
+	trash, trashActions := repairTrash(trash, tasks)
+	repaired, nextID, actions := repairTasks(tasks, nextID, removedUUIDs(trash, tm.Tombstones))
+	actions = append(actions, trashActions...)
+
+	quarantine := quarantineName(tm.filename)
+	if err := copyFile(tm.filename, quarantine); err != nil {
+		fmt.Printf("❌ Impossible de mettre l'original en quarantaine : %v\n", err)
+		return false
+	}
+
+	tm.Tasks = repaired
+	tm.Trash = trash
+	tm.NextID = nextID
+	repairedData, err := encodeTodoData(tm)
+	if err == nil {
+		repairedData, err = sealData(repairedData, tm.cipher())
+	}
+	if err == nil {
+		err = writeFileAtomic(tm.filename, repairedData, dataFileMode, "")
+	}
+	if err != nil {
+		fmt.Printf("❌ Erreur lors de l'écriture : %v\n", err)
+		return false
+	}
+
+	fmt.Printf("\n🔧 Fichier réparé (%d tâches), original en quarantaine : %s\n", len(repaired), quarantine)
+	for _, action := range actions {
+		fmt.Printf("  - %s\n", action)
+	}
+	return true
+}
+
+// confirmRepair demande confirmation avant réparation
+func confirmRepair() bool {
+	fmt.Print("\nRéparer automatiquement ? (y/N) ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+
+	return response == "y" || response == "yes" || response == "o" || response == "oui"
+}
@@ -0,0 +1,227 @@
+// doctor_test.go - Tests du diagnostic et de la réparation
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseTodoData(t *testing.T) {
+	t.Run("fichier sain", func(t *testing.T) {
+		data := `{"tasks": [{"id": 1, "uuid": "a1b2c3d4-e5f6-4789-8abc-def012345678", "text": "OK",
+			"priority": "high", "due": "2025-07-20", "created": "2025-07-09 10:00:00", "updated": "2025-07-09 10:00:00"}],
+			"nextId": 2}`
+		report := diagnoseTodoData([]byte(data))
+		if len(report.Issues) != 0 {
+			t.Errorf("Aucun problème attendu, obtenu: %v", report.Issues)
+		}
+	})
+
+	t.Run("incohérences détectées", func(t *testing.T) {
+		data := `{"tasks": [
+			{"id": 1, "uuid": "same", "text": "A", "due": "2025-13-40"},
+			{"id": 1, "uuid": "same", "text": "B", "priority": "urgent"}
+		], "nextId": 1}`
+		report := diagnoseTodoData([]byte(data))
+
+		messages := ""
+		for _, issue := range report.Issues {
+			messages += issue.Message + "\n"
+		}
+		for _, expected := range []string{"ID 1 utilisé par 2", "UUID same utilisé par 2", "NextID (1)", "date limite '2025-13-40'", "priorité 'urgent'"} {
+			if !strings.Contains(messages, expected) {
+				t.Errorf("Problème '%s' non détecté dans:\n%s", expected, messages)
+			}
+		}
+	})
+
+	t.Run("statut, planification et références", func(t *testing.T) {
+		data := `{"schemaVersion": 3, "tasks": [
+			{"id": 1, "uuid": "u1", "text": "A", "status": "paused", "scheduled": "2025-02-30", "estimate": "beaucoup"},
+			{"id": 2, "uuid": "u2", "text": "B", "parent": "absente", "dependsOn": ["u1", "u2", "inconnue", "u3"], "wait": "bientôt"},
+			{"id": 3, "uuid": "u4", "text": "C", "parent": "u3", "until": "2025-07-20", "estimate": "2h00"}
+		], "trash": [
+			{"id": 4, "uuid": "u3", "text": "Supprimée", "deleted": "2025-07-01T10:00:00Z"},
+			{"id": 5, "uuid": "u1", "text": "Doublon", "deleted": "hier"},
+			{"id": 6, "text": "Sans UUID", "deleted": "2025-07-01T10:00:00Z"}
+		], "nextId": 7}`
+		report := diagnoseTodoData([]byte(data))
+
+		messages := ""
+		for _, issue := range report.Issues {
+			messages += issue.Message + "\n"
+		}
+		for _, expected := range []string{"statut 'paused'", "date de début prévue '2025-02-30'", "estimation 'beaucoup'",
+			"date d'attente 'bientôt'", "tâche parente absente introuvable", "[2] : dépend d'elle-même", "dépendance inconnue introuvable",
+			"UUID u1 aussi présent", "date de suppression 'hier'", "[6] 'Sans UUID' sans UUID"} {
+			if !strings.Contains(messages, expected) {
+				t.Errorf("Problème '%s' non détecté dans:\n%s", expected, messages)
+			}
+		}
+		// Une référence vers une tâche de la corbeille reste valide
+		for _, unexpected := range []string{"u3 introuvable", "[3] : tâche parente", "[3] : estimation"} {
+			if strings.Contains(messages, unexpected) {
+				t.Errorf("Problème '%s' inattendu dans:\n%s", unexpected, messages)
+			}
+		}
+	})
+
+	t.Run("JSON tronqué", func(t *testing.T) {
+		data := `{"nextId": 3, "tasks": [{"id": 1, "uuid": "u1", "text": "Complète"}, {"id": 2, "text": "Tron`
+		report := diagnoseTodoData([]byte(data))
+		if !report.Malformed {
+			t.Error("Le JSON tronqué devrait être signalé")
+		}
+		if len(report.Tasks) != 1 || report.Tasks[0].Text != "Complète" {
+			t.Errorf("Une tâche récupérable attendue, obtenu: %v", report.Tasks)
+		}
+		if report.NextID != 3 {
+			t.Errorf("NextID récupéré attendu: 3, obtenu: %d", report.NextID)
+		}
+	})
+}
+
+func TestRepairTasks(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, UUID: "u1", Text: "A", Priority: "h", Created: "2025-07-09 10:00:00", Updated: "2025-07-09 10:00:00"},
+		{ID: 1, UUID: "u1", Text: "B", Due: "demain", Created: "hier", Updated: "2025-07-09 10:00:00"},
+		{ID: 0, UUID: "", Text: "C"},
+		{ID: 4, UUID: "u4", Text: "D", Status: "canceled", Parent: "u4", DependsOn: []string{"u1", "supprimée", "inconnue"}, Wait: "bientôt", Estimate: "90m"},
+		{ID: 5, UUID: "u5", Text: "E", Status: "paused", Parent: "inconnue", Until: "2025-07-20", Estimate: "beaucoup"},
+	}
+
+	repaired, nextID, actions := repairTasks(tasks, 1, map[string]bool{"supprimée": true})
+	if len(actions) == 0 {
+		t.Fatal("Des corrections étaient attendues")
+	}
+
+	report := diagnoseTodoData(mustEncode(t, repaired, nextID))
+	for _, issue := range report.Issues {
+		if issue.Severity == "error" {
+			t.Errorf("Problème restant après réparation: %s", issue.Message)
+		}
+	}
+	if repaired[0].Priority != "high" {
+		t.Errorf("Priorité 'h' devrait devenir 'high', obtenu: %s", repaired[0].Priority)
+	}
+
+	d, e := repaired[3], repaired[4]
+	if d.Status != StatusCancelled || e.Status != StatusTodo {
+		t.Errorf("Statuts réparés inattendus: %s, %s", d.Status, e.Status)
+	}
+	if d.Parent != "" || e.Parent != "" || strings.Join(d.DependsOn, " ") != "u1 supprimée" {
+		t.Errorf("Références réparées inattendues: %+v / %+v", d, e)
+	}
+	if d.Wait != "" || e.Until != "2025-07-20" || d.Estimate != "1h30" || e.Estimate != "" {
+		t.Errorf("Planification réparée inattendue: %+v / %+v", d, e)
+	}
+}
+
+func TestRepairTrash(t *testing.T) {
+	active := "a1b2c3d4-e5f6-4789-8abc-def012345678"
+	tasks := []Task{{ID: 1, UUID: active, Text: "Active"}}
+	trash := []Task{
+		{ID: 2, UUID: "u2", Text: "Valide", Deleted: "2025-07-01T10:00:00Z"},
+		{ID: 3, UUID: active, Text: "Copie de l'active", Deleted: "2025-07-01T10:00:00Z"},
+		{ID: 4, UUID: "u2", Text: "Doublon", Deleted: "2025-07-01T10:00:00Z"},
+		{ID: 5, Text: "Sans UUID", Deleted: "hier"},
+	}
+
+	repaired, actions := repairTrash(trash, tasks)
+	if len(repaired) != 2 || repaired[0].UUID != "u2" || len(actions) != 4 {
+		t.Fatalf("Corbeille réparée inattendue: %+v (%v)", repaired, actions)
+	}
+	if repaired[1].UUID == "" || repaired[1].Deleted == "hier" {
+		t.Errorf("UUID et date de suppression attendus: %+v", repaired[1])
+	}
+
+	data, err := encodeTodoData(&TodoManager{Tasks: tasks, Trash: repaired, NextID: 6})
+	if err != nil {
+		t.Fatalf("Erreur d'encodage: %v", err)
+	}
+	if report := diagnoseTodoData(data); len(report.Issues) != 0 {
+		t.Errorf("Problèmes restants après réparation: %v", report.Issues)
+	}
+}
+
+func mustEncode(t *testing.T, tasks []Task, nextID int) []byte {
+	t.Helper()
+	data, err := encodeTodoData(&TodoManager{Tasks: tasks, NextID: nextID})
+	if err != nil {
+		t.Fatalf("Erreur d'encodage: %v", err)
+	}
+	return data
+}
+
+func TestTodoManager_DoctorHealthyReferences(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	// Sous-tâches, dépendances et corbeille créées par les commandes : rien à signaler
+	tm.Add("Parente", nil, "", "")
+	if err := tm.AddSubtask(1, "Enfant", nil, "", ""); err != nil {
+		t.Fatalf("AddSubtask: %v", err)
+	}
+	tm.Add("Bloquante", nil, "", "")
+	if err := tm.AddDependency(2, 3); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+	tm.Update(2, TaskEdit{Estimate: "1h30", Wait: "2025-07-20"})
+	tm.Remove(1)
+	tm.Remove(3)
+
+	if !reloadManager(t, tm.filename).Doctor(false, true) {
+		t.Error("Des références vers la corbeille ne sont pas des problèmes")
+	}
+}
+
+func TestTodoManager_Doctor(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Tâche 1", nil, "", "")
+	tm.Add("Tâche 2", nil, "", "")
+	tm.Add("Tâche 3", nil, "", "")
+
+	// Tronquer le fichier au milieu de la troisième tâche
+	data, _ := ioutil.ReadFile(tm.filename)
+	truncated := data[:strings.Index(string(data), "Tâche 3")]
+	if err := ioutil.WriteFile(tm.filename, truncated, 0644); err != nil {
+		t.Fatalf("Impossible de tronquer le fichier: %v", err)
+	}
+
+	if tm.Doctor(false, true) {
+		t.Error("--check devrait signaler le fichier tronqué")
+	}
+	if !tm.Doctor(true, false) {
+		t.Fatal("La réparation devrait réussir")
+	}
+
+	quarantined, _ := filepath.Glob(filepath.Join(tempDir, "*.corrupt-*"))
+	if len(quarantined) != 1 {
+		t.Errorf("Un fichier en quarantaine attendu, obtenu: %v", quarantined)
+	}
+
+	tm2 := newTestManager(tm.filename, nil)
+	if err := tm2.load(); err != nil {
+		t.Fatalf("Fichier réparé illisible: %v", err)
+	}
+	// Tâche 1 récupérée du fichier tronqué, tâche 2 depuis la sauvegarde
+	assertTaskCount(t, tm2, 2)
+	if !tm2.Doctor(false, true) {
+		t.Error("Le fichier réparé ne devrait plus présenter de problème")
+	}
+
+	t.Run("autre stockage", func(t *testing.T) {
+		before, _ := ioutil.ReadFile(tm.filename)
+		journal := newTestManager(tm.filename, NewJournalStore(filepath.Join(tempDir, "todo.journal")))
+		if journal.Doctor(true, false) {
+			t.Error("doctor doit refuser un stockage autre que json")
+		}
+		if after, _ := ioutil.ReadFile(tm.filename); string(after) != string(before) {
+			t.Error("Le fichier JSON ne doit pas être touché")
+		}
+	})
+}
@@ -0,0 +1,205 @@
+// estimate.go - Estimations d'effort, reste à faire et précision (todo accuracy)
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Estimate estimation d'effort : une durée ou des points (story points)
+type Estimate struct {
+	Duration time.Duration
+	Points   float64
+}
+
+// pointSuffixes suffixes des estimations en points (du plus long au plus court)
+var pointSuffixes = []string{"points", "point", "pts", "pt", "sp"}
+
+// parseEstimate lit une estimation : durée (2h, 45m, 1h30) ou points (3pt,
+// 5sp, 0.5pt). Un nombre seul compte en points.
+func parseEstimate(value string) (Estimate, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	invalid := fmt.Errorf("estimation '%s' invalide (ex: 2h, 45m, 3pt)", value)
+
+	number := value
+	for _, suffix := range pointSuffixes {
+		if trimmed, found := strings.CutSuffix(value, suffix); found {
+			number = strings.TrimSpace(trimmed)
+			break
+		}
+	}
+	if points, err := strconv.ParseFloat(number, 64); err == nil {
+		if points <= 0 {
+			return Estimate{}, invalid
+		}
+		return Estimate{Points: points}, nil
+	}
+
+	duration, err := parseTimeSpent(value)
+	if err != nil || duration < time.Minute { // Enregistrée à la minute : "10s" deviendrait "0m"
+		return Estimate{}, invalid
+	}
+	return Estimate{Duration: duration}, nil
+}
+
+// String forme canonique, telle qu'enregistrée dans Task.Estimate
+func (e Estimate) String() string {
+	if e.Points > 0 {
+		return strconv.FormatFloat(e.Points, 'f', -1, 64) + "pt"
+	}
+	return formatDuration(e.Duration)
+}
+
+// normalizeEstimate convertit une estimation saisie vers sa forme enregistrée
+func normalizeEstimate(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	estimate, err := parseEstimate(value)
+	if err != nil {
+		return "", err
+	}
+	return estimate.String(), nil
+}
+
+// EstimateTotals somme des estimations d'un groupe de tâches. Le reste à
+// faire d'une tâche fermée est nul ; pour une durée, le temps suivi est déduit.
+type EstimateTotals struct {
+	Duration, Remaining     time.Duration
+	Points, RemainingPoints float64
+}
+
+// add ajoute l'estimation d'une tâche aux totaux
+func (totals *EstimateTotals) add(task Task, now time.Time) {
+	estimate, err := parseEstimate(task.Estimate)
+	if task.Estimate == "" || err != nil {
+		return
+	}
+	totals.Duration += estimate.Duration
+	totals.Points += estimate.Points
+	if task.IsClosed() {
+		return
+	}
+	totals.RemainingPoints += estimate.Points
+	if remaining := estimate.Duration - timeSpent(task, time.Time{}, now, now); remaining > 0 {
+		totals.Remaining += remaining
+	}
+}
+
+// String affiche les totaux : 5h30 (reste 3h10) · 8pt (reste 5pt)
+func (totals EstimateTotals) String() string {
+	var parts []string
+	if totals.Duration > 0 {
+		parts = append(parts, fmt.Sprintf("%s (reste %s)", formatDuration(totals.Duration), formatDuration(totals.Remaining)))
+	}
+	if totals.Points > 0 {
+		parts = append(parts, fmt.Sprintf("%s (reste %s)", Estimate{Points: totals.Points}, Estimate{Points: totals.RemainingPoints}))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// printEstimateSummary affiche sous todo list la somme des estimations par
+// +projet et @contexte (rien si aucune tâche n'est estimée)
+func printEstimateSummary(tasks []Task, now time.Time) {
+	groups := make(map[string]*EstimateTotals)
+	var total EstimateTotals
+	for _, task := range tasks {
+		if task.Estimate == "" {
+			continue
+		}
+		total.add(task, now)
+		for _, tag := range task.Tags {
+			if strings.HasPrefix(tag, "+") || strings.HasPrefix(tag, "@") {
+				if groups[tag] == nil {
+					groups[tag] = &EstimateTotals{}
+				}
+				groups[tag].add(task, now)
+			}
+		}
+	}
+	if total.Duration == 0 && total.Points == 0 {
+		return
+	}
+
+	// Projets puis contextes, par ordre alphabétique
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i][0] != names[j][0] {
+			return names[i][0] == '+'
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Printf("\n📊 Estimations\n")
+	for _, name := range names {
+		fmt.Printf("   %s%-20s%s %s\n", ColorBlue, name, ColorReset, groups[name])
+	}
+	fmt.Printf("   %s%-20s%s %s\n", ColorBold, "Total", ColorReset, total)
+}
+
+// Accuracy compare les estimations en durée au temps suivi des tâches
+// terminées (archives comprises), et donne le temps moyen par point
+func (tm *TodoManager) Accuracy() error {
+	archived, err := tm.ArchivedTasks()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🎯 Précision des estimations (tâches terminées avec temps suivi)")
+	now := time.Now()
+	var estimated, spent, pointsSpent time.Duration
+	var points, errorSum float64
+	var compared int
+	for _, task := range append(append([]Task(nil), tm.Tasks...), archived...) {
+		estimate, err := parseEstimate(task.Estimate)
+		taskSpent := timeSpent(task, time.Time{}, now, now)
+		if !task.IsDone() || task.Estimate == "" || err != nil || taskSpent == 0 {
+			continue
+		}
+		if estimate.Points > 0 {
+			points += estimate.Points
+			pointsSpent += taskSpent
+			continue
+		}
+
+		deviation := (taskSpent.Minutes() - estimate.Duration.Minutes()) / estimate.Duration.Minutes() * 100
+		fmt.Printf("   [%d] %-25s estimé %6s  passé %6s  %s\n", task.ID, task.Text, formatDuration(estimate.Duration), formatDuration(taskSpent), formatDeviation(deviation))
+		estimated += estimate.Duration
+		spent += taskSpent
+		if deviation < 0 {
+			deviation = -deviation
+		}
+		errorSum += deviation
+		compared++
+	}
+
+	if compared == 0 && points == 0 {
+		fmt.Println("📝 Aucune tâche terminée avec estimation et temps suivi")
+		return nil
+	}
+	if compared > 0 {
+		total := (spent.Minutes() - estimated.Minutes()) / estimated.Minutes() * 100
+		fmt.Printf("\n   %-29s estimé %6s  passé %6s  %s\n", fmt.Sprintf("Total (%d tâches)", compared), formatDuration(estimated), formatDuration(spent), formatDeviation(total))
+		fmt.Printf("   Écart moyen par tâche : %.0f%%\n", errorSum/float64(compared))
+	}
+	if points > 0 {
+		perPoint := time.Duration(float64(pointsSpent) / points)
+		fmt.Printf("   Points : %s terminés en %s, soit %s par point\n", Estimate{Points: points}, formatDuration(pointsSpent), formatDuration(perPoint))
+	}
+	return nil
+}
+
+// formatDeviation affiche un écart : rouge au-delà de l'estimation, vert en deçà
+func formatDeviation(percent float64) string {
+	color := ColorGreen
+	if percent > 0 {
+		color = ColorRed
+	}
+	return fmt.Sprintf("%s%+.0f%%%s", color, percent, ColorReset)
+}
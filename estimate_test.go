@@ -0,0 +1,119 @@
+// estimate_test.go - Tests des estimations et de la précision
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		valid bool
+	}{
+		{"2h", "2h00", true},
+		{"45m", "45m", true},
+		{"1h30", "1h30", true},
+		{"3pt", "3pt", true},
+		{"5 points", "5pt", true},
+		{"8SP", "8pt", true},
+		{"0.5pt", "0.5pt", true},
+		{"3", "3pt", true}, // Un nombre seul compte en points
+		{"0pt", "", false},
+		{"longtemps", "", false},
+		{"-2h", "", false},
+		{"10s", "", false}, // Moins d'une minute
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := normalizeEstimate(tt.input)
+			if (err == nil) != tt.valid {
+				t.Fatalf("normalizeEstimate(%q) erreur = %v, valide attendu %v", tt.input, err, tt.valid)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeEstimate(%q) = %q, attendu %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateTotals(t *testing.T) {
+	now := time.Date(2025, 7, 16, 18, 0, 0, 0, time.Local)
+	logged := []TimeEntry{{Start: timestamp(now.Add(-90 * time.Minute)), End: timestamp(now)}}
+
+	var totals EstimateTotals
+	totals.add(Task{Status: StatusTodo, Estimate: "2h", TimeLog: logged}, now)
+	totals.add(Task{Status: StatusTodo, Estimate: "1h", TimeLog: append(logged, logged...)}, now) // Dépassée : pas de reste négatif
+	totals.add(Task{Status: StatusDone, Estimate: "30m"}, now)
+	totals.add(Task{Status: StatusTodo, Estimate: "3pt"}, now)
+	totals.add(Task{Status: StatusCancelled, Estimate: "2pt"}, now)
+	totals.add(Task{Status: StatusTodo}, now)
+
+	want := EstimateTotals{Duration: 210 * time.Minute, Remaining: 30 * time.Minute, Points: 5, RemainingPoints: 3}
+	if totals != want {
+		t.Errorf("Totaux = %+v, attendu %+v", totals, want)
+	}
+	if got := totals.String(); got != "3h30 (reste 30m) · 5pt (reste 3pt)" {
+		t.Errorf("Affichage inattendu: %q", got)
+	}
+}
+
+func TestAccuracy(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := tm.Accuracy(); err != nil {
+		t.Fatalf("Accuracy sans estimation: %v", err)
+	}
+
+	end := time.Now().Add(-time.Hour)
+	for _, task := range []Task{
+		{Text: "Refonte API", Tags: []string{"+backend"}, Estimate: "2h"},
+		{Text: "Écran login", Tags: []string{"+front"}, Estimate: "3pt"},
+		{Text: "Sans estimation"},
+	} {
+		if err := tm.AddTask(task, 0, ""); err != nil {
+			t.Fatalf("AddTask: %v", err)
+		}
+	}
+	tm.LogTime(1, 3*time.Hour, end)
+	tm.LogTime(2, 90*time.Minute, end)
+	tm.LogTime(3, time.Hour, end)
+	tm.Done(1)
+	tm.Done(2)
+	tm.Done(3)
+
+	if err := tm.Accuracy(); err != nil {
+		t.Fatalf("Accuracy: %v", err)
+	}
+	task := assertTaskExists(t, reloadManager(t, tm.filename), 1)
+	if task.Estimate != "2h" || timeSpent(*task, time.Time{}, time.Time{}, time.Now()) != 3*time.Hour {
+		t.Errorf("Estimation et temps suivi attendus après complétion: %+v", task)
+	}
+}
+
+func TestEstimate_CSVRoundTrip(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := tm.AddTask(Task{Text: "Refonte API", Estimate: "1h30"}, 0, ""); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+
+	csvFile := filepath.Join(tempDir, "export.csv")
+	if err := tm.ExportCSV(csvFile); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+
+	imported := newTestManager(filepath.Join(tempDir, "autre.json"), nil)
+	if _, err := imported.ImportCSV(csvFile, "merge", "skip", ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	assertTaskCount(t, imported, 1)
+	if got := imported.Tasks[0].Estimate; got != "1h30" {
+		t.Errorf("Estimation perdue à l'import: %q", got)
+	}
+}
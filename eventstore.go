@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types d'événements du stockage events
+const (
+	EventSnapshot      = "Snapshot"      // État complet (début de segment après compaction)
+	EventTaskAdded     = "TaskAdded"     // Nouvelle tâche
+	EventTaskEdited    = "TaskEdited"    // Texte, tags, priorité… modifiés
+	EventTaskCompleted = "TaskCompleted" // Tâche terminée
+	EventTaskReopened  = "TaskReopened"  // Tâche rouverte
+	EventTaskRemoved   = "TaskRemoved"   // Tâche placée dans la corbeille
+	EventTaskRestored  = "TaskRestored"  // Tâche sortie de la corbeille
+	EventTaskPurged    = "TaskPurged"    // Suppression définitive (UUID conservé)
+	EventTaskDeleted   = "TaskDeleted"   // Sortie du fichier (archive, undo d'un ajout)
+	EventNextIDSet     = "NextIDSet"     // Compteur d'ID modifié seul (undo)
+)
+
+// eventCompactThreshold nombre d'événements avant compaction du segment courant
+const eventCompactThreshold = 500
+
+// Event entrée du journal d'événements, identifiée par l'UUID de la tâche
+type Event struct {
+	Seq       int          `json:"seq"`
+	Time      string       `json:"time"`
+	Type      string       `json:"type"`
+	UUID      string       `json:"uuid,omitempty"`
+	Task      *Task        `json:"task,omitempty"`  // État de la tâche après l'événement
+	NextID    int          `json:"nextId"`          // Compteur après l'événement
+	Operation string       `json:"op,omitempty"`    // Commande à l'origine de l'événement
+	State     *TodoManager `json:"state,omitempty"` // Snapshot uniquement
+}
+
+// EventStore stocke les modifications sous forme d'événements en ajout seul
+// (todo.events). L'état est reconstruit au chargement ; au-delà de
+// eventCompactThreshold événements, le segment est archivé (todo.events.000042)
+// et un nouveau segment commence par un instantané de l'état.
+type EventStore struct {
+	filename string
+	seq      int          // Dernier numéro d'événement
+	count    int          // Événements du segment courant
+	last     *TodoManager // État au dernier chargement ou à la dernière sauvegarde
+	size     int64        // Taille du segment sans sa dernière ligne inachevée
+	fragment bool         // Ligne inachevée à retirer avant d'ajouter des événements
+	loadErr  error        // Segment illisible : aucune écriture tant qu'il n'est pas réparé
+}
+
+// NewEventStore crée un stockage par événements
+func NewEventStore(filename string) *EventStore {
+	return &EventStore{filename: filename}
+}
+
+// Load rejoue le segment courant. Une dernière ligne tronquée par un crash est ignorée.
+func (s *EventStore) Load(tm *TodoManager) error {
+	events, size, err := scanEvents(s.filename)
+	s.loadErr = nil
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.loadErr = err
+		}
+		return err
+	}
+	if info, err := os.Stat(s.filename); err == nil {
+		s.size, s.fragment = size, info.Size() > size
+	}
+
+	state := &TodoManager{Tasks: []Task{}, NextID: 1}
+	for _, event := range events {
+		if err := applyEvent(state, event); err != nil {
+			return fmt.Errorf("%s, événement %d : %v", s.filename, event.Seq, err)
+		}
+		s.seq = event.Seq
+	}
+	s.count = len(events)
+
+	tm.Tasks, tm.Trash, tm.Tombstones, tm.NextID = state.Tasks, state.Trash, state.Tombstones, state.NextID
+	tm.SchemaVersion = currentSchemaVersion
+	s.last = copyState(tm)
+	return nil
+}
+
+// Save ajoute les événements décrivant les modifications depuis le dernier état
+func (s *EventStore) Save(tm *TodoManager) error {
+	if s.last == nil {
+		s.last = &TodoManager{Tasks: []Task{}, NextID: 1}
+	}
+
+	events := diffEvents(s.last, tm)
+	if len(events) == 0 {
+		return nil
+	}
+	if s.loadErr != nil {
+		return fmt.Errorf("modification non enregistrée : %v", s.loadErr)
+	}
+	if s.fragment {
+		// Sans quoi le premier événement ajouté serait collé à la ligne tronquée
+		if err := os.Truncate(s.filename, s.size); err != nil {
+			return err
+		}
+		s.fragment = false
+	}
+
+	now := timestamp(time.Now())
+	var buffer bytes.Buffer
+	for i := range events {
+		s.seq++
+		events[i].Seq = s.seq
+		events[i].Time = now
+		events[i].NextID = tm.NextID
+		events[i].Operation = tm.operation
+		line, err := json.Marshal(events[i])
+		if err != nil {
+			return err
+		}
+		buffer.Write(line)
+		buffer.WriteByte('\n')
+	}
+
+	if s.count+len(events) > eventCompactThreshold {
+		if err := s.compact(tm, buffer.Bytes()); err != nil {
+			return err
+		}
+	} else {
+		if err := appendSynced(s.filename, buffer.Bytes()); err != nil {
+			return err
+		}
+		s.count += len(events)
+	}
+
+	s.last = copyState(tm)
+	return nil
+}
+
+// compact archive le segment courant (événements compris) et en commence un
+// nouveau par un instantané de l'état
+func (s *EventStore) compact(tm *TodoManager, pending []byte) error {
+	if err := appendSynced(s.filename, pending); err != nil {
+		return err
+	}
+
+	events, err := readEvents(s.filename)
+	if err != nil {
+		return err
+	}
+	first := s.seq
+	for _, event := range events {
+		if event.Type != EventSnapshot {
+			first = event.Seq
+			break
+		}
+	}
+	segment := fmt.Sprintf("%s.%06d", s.filename, first)
+	if err := backupFile(s.filename, segment); err != nil {
+		return err
+	}
+
+	snapshot, err := json.Marshal(Event{
+		Seq:    s.seq,
+		Time:   timestamp(time.Now()),
+		Type:   EventSnapshot,
+		NextID: tm.NextID,
+		State:  copyState(tm),
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.filename, append(snapshot, '\n'), dataFileMode, ""); err != nil {
+		return err
+	}
+	s.count = 1
+	return nil
+}
+
+// appendSynced ajoute des données en fin de fichier et les force sur disque
+func appendSynced(filename string, data []byte) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, dataFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readEvents lit les événements d'un segment
+func readEvents(filename string) ([]Event, error) {
+	events, _, err := scanEvents(filename)
+	return events, err
+}
+
+// scanEvents lit les événements d'un segment et retourne la taille de sa
+// partie complète. Seule une dernière ligne sans fin de ligne (écriture
+// interrompue par un crash) est ignorée : toute autre ligne illisible est
+// une corruption, signalée avec son numéro.
+func scanEvents(filename string) ([]Event, int64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var events []Event
+	var size int64
+	reader := bufio.NewReader(file)
+	for number := 1; ; number++ {
+		raw, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break // Ligne inachevée ignorée (ou fin du segment)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		size += int64(len(raw))
+
+		line := bytes.TrimSpace(raw)
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, 0, fmt.Errorf("%s, ligne %d illisible : %v", filename, number, err)
+		}
+		if event.Type == "" {
+			return nil, 0, fmt.Errorf("%s, ligne %d : événement sans type", filename, number)
+		}
+		events = append(events, event)
+	}
+	return events, size, nil
+}
+
+// copyState copie l'état persistant d'un TodoManager
+func copyState(tm *TodoManager) *TodoManager {
+	return &TodoManager{
+		SchemaVersion: currentSchemaVersion,
+		Tasks:         append([]Task{}, tm.Tasks...),
+		Trash:         append([]Task(nil), tm.Trash...),
+		Tombstones:    append([]Tombstone(nil), tm.Tombstones...),
+		NextID:        tm.NextID,
+	}
+}
+
+// diffEvents traduit les différences entre deux états en événements
+func diffEvents(before *TodoManager, after *TodoManager) []Event {
+	type located struct {
+		task    Task
+		trashed bool
+	}
+	index := func(tm *TodoManager) map[string]located {
+		byKey := make(map[string]located)
+		for _, task := range tm.Tasks {
+			byKey[taskKey(task)] = located{task, false}
+		}
+		for _, task := range tm.Trash {
+			byKey[taskKey(task)] = located{task, true}
+		}
+		return byKey
+	}
+	previous, current := index(before), index(after)
+
+	purged := make(map[string]bool)
+	for _, tombstone := range after.Tombstones {
+		purged[tombstone.UUID] = true
+	}
+	for _, tombstone := range before.Tombstones {
+		delete(purged, tombstone.UUID)
+	}
+
+	// Une suppression de pierre tombale (undo d'un vidage) ne s'exprime pas en
+	// événements de tâche : l'état complet est enregistré
+	if len(after.Tombstones) < len(before.Tombstones) {
+		return []Event{{Type: EventSnapshot, State: copyState(after)}}
+	}
+
+	var events []Event
+	event := func(kind string, task Task) {
+		events = append(events, Event{Type: kind, UUID: taskKey(task), Task: &task})
+	}
+
+	// Ordre : celui du nouvel état (tâches puis corbeille), suppressions à la fin
+	for _, list := range [][]Task{after.Tasks, after.Trash} {
+		for _, task := range list {
+			key := taskKey(task)
+			old, existed := previous[key]
+			now := current[key]
+			switch {
+			case !existed && now.trashed:
+				event(EventTaskRemoved, task)
+			case !existed:
+				event(EventTaskAdded, task)
+			case old.trashed && !now.trashed:
+				event(EventTaskRestored, task)
+			case !old.trashed && now.trashed:
+				event(EventTaskRemoved, task)
+			case sameTask(old.task, task):
+			case now.trashed:
+				event(EventTaskRemoved, task)
+			case !old.task.IsDone() && task.IsDone():
+				event(EventTaskCompleted, task)
+			case old.task.IsDone() && !task.IsDone():
+				event(EventTaskReopened, task)
+			default:
+				event(EventTaskEdited, task)
+			}
+		}
+	}
+
+	for _, list := range [][]Task{before.Tasks, before.Trash} {
+		for _, task := range list {
+			key := taskKey(task)
+			if _, kept := current[key]; kept {
+				continue
+			}
+			kind := EventTaskDeleted
+			if previous[key].trashed && purged[task.UUID] {
+				kind = EventTaskPurged
+			}
+			events = append(events, Event{Type: kind, UUID: key, Task: &Task{ID: task.ID, UUID: task.UUID, Text: task.Text, Deleted: task.Deleted}})
+		}
+	}
+
+	if len(events) == 0 && before.NextID != after.NextID {
+		events = append(events, Event{Type: EventNextIDSet})
+	}
+	return events
+}
+
+// applyEvent applique un événement à un état
+func applyEvent(state *TodoManager, event Event) error {
+	if event.Type == EventSnapshot {
+		if event.State == nil {
+			return fmt.Errorf("instantané sans état")
+		}
+		*state = *copyState(event.State)
+		state.NextID = event.NextID
+		return nil
+	}
+
+	if event.NextID > 0 {
+		state.NextID = event.NextID
+	}
+	if event.Type == EventNextIDSet {
+		return nil
+	}
+	if event.Task == nil {
+		return fmt.Errorf("%s sans tâche", event.Type)
+	}
+
+	key := event.UUID
+	remove := func(tasks []Task) ([]Task, int) {
+		for i, task := range tasks {
+			if taskKey(task) == key {
+				return append(tasks[:i:i], tasks[i+1:]...), i
+			}
+		}
+		return tasks, -1
+	}
+	upsert := func(tasks []Task, task Task) []Task {
+		for i := range tasks {
+			if taskKey(tasks[i]) == key {
+				tasks[i] = task
+				return tasks
+			}
+		}
+		return append(tasks, task)
+	}
+
+	switch event.Type {
+	case EventTaskAdded, EventTaskRestored:
+		state.Trash, _ = remove(state.Trash)
+		state.Tasks = upsert(state.Tasks, *event.Task)
+	case EventTaskEdited, EventTaskCompleted, EventTaskReopened:
+		state.Tasks = upsert(state.Tasks, *event.Task)
+	case EventTaskRemoved:
+		state.Tasks, _ = remove(state.Tasks)
+		state.Trash = upsert(state.Trash, *event.Task)
+	case EventTaskPurged:
+		state.Trash, _ = remove(state.Trash)
+		state.Tombstones = append(state.Tombstones, Tombstone{UUID: event.Task.UUID, Deleted: event.Task.Deleted})
+	case EventTaskDeleted:
+		state.Tasks, _ = remove(state.Tasks)
+		state.Trash, _ = remove(state.Trash)
+	default:
+		return fmt.Errorf("type d'événement inconnu '%s'", event.Type)
+	}
+	return nil
+}
+
+// auditEvents lit tous les événements : segments archivés puis segment courant
+func (s *EventStore) auditEvents() ([]Event, error) {
+	var all []Event
+	seen := make(map[int]bool)
+	for _, filename := range append(eventSegments(s.filename), s.filename) {
+		events, err := readEvents(filename)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, event := range events {
+			if event.Type == EventSnapshot || seen[event.Seq] {
+				continue
+			}
+			seen[event.Seq] = true
+			all = append(all, event)
+		}
+	}
+	return all, nil
+}
+
+// Audit affiche l'historique complet des événements, ou celui d'une tâche
+// désignée par son ID ou le début de son UUID
+func (tm *TodoManager) Audit(ref string) error {
+	store, ok := tm.storage().(*EventStore)
+	if !ok {
+		return fmt.Errorf("todo audit nécessite le stockage events (--store=events)")
+	}
+
+	events, err := store.auditEvents()
+	if err != nil {
+		return err
+	}
+
+	key := ""
+	if ref != "" {
+		if key, err = tm.resolveTaskKey(ref, events); err != nil {
+			return err
+		}
+	}
+
+	var shown int
+	for _, event := range events {
+		if key != "" && event.UUID != key {
+			continue
+		}
+		text := ""
+		if event.Task != nil {
+			text = fmt.Sprintf("[%d] %s", event.Task.ID, event.Task.Text)
+		}
+		operation := ""
+		if event.Operation != "" {
+			operation = fmt.Sprintf(" %s(%s)%s", ColorGray, event.Operation, ColorReset)
+		}
+		fmt.Printf("%s#%d %s%s  %-14s %s%s\n", ColorGray, event.Seq, formatTimestamp(event.Time), ColorReset, event.Type, text, operation)
+		shown++
+	}
+
+	if shown == 0 {
+		fmt.Println("📝 Aucun événement")
+	}
+	return nil
+}
+
+// resolveTaskKey retrouve la clé d'une tâche par ID (tâches et corbeille)
+// ou par début d'UUID (y compris les tâches disparues du journal)
+func (tm *TodoManager) resolveTaskKey(ref string, events []Event) (string, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		for _, task := range append(append([]Task(nil), tm.Tasks...), tm.Trash...) {
+			if task.ID == id {
+				return taskKey(task), nil
+			}
+		}
+		return "", fmt.Errorf("tâche %d introuvable", id)
+	}
+
+	matches := make(map[string]bool)
+	for _, event := range events {
+		if len(ref) >= 4 && strings.HasPrefix(event.UUID, strings.ToLower(ref)) {
+			matches[event.UUID] = true
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("aucun événement pour '%s'", ref)
+	case 1:
+		for key := range matches {
+			return key, nil
+		}
+	}
+	return "", fmt.Errorf("plusieurs tâches commencent par '%s', précisez l'UUID", ref)
+}
+
+// eventSegments liste les segments archivés d'un journal d'événements
+func eventSegments(filename string) []string {
+	entries, err := ioutil.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		return nil
+	}
+	var segments []string
+	prefix := filepath.Base(filename) + "."
+	for _, entry := range entries {
+		if suffix := strings.TrimPrefix(entry.Name(), prefix); suffix != entry.Name() {
+			if _, err := strconv.Atoi(suffix); err == nil {
+				segments = append(segments, filepath.Join(filepath.Dir(filename), entry.Name()))
+			}
+		}
+	}
+	sort.Strings(segments)
+	return segments
+}
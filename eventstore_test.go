@@ -0,0 +1,171 @@
+// eventstore_test.go - Tests du stockage par événements
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// eventTypes retourne les types d'événements d'un segment
+func eventTypes(t *testing.T, filename string) []string {
+	t.Helper()
+	events, err := readEvents(filename)
+	if err != nil {
+		t.Fatalf("Lecture de %s: %v", filename, err)
+	}
+	var types []string
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestEventStore_Replay(t *testing.T) {
+	tempDir := t.TempDir()
+	filename := filepath.Join(tempDir, "todo.json")
+	eventsFile := filepath.Join(tempDir, "todo.events")
+
+	tm := newTestManager(filename, NewEventStore(eventsFile))
+	tm.Add("Préparer CV", []string{"+job"}, "high", "")
+	tm.Add("Relire contrat", nil, "", "")
+	tm.Add("Tâche éphémère", nil, "", "")
+	tm.Done(1)
+	tm.Edit(2, "Relire contrat (v2)", nil)
+	tm.Remove(3)
+	tm.Remove(2)
+	if err := tm.Restore("2"); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	tm.Remove(3)
+	tm.EmptyTrash(0)
+
+	want := []string{
+		EventTaskAdded, EventTaskAdded, EventTaskAdded, EventTaskCompleted, EventTaskEdited,
+		EventTaskRemoved, EventTaskRemoved, EventTaskRestored, EventTaskPurged,
+	}
+	if got := eventTypes(t, eventsFile); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Événements inattendus:\n  obtenu  %v\n  attendu %v", got, want)
+	}
+
+	reloaded := newTestManager(filename, NewEventStore(eventsFile))
+	if err := reloaded.load(); err != nil {
+		t.Fatalf("Rechargement: %v", err)
+	}
+	assertTaskCount(t, reloaded, 2)
+	if task := assertTaskExists(t, reloaded, 1); !task.IsDone() || task.Priority != "high" {
+		t.Errorf("Tâche 1 terminée et prioritaire attendue: %+v", task)
+	}
+	if task := assertTaskExists(t, reloaded, 2); task.Text != "Relire contrat (v2)" {
+		t.Errorf("Texte modifié attendu: %q", task.Text)
+	}
+	if len(reloaded.Trash) != 0 || len(reloaded.Tombstones) != 1 || reloaded.NextID != 4 {
+		t.Errorf("Corbeille vide, 1 pierre tombale et NextID 4 attendus: %+v", reloaded)
+	}
+
+	t.Run("undo", func(t *testing.T) {
+		if err := reloaded.Undo(1); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+		again := newTestManager(filename, NewEventStore(eventsFile))
+		if err := again.load(); err != nil {
+			t.Fatalf("Rechargement: %v", err)
+		}
+		if len(again.Trash) != 1 {
+			t.Errorf("Le vidage de la corbeille devrait être annulé: %+v", again.Trash)
+		}
+	})
+
+	t.Run("ligne tronquée", func(t *testing.T) {
+		file, err := os.OpenFile(eventsFile, os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.WriteString(`{"seq":99,"type":"TaskAdd`)
+		file.Close()
+
+		store := NewEventStore(eventsFile)
+		crashed := newTestManager(filename, store)
+		if err := crashed.load(); err != nil {
+			t.Fatalf("Une ligne tronquée doit être ignorée: %v", err)
+		}
+		assertTaskCount(t, crashed, 2)
+
+		// La ligne tronquée est retirée avant l'ajout suivant
+		crashed.Add("Après le crash", nil, "", "")
+		again := newTestManager(filename, NewEventStore(eventsFile))
+		if err := again.load(); err != nil {
+			t.Fatalf("Rechargement après ajout: %v", err)
+		}
+		assertTaskCount(t, again, 3)
+	})
+
+	t.Run("ligne illisible au milieu", func(t *testing.T) {
+		data, err := ioutil.ReadFile(eventsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.SplitAfter(string(data), "\n")
+		lines[2] = "{pas du json}\n"
+		corrupted := filepath.Join(tempDir, "corrompu.events")
+		if err := ioutil.WriteFile(corrupted, []byte(strings.Join(lines, "")), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		tm := newTestManager(filename, NewEventStore(corrupted))
+		if err := tm.load(); err == nil || !strings.Contains(err.Error(), "ligne 3") {
+			t.Fatalf("Erreur désignant la ligne 3 attendue, obtenu %v", err)
+		}
+		if err := tm.storage().Save(&TodoManager{Tasks: []Task{{ID: 1, UUID: "x", Text: "Nouvelle"}}, NextID: 2}); err == nil {
+			t.Error("Un segment illisible ne doit pas recevoir d'événements")
+		}
+		if after, _ := ioutil.ReadFile(corrupted); string(after) != strings.Join(lines, "") {
+			t.Error("Le segment illisible ne doit pas être modifié")
+		}
+	})
+}
+
+func TestEventStore_Compaction(t *testing.T) {
+	tempDir := t.TempDir()
+	filename := filepath.Join(tempDir, "todo.json")
+	eventsFile := filepath.Join(tempDir, "todo.events")
+
+	store := NewEventStore(eventsFile)
+	tm := newTestManager(filename, store)
+	for i := 0; i < eventCompactThreshold+5; i++ {
+		tm.Add("Tâche", nil, "", "")
+	}
+	tm.Done(1)
+
+	segments := eventSegments(eventsFile)
+	if len(segments) != 1 || filepath.Base(segments[0]) != "todo.events.000001" {
+		t.Fatalf("Un segment archivé attendu, obtenu %v", segments)
+	}
+	if types := eventTypes(t, eventsFile); len(types) > 10 || types[0] != EventSnapshot {
+		t.Errorf("Le segment courant doit commencer par un instantané: %v", types)
+	}
+
+	reloaded := newTestManager(filename, NewEventStore(eventsFile))
+	if err := reloaded.load(); err != nil {
+		t.Fatalf("Rechargement: %v", err)
+	}
+	assertTaskCount(t, reloaded, eventCompactThreshold+5)
+	if !reloaded.Tasks[0].IsDone() {
+		t.Error("L'événement postérieur à l'instantané doit être rejoué")
+	}
+
+	events, err := store.auditEvents()
+	if err != nil {
+		t.Fatalf("auditEvents: %v", err)
+	}
+	if len(events) != eventCompactThreshold+6 {
+		t.Errorf("%d événements attendus dans l'audit, obtenu %d", eventCompactThreshold+6, len(events))
+	}
+	for i, event := range events {
+		if event.Seq != i+1 {
+			t.Fatalf("Événement %d hors séquence: %+v", i, event)
+		}
+	}
+}
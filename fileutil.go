@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dataFileMode droits des fichiers contenant des tâches : lisibles par l'utilisateur seul
+const dataFileMode os.FileMode = 0600
+
+// writeFileAtomic écrit un fichier sans jamais laisser de version tronquée :
+// fichier temporaire dans le même répertoire, fsync, puis renommage atomique.
+// Si backup n'est pas vide, la version précédente y est conservée.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode, backup string) error {
+	dir := filepath.Dir(filename)
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // Sans effet après le renommage
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if perm != 0600 { // TempFile crée déjà le fichier en 0600
+		if err := os.Chmod(tmpName, perm); err != nil {
+			return err
+		}
+	}
+
+	if backup != "" {
+		if err := backupFile(filename, backup); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// replaceFile remplace un fichier annexe (historique…) par renommage, sans fsync :
+// jamais de version tronquée, mais une coupure de courant peut perdre la dernière
+// écriture. Réservé aux fichiers dont la perte n'affecte pas les tâches.
+func replaceFile(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if perm != 0600 { // TempFile crée déjà le fichier en 0600
+		if err := os.Chmod(tmpName, perm); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmpName, filename)
+}
+
+// backupFile conserve filename sous le nom backup (lien physique ou copie).
+// Le fichier d'origine reste en place : aucun instant sans fichier principal.
+func backupFile(filename string, backup string) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil // Première sauvegarde, rien à conserver
+	}
+
+	if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(filename, backup); err == nil {
+		return nil
+	}
+	return copyFile(filename, backup)
+}
+
+// copyFile copie un fichier et force son écriture sur disque
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// syncDir force l'écriture du répertoire (entrées renommées).
+// Ignoré là où ce n'est pas supporté (Windows).
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// quarantineName retourne un nom de quarantaine horodaté encore libre
+func quarantineName(filename string) string {
+	base := filename + ".corrupt-" + time.Now().Format("20060102-150405")
+	name := base
+	for i := 1; ; i++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// quarantineFile déplace un fichier corrompu à côté de l'original
+func quarantineFile(filename string) (string, error) {
+	quarantine := quarantineName(filename)
+	if err := os.Rename(filename, quarantine); err != nil {
+		return "", err
+	}
+	return quarantine, nil
+}
+
+// sidecarDir retourne le répertoire annexe kind (listes, archives…) d'un fichier de tâches.
+// todo.json → <dir>/kind, .todo.json (projet) → <dir>/.todo/kind, work.json → <dir>/kind/work
+func sidecarDir(filename string, kind string) string {
+	dir := filepath.Dir(filename)
+	stem := strings.TrimSuffix(filepath.Base(filename), ".json")
+
+	switch stem {
+	case "todo":
+		return filepath.Join(dir, kind)
+	case ".todo":
+		return filepath.Join(dir, ".todo", kind)
+	default:
+		return filepath.Join(dir, kind, stem)
+	}
+}
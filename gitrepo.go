@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitBranch branche des données et gitRemote nom du dépôt distant
+const (
+	gitBranch = "main"
+	gitRemote = "origin"
+)
+
+// GitRepo répertoire de données versionné par git (todo git init) :
+// chaque sauvegarde y crée un commit décrivant l'opération
+type GitRepo struct {
+	dir string
+}
+
+// findDataRepo retourne le dépôt git du fichier de tâches s'il a été créé par
+// todo git init. Le dépôt d'un projet qui contient une liste .todo n'est
+// jamais utilisé : il n'a pas de section [todo] dans sa configuration.
+func findDataRepo(filename string) *GitRepo {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil
+	}
+
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".git")); err == nil && info.IsDir() {
+			config, err := ioutil.ReadFile(filepath.Join(dir, ".git", "config"))
+			if err != nil || !bytes.Contains(config, []byte("[todo]")) {
+				return nil
+			}
+			return &GitRepo{dir: dir}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// run exécute une commande git dans le dépôt et retourne sa sortie
+func (r *GitRepo) run(args ...string) (string, error) {
+	output, err := r.output(args...)
+	return strings.TrimSpace(string(output)), err
+}
+
+// output exécute une commande git et retourne sa sortie brute
+func (r *GitRepo) output(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, fmt.Errorf("git %s : %s", args[0], message)
+	}
+	return stdout.Bytes(), nil
+}
+
+// InitGitRepo fait du répertoire de données un dépôt git, avec un dépôt distant
+// facultatif (ex: un dépôt nu sur un disque partagé)
+func InitGitRepo(dir string, remote string) (*GitRepo, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	repo := &GitRepo{dir: dir}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := repo.run("init", "-q"); err != nil {
+			return nil, err
+		}
+		if _, err := repo.run("symbolic-ref", "HEAD", "refs/heads/"+gitBranch); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := repo.run("config", "todo.data", "true"); err != nil {
+		return nil, err
+	}
+
+	// Identité minimale si l'utilisateur n'en a configuré aucune
+	if email, _ := repo.run("config", "user.email"); email == "" {
+		hostname, _ := os.Hostname()
+		repo.run("config", "user.name", "todo")
+		repo.run("config", "user.email", "todo@"+hostname)
+	}
+
+	gitignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(gitignore); os.IsNotExist(err) {
+		if err := ioutil.WriteFile(gitignore, []byte(projectGitignore), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	if remote != "" {
+		if _, err := repo.run("remote", "get-url", gitRemote); err == nil {
+			_, err = repo.run("remote", "set-url", gitRemote, remote)
+			if err != nil {
+				return nil, err
+			}
+		} else if _, err := repo.run("remote", "add", gitRemote, remote); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := repo.commit("todo git init"); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// commit enregistre toutes les modifications du répertoire de données
+func (r *GitRepo) commit(message string) error {
+	if _, err := r.run("add", "-A"); err != nil {
+		return err
+	}
+	if status, err := r.run("status", "--porcelain"); err != nil || status == "" {
+		return err // Rien à enregistrer
+	}
+	_, err := r.run("commit", "-q", "--no-verify", "-m", message)
+	return err
+}
+
+// commitToRepo commite l'opération si le répertoire de données est un dépôt git.
+// Un échec n'annule pas la sauvegarde : il est seulement signalé.
+func (tm *TodoManager) commitToRepo(operation string) {
+	if tm.repo == nil {
+		return
+	}
+	if operation == "" {
+		operation = "modification"
+	}
+	if err := tm.repo.commit(operation); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Commit git non créé : %v\n", err)
+	}
+}
+
+// Push envoie les commits vers le dépôt distant
+func (r *GitRepo) Push() error {
+	if _, err := r.run("push", "-q", gitRemote, gitBranch); err != nil {
+		return fmt.Errorf("%v\n   Lancez 'todo git pull' puis réessayez", err)
+	}
+	fmt.Printf("⬆️  Tâches envoyées vers %s\n", gitRemote)
+	return nil
+}
+
+// Pull récupère les commits distants. Si les deux côtés ont changé, les
+// fichiers de tâches sont fusionnés tâche par tâche (UUID) au lieu de ligne à ligne.
+func (r *GitRepo) Pull() error {
+	if err := r.commit("modifications locales"); err != nil {
+		return err
+	}
+	if _, err := r.run("fetch", "-q", gitRemote); err != nil {
+		return err
+	}
+
+	remoteRef := gitRemote + "/" + gitBranch
+	theirs, err := r.run("rev-parse", "--verify", "-q", remoteRef)
+	if err != nil || theirs == "" {
+		fmt.Println("📝 Rien à récupérer : le dépôt distant est vide")
+		return nil
+	}
+	ours, err := r.run("rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	base, _ := r.run("merge-base", ours, theirs) // Vide : historiques indépendants
+
+	switch base {
+	case theirs:
+		fmt.Println("✅ Déjà à jour")
+		return nil
+	case ours:
+		if _, err := r.run("merge", "-q", "--ff-only", remoteRef); err != nil {
+			return err
+		}
+		fmt.Printf("⬇️  Tâches récupérées depuis %s\n", gitRemote)
+		return nil
+	}
+
+	// Fusion : on garde notre arbre puis on fusionne chaque fichier modifié à distance
+	mergeArgs := []string{"merge", "-q", "--no-ff", "--no-commit", "-s", "ours"}
+	if base == "" {
+		mergeArgs = append(mergeArgs, "--allow-unrelated-histories")
+	}
+	if _, err := r.run(append(mergeArgs, remoteRef)...); err != nil {
+		return err
+	}
+
+	paths, err := r.changedPaths(base, theirs)
+	if err != nil {
+		r.run("merge", "--abort")
+		return err
+	}
+
+	var conflicts int
+	for _, path := range paths {
+		n, err := r.mergePath(path, base, ours, theirs)
+		if err != nil {
+			r.run("merge", "--abort")
+			return fmt.Errorf("fusion de %s : %v", path, err)
+		}
+		conflicts += n
+	}
+
+	if _, err := r.run("add", "-A"); err != nil {
+		return err
+	}
+	if _, err := r.run("commit", "-q", "--no-verify", "-m", "merge "+remoteRef); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔀 Tâches fusionnées avec %s (%d fichier(s))\n", gitRemote, len(paths))
+	if conflicts > 0 {
+		fmt.Printf("⚠️  %d tâche(s) modifiée(s) des deux côtés : la version la plus récente est conservée\n", conflicts)
+	}
+	return nil
+}
+
+// changedPaths fichiers modifiés à distance depuis l'ancêtre commun (tous sans ancêtre)
+func (r *GitRepo) changedPaths(base string, theirs string) ([]string, error) {
+	var output string
+	var err error
+	if base == "" {
+		output, err = r.run("ls-tree", "-r", "-z", "--name-only", theirs)
+	} else {
+		output, err = r.run("diff", "-z", "--name-only", base, theirs)
+	}
+	output = strings.Trim(output, "\x00")
+	if err != nil || output == "" {
+		return nil, err
+	}
+	return strings.Split(output, "\x00"), nil
+}
+
+// show retourne le contenu d'un fichier à une révision (nil s'il n'y existe pas)
+func (r *GitRepo) show(rev string, path string) []byte {
+	if rev == "" {
+		return nil
+	}
+	output, err := r.output("show", rev+":"+path)
+	if err != nil {
+		return nil
+	}
+	return output
+}
+
+// mergePath fusionne un fichier modifié à distance et retourne le nombre de conflits
+func (r *GitRepo) mergePath(path string, base string, ours string, theirs string) (int, error) {
+	baseData, ourData, theirData := r.show(base, path), r.show(ours, path), r.show(theirs, path)
+	target := filepath.Join(r.dir, filepath.FromSlash(path))
+
+	switch {
+	case bytes.Equal(ourData, baseData):
+		// Inchangé chez nous : la version distante s'applique telle quelle
+		if theirData == nil {
+			return 0, os.Remove(target)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return 0, err
+		}
+		return 0, writeFileAtomic(target, theirData, dataFileMode, "")
+	case ourData == nil:
+		// Supprimé chez nous mais modifié à distance : la version modifiée l'emporte
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return 0, err
+		}
+		return 0, writeFileAtomic(target, theirData, dataFileMode, "")
+	case theirData == nil || bytes.Equal(ourData, theirData):
+		return 0, nil // Supprimé à distance mais modifié chez nous : on garde
+	case filepath.Ext(path) != ".json":
+		fmt.Printf("⚠️  %s modifié des deux côtés, version locale conservée\n", path)
+		return 0, nil
+	}
+
+	merged, conflicts, err := mergeTaskFile(baseData, ourData, theirData)
+	if err != nil {
+		return 0, err
+	}
+	return conflicts, writeFileAtomic(target, merged, dataFileMode, "")
+}
+
+// mergeTaskFile fusionne trois versions d'un fichier de tâches ou d'une archive.
+// Le résultat est chiffré comme notre version.
+func mergeTaskFile(baseData []byte, ourData []byte, theirData []byte) ([]byte, int, error) {
+	ourData, c, err := openData(ourData)
+	if err != nil {
+		return nil, 0, err
+	}
+	if theirData, _, err = openData(theirData); err != nil {
+		return nil, 0, err
+	}
+	if baseData != nil {
+		if baseData, _, err = openData(baseData); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var probe struct {
+		Month *string `json:"month"`
+	}
+	json.Unmarshal(ourData, &probe)
+
+	var merged []byte
+	var conflicts int
+	if probe.Month != nil {
+		merged, err = mergeArchives(ourData, theirData)
+	} else {
+		merged, conflicts, err = mergeTodoDocuments(baseData, ourData, theirData)
+	}
+	if err == nil {
+		merged, err = sealData(merged, c)
+	}
+	return merged, conflicts, err
+}
+
+// mergeArchives réunit deux versions d'une archive mensuelle
+func mergeArchives(ourData []byte, theirData []byte) ([]byte, error) {
+	var ours, theirs Archive
+	if err := json.Unmarshal(ourData, &ours); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(theirData, &theirs); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, task := range ours.Tasks {
+		known[taskKey(task)] = true
+	}
+	for _, task := range theirs.Tasks {
+		if !known[taskKey(task)] {
+			ours.Tasks = append(ours.Tasks, task)
+		}
+	}
+	return json.MarshalIndent(ours, "", "  ")
+}
+
+// mergeTodoDocuments fusionne trois versions d'un fichier de tâches
+func mergeTodoDocuments(baseData []byte, ourData []byte, theirData []byte) ([]byte, int, error) {
+	base, ours, theirs := &TodoManager{}, &TodoManager{}, &TodoManager{}
+	if baseData != nil {
+		if err := decodeTodoData(baseData, base); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := decodeTodoData(ourData, ours); err != nil {
+		return nil, 0, err
+	}
+	if err := decodeTodoData(theirData, theirs); err != nil {
+		return nil, 0, err
+	}
+
+	merged := mergeTodoManagers(base, ours, theirs)
+	data, err := encodeTodoData(merged.result)
+	return data, merged.conflicts, err
+}
+
+// todoMerge résultat d'une fusion
+type todoMerge struct {
+	result    *TodoManager
+	conflicts int
+}
+
+// mergeTodoManagers fusionne tâches, corbeille et suppressions définitives
+func mergeTodoManagers(base *TodoManager, ours *TodoManager, theirs *TodoManager) todoMerge {
+	tasks, taskConflicts := mergeTasks(base.Tasks, ours.Tasks, theirs.Tasks)
+	trash, trashConflicts := mergeTasks(base.Trash, ours.Trash, theirs.Trash)
+
+	result := &TodoManager{Tasks: []Task{}, NextID: ours.NextID}
+	if theirs.NextID > result.NextID {
+		result.NextID = theirs.NextID
+	}
+
+	tombstones := make(map[string]bool)
+	for _, tombstone := range append(append([]Tombstone(nil), ours.Tombstones...), theirs.Tombstones...) {
+		if !tombstones[tombstone.UUID] {
+			tombstones[tombstone.UUID] = true
+			result.Tombstones = append(result.Tombstones, tombstone)
+		}
+	}
+
+	// Une tâche supprimée d'un côté et modifiée de l'autre reste dans la liste
+	live := make(map[string]bool)
+	usedIDs := make(map[int]bool)
+	for _, task := range tasks {
+		if task.UUID != "" && tombstones[task.UUID] {
+			continue
+		}
+		live[taskKey(task)] = true
+		result.Tasks = append(result.Tasks, task)
+		usedIDs[task.ID] = true
+	}
+	for _, task := range trash {
+		if !live[taskKey(task)] && !(task.UUID != "" && tombstones[task.UUID]) {
+			result.Trash = append(result.Trash, task)
+		}
+	}
+
+	// Tâches créées des deux côtés avec le même ID : les distantes sont renumérotées
+	for _, task := range result.Tasks {
+		if task.ID >= result.NextID {
+			result.NextID = task.ID + 1
+		}
+	}
+	seen := make(map[int]bool)
+	for i := range result.Tasks {
+		if seen[result.Tasks[i].ID] {
+			result.Tasks[i].ID = result.NextID
+			result.NextID++
+		}
+		seen[result.Tasks[i].ID] = true
+	}
+
+	return todoMerge{result: result, conflicts: taskConflicts + trashConflicts}
+}
+
+// mergeTasks fusion à trois voies d'une liste de tâches, par UUID. Une tâche
+// modifiée des deux côtés garde la version mise à jour le plus récemment.
+func mergeTasks(base []Task, ours []Task, theirs []Task) ([]Task, int) {
+	index := func(tasks []Task) map[string]Task {
+		byKey := make(map[string]Task, len(tasks))
+		for _, task := range tasks {
+			byKey[taskKey(task)] = task
+		}
+		return byKey
+	}
+	baseByKey, ourByKey, theirByKey := index(base), index(ours), index(theirs)
+
+	// Ordre : nos tâches, puis les nouvelles tâches distantes
+	var keys []string
+	for _, task := range ours {
+		keys = append(keys, taskKey(task))
+	}
+	var added []string
+	for _, task := range theirs {
+		if _, found := ourByKey[taskKey(task)]; !found {
+			added = append(added, taskKey(task))
+		}
+	}
+	keys = append(keys, added...)
+
+	var merged []Task
+	var conflicts int
+	for _, key := range keys {
+		b, inBase := baseByKey[key]
+		o, inOurs := ourByKey[key]
+		t, inTheirs := theirByKey[key]
+
+		switch {
+		case inOurs && inTheirs && sameTask(o, t):
+			merged = append(merged, o)
+		case !inTheirs:
+			// Absente à distance : supprimée là-bas si elle existait, sauf modification locale
+			if !inBase || !sameTask(o, b) {
+				merged = append(merged, o)
+			}
+		case !inOurs:
+			if !inBase || !sameTask(t, b) {
+				merged = append(merged, t)
+			}
+		case inBase && sameTask(o, b):
+			merged = append(merged, t)
+		case inBase && sameTask(t, b):
+			merged = append(merged, o)
+		default:
+			conflicts++
+			if (&TodoManager{}).isNewer(t.Updated, o.Updated) {
+				merged = append(merged, t)
+			} else {
+				merged = append(merged, o)
+			}
+		}
+	}
+
+	return merged, conflicts
+}
+
+// PrintGitLog affiche les derniers commits du répertoire de données
+func (r *GitRepo) PrintGitLog(limit int) error {
+	output, err := r.run("log", fmt.Sprintf("-%d", limit), "--format=%h %ad %s", "--date=format:%Y-%m-%d %H:%M")
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Println("📝 Aucun commit")
+		return nil
+	}
+	fmt.Println(output)
+	return nil
+}
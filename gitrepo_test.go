@@ -0,0 +1,178 @@
+// gitrepo_test.go - Tests du répertoire de données versionné par git
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeTasks(t *testing.T) {
+	base := []Task{
+		{ID: 1, UUID: "a", Text: "Inchangée"},
+		{ID: 2, UUID: "b", Text: "Modifiée chez nous"},
+		{ID: 3, UUID: "c", Text: "Modifiée à distance"},
+		{ID: 4, UUID: "d", Text: "Supprimée à distance"},
+		{ID: 5, UUID: "e", Text: "Supprimée à distance, modifiée chez nous"},
+		{ID: 6, UUID: "f", Text: "Conflit", Updated: "2025-07-01 10:00:00"},
+	}
+	ours := []Task{
+		{ID: 1, UUID: "a", Text: "Inchangée"},
+		{ID: 2, UUID: "b", Text: "Modifiée chez nous (v2)"},
+		{ID: 3, UUID: "c", Text: "Modifiée à distance"},
+		{ID: 4, UUID: "d", Text: "Supprimée à distance"},
+		{ID: 5, UUID: "e", Text: "Supprimée à distance, modifiée chez nous", Status: StatusDone},
+		{ID: 6, UUID: "f", Text: "Conflit (local)", Updated: "2025-07-02 10:00:00"},
+		{ID: 7, UUID: "g", Text: "Ajoutée chez nous"},
+	}
+	theirs := []Task{
+		{ID: 1, UUID: "a", Text: "Inchangée"},
+		{ID: 2, UUID: "b", Text: "Modifiée chez nous"},
+		{ID: 3, UUID: "c", Text: "Modifiée à distance (v2)"},
+		{ID: 6, UUID: "f", Text: "Conflit (distant)", Updated: "2025-07-03 10:00:00"},
+		{ID: 7, UUID: "h", Text: "Ajoutée à distance"},
+	}
+
+	merged, conflicts := mergeTasks(base, ours, theirs)
+	if conflicts != 1 {
+		t.Errorf("1 conflit attendu, obtenu %d", conflicts)
+	}
+
+	var texts []string
+	for _, task := range merged {
+		texts = append(texts, task.Text)
+	}
+	want := []string{
+		"Inchangée",
+		"Modifiée chez nous (v2)",
+		"Modifiée à distance (v2)",
+		"Supprimée à distance, modifiée chez nous",
+		"Conflit (distant)",
+		"Ajoutée chez nous",
+		"Ajoutée à distance",
+	}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("Fusion inattendue:\n  obtenu  %v\n  attendu %v", texts, want)
+	}
+
+	result := mergeTodoManagers(&TodoManager{Tasks: base, NextID: 7},
+		&TodoManager{Tasks: ours, NextID: 8}, &TodoManager{Tasks: theirs, NextID: 8}).result
+	ids := make(map[int]bool)
+	for _, task := range result.Tasks {
+		if ids[task.ID] {
+			t.Errorf("ID %d en double après fusion", task.ID)
+		}
+		ids[task.ID] = true
+	}
+	if result.NextID != 9 {
+		t.Errorf("NextID 9 attendu après renumérotation, obtenu %d", result.NextID)
+	}
+}
+
+func TestGitRepo_Sync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git non disponible")
+	}
+
+	tempDir := t.TempDir()
+	remote := filepath.Join(tempDir, "remote.git")
+	if output, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v %s", err, output)
+	}
+
+	// Poste A : crée les tâches et les envoie
+	dirA := filepath.Join(tempDir, "a")
+	repoA, err := InitGitRepo(dirA, remote)
+	if err != nil {
+		t.Fatalf("InitGitRepo: %v", err)
+	}
+	fileA := filepath.Join(dirA, "todo.json")
+	tmA := NewTodoManagerWithStore(fileA, nil)
+	if tmA.repo == nil {
+		t.Fatal("Le dépôt de données devrait être détecté")
+	}
+	tmA.Add("Préparer CV", nil, "", "")
+	tmA.Add("Relire contrat", nil, "", "")
+	tmA.Done(1)
+
+	if log, _ := repoA.run("log", "-1", "--format=%s"); log != "done [1] Préparer CV" {
+		t.Errorf("Message de commit inattendu: %q", log)
+	}
+	if err := repoA.Push(); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	// Poste B : récupère les tâches
+	dirB := filepath.Join(tempDir, "b")
+	repoB, err := InitGitRepo(dirB, remote)
+	if err != nil {
+		t.Fatalf("InitGitRepo: %v", err)
+	}
+	if err := repoB.Pull(); err != nil {
+		t.Fatalf("Premier pull: %v", err)
+	}
+	fileB := filepath.Join(dirB, "todo.json")
+	assertTaskCount(t, reloadManager(t, fileB), 2)
+
+	// Modifications concurrentes
+	tmA = NewTodoManagerWithStore(fileA, nil)
+	tmA.Edit(2, "Relire contrat (v2)", nil)
+	tmA.Add("Tâche du poste A", nil, "", "")
+	if err := repoA.Push(); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	tmB := NewTodoManagerWithStore(fileB, nil)
+	tmB.Remove(1)
+	tmB.Add("Tâche du poste B", nil, "", "")
+	if err := repoB.Push(); err == nil {
+		t.Error("Le push devrait être refusé tant que le poste B n'a pas récupéré A")
+	}
+
+	if err := repoB.Pull(); err != nil {
+		t.Fatalf("Pull avec fusion: %v", err)
+	}
+	tmB = reloadManager(t, fileB)
+	assertTaskCount(t, tmB, 3)
+	ids := make(map[int]bool)
+	texts := make(map[string]bool)
+	for _, task := range tmB.Tasks {
+		ids[task.ID] = true
+		texts[task.Text] = true
+	}
+	for _, text := range []string{"Relire contrat (v2)", "Tâche du poste A", "Tâche du poste B"} {
+		if !texts[text] {
+			t.Errorf("Tâche %q absente après fusion: %+v", text, tmB.Tasks)
+		}
+	}
+	if len(ids) != 3 {
+		t.Errorf("IDs en double après fusion: %+v", tmB.Tasks)
+	}
+	if len(tmB.Trash) != 1 {
+		t.Errorf("La tâche supprimée sur B devrait rester dans la corbeille: %+v", tmB.Trash)
+	}
+
+	// Retour vers A : avance rapide
+	if err := repoB.Push(); err != nil {
+		t.Fatalf("Push après fusion: %v", err)
+	}
+	if err := repoA.Pull(); err != nil {
+		t.Fatalf("Pull sur A: %v", err)
+	}
+	assertTaskCount(t, reloadManager(t, fileA), 3)
+}
+
+func TestFindDataRepo_IgnoresProjectRepos(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git non disponible")
+	}
+
+	project := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", project).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v %s", err, output)
+	}
+	if repo := findDataRepo(filepath.Join(project, ".todo", "todo.json")); repo != nil {
+		t.Errorf("Le dépôt d'un projet ne doit pas recevoir de commits: %+v", repo)
+	}
+}
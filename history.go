@@ -0,0 +1,547 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultHistorySize nombre d'opérations conservées dans le journal
+const defaultHistorySize = 100
+
+// TaskChange modification d'une tâche : Before nil pour un ajout, After nil
+// pour une suppression. Index est la position de la tâche avant modification.
+type TaskChange struct {
+	Section string `json:"section"`
+	Index   int    `json:"index"`
+	Before  *Task  `json:"before,omitempty"`
+	After   *Task  `json:"after,omitempty"`
+}
+
+// HistoryEntry opération enregistrée, annulable et rejouable
+type HistoryEntry struct {
+	ID                int          `json:"id"`
+	Time              string       `json:"time"`
+	Operation         string       `json:"operation"`
+	Changes           []TaskChange `json:"changes"`
+	AddedTombstones   []Tombstone  `json:"addedTombstones,omitempty"`   // Suppressions définitives (trash empty)
+	RemovedTombstones []Tombstone  `json:"removedTombstones,omitempty"` // Suppressions définitives levées
+	NextIDBefore      int          `json:"nextIdBefore"`
+	NextIDAfter       int          `json:"nextIdAfter"`
+	Undone            bool         `json:"undone,omitempty"`
+}
+
+// History journal des opérations d'un fichier de tâches
+type History struct {
+	Entries []HistoryEntry `json:"entries"`
+	NextID  int            `json:"nextId"`
+
+	encoded map[historyKey][]byte // Entrées déjà encodées, réutilisées à chaque réécriture
+}
+
+// historyKey identifie l'encodage d'une entrée : seul Undone change après l'ajout
+type historyKey struct {
+	id     int
+	undone bool
+}
+
+// historyFile retourne le journal des opérations, vide si l'historique
+// n'a pas de sens (stockage en mémoire, gestionnaire sans fichier)
+func (tm *TodoManager) historyFile() string {
+	if tm.filename == "" {
+		return ""
+	}
+	if _, inMemory := tm.storage().(*MemoryStore); inMemory {
+		return ""
+	}
+	return tm.filename + ".history"
+}
+
+// sections listes de tâches suivies par l'historique
+func (tm *TodoManager) sections() map[string]*[]Task {
+	return map[string]*[]Task{
+		"tasks": &tm.Tasks,
+		"trash": &tm.Trash,
+	}
+}
+
+// snapshot mémorise l'état courant, référence du prochain enregistrement
+func (tm *TodoManager) snapshot() {
+	tm.baseline = make(map[string][]Task)
+	for name, tasks := range tm.sections() {
+		tm.baseline[name] = append([]Task(nil), (*tasks)...)
+	}
+	tm.baselineNextID = tm.NextID
+	tm.baselineTombstones = append([]Tombstone(nil), tm.Tombstones...)
+}
+
+// taskKey identifiant stable d'une tâche (UUID, sinon ID)
+func taskKey(task Task) string {
+	if task.UUID != "" {
+		return task.UUID
+	}
+	return fmt.Sprintf("#%d", task.ID)
+}
+
+// diffTasks calcule les modifications entre deux états d'une section
+func diffTasks(section string, before []Task, after []Task) []TaskChange {
+	var changes []TaskChange
+
+	previous := make(map[string]int, len(before))
+	for i, task := range before {
+		previous[taskKey(task)] = i
+	}
+
+	seen := make(map[string]bool, len(after))
+	for i := range after {
+		task := after[i]
+		key := taskKey(task)
+		seen[key] = true
+
+		index, existed := previous[key]
+		if !existed {
+			changes = append(changes, TaskChange{Section: section, Index: i, After: &after[i]})
+			continue
+		}
+		if !equalTasks(&before[index], &after[i]) {
+			changes = append(changes, TaskChange{Section: section, Index: index, Before: &before[index], After: &after[i]})
+		}
+	}
+
+	for i := range before {
+		if !seen[taskKey(before[i])] {
+			changes = append(changes, TaskChange{Section: section, Index: i, Before: &before[i]})
+		}
+	}
+
+	return changes
+}
+
+// diffTombstones calcule les UUID supprimés définitivement ou rétablis entre deux états
+func diffTombstones(before []Tombstone, after []Tombstone) (added []Tombstone, removed []Tombstone) {
+	previous := make(map[string]bool, len(before))
+	for _, tombstone := range before {
+		previous[tombstone.UUID] = true
+	}
+	current := make(map[string]bool, len(after))
+	for _, tombstone := range after {
+		current[tombstone.UUID] = true
+		if !previous[tombstone.UUID] {
+			added = append(added, tombstone)
+		}
+	}
+	for _, tombstone := range before {
+		if !current[tombstone.UUID] {
+			removed = append(removed, tombstone)
+		}
+	}
+	return added, removed
+}
+
+// updateTombstones retire de tombstones les UUID de remove puis ajoute ceux de add
+func updateTombstones(tombstones []Tombstone, add []Tombstone, remove []Tombstone) []Tombstone {
+	dropped := make(map[string]bool, len(remove)+len(add))
+	for _, tombstone := range append(append([]Tombstone(nil), remove...), add...) {
+		dropped[tombstone.UUID] = true
+	}
+	var result []Tombstone
+	for _, tombstone := range tombstones {
+		if !dropped[tombstone.UUID] {
+			result = append(result, tombstone)
+		}
+	}
+	return append(result, add...)
+}
+
+// readHistory lit le journal des opérations
+func readHistory(filename string) (*History, error) {
+	history := &History{NextID: 1}
+	data, err := readDataFile(filename)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("historique %s illisible : %v", filename, err)
+	}
+	return history, nil
+}
+
+// loadHistory lit le journal une seule fois par commande : les sauvegardes
+// suivantes réutilisent la version en mémoire (le verrou exclut tout autre écrivain)
+func (tm *TodoManager) loadHistory() (*History, error) {
+	if tm.history == nil {
+		history, err := readHistory(tm.historyFile())
+		if err != nil {
+			return nil, err
+		}
+		tm.history = history
+	}
+	return tm.history, nil
+}
+
+// save écrit le journal en ne gardant que les limit dernières opérations,
+// chiffré comme le fichier de tâches si c n'est pas nil
+func (h *History) save(filename string, limit int, c *fileCipher) error {
+	if limit <= 0 {
+		limit = defaultHistorySize
+	}
+	if len(h.Entries) > limit {
+		h.Entries = h.Entries[len(h.Entries)-limit:]
+	}
+
+	data, err := h.encode() // Compact : réécrit à chaque sauvegarde
+	if err == nil {
+		data, err = sealData(data, c)
+	}
+	if err != nil {
+		return err
+	}
+	return replaceFile(filename, data, dataFileMode)
+}
+
+// encode produit le JSON du journal ; seules les entrées nouvelles ou
+// annulées (rétablies) depuis la dernière écriture sont encodées
+func (h *History) encode() ([]byte, error) {
+	encoded := make(map[historyKey][]byte, len(h.Entries))
+	data := []byte(`{"entries":[`)
+	for i, entry := range h.Entries {
+		key := historyKey{entry.ID, entry.Undone}
+		raw, ok := h.encoded[key]
+		if !ok {
+			var err error
+			if raw, err = json.Marshal(entry); err != nil {
+				return nil, err
+			}
+		}
+		encoded[key] = raw
+		if i > 0 {
+			data = append(data, ',')
+		}
+		data = append(data, raw...)
+	}
+	h.encoded = encoded
+	return append(data, fmt.Sprintf(`],"nextId":%d}`, h.NextID)...), nil
+}
+
+// recordHistory ajoute au journal les modifications depuis le dernier état connu.
+// Une nouvelle opération abandonne les opérations annulées (plus de redo possible).
+func (tm *TodoManager) recordHistory() error {
+	filename := tm.historyFile()
+	if filename == "" {
+		return nil
+	}
+
+	var changes []TaskChange
+	for _, name := range sortedKeys(tm.sections()) {
+		changes = append(changes, diffTasks(name, tm.baseline[name], *tm.sections()[name])...)
+	}
+	added, removed := diffTombstones(tm.baselineTombstones, tm.Tombstones)
+	if len(changes) == 0 && len(added) == 0 && len(removed) == 0 && tm.NextID == tm.baselineNextID {
+		return nil
+	}
+
+	history, err := tm.loadHistory()
+	if err != nil {
+		return err
+	}
+
+	kept := history.Entries[:0]
+	for _, entry := range history.Entries {
+		if !entry.Undone {
+			kept = append(kept, entry)
+		}
+	}
+
+	operation := tm.operation
+	if operation == "" {
+		operation = "modification"
+	}
+
+	history.Entries = append(kept, HistoryEntry{
+		ID:                history.NextID,
+		Time:              timestamp(time.Now()),
+		Operation:         operation,
+		Changes:           changes,
+		AddedTombstones:   added,
+		RemovedTombstones: removed,
+		NextIDBefore:      tm.baselineNextID,
+		NextIDAfter:       tm.NextID,
+	})
+	history.NextID++
+
+	return history.save(filename, tm.historySize, tm.cipher())
+}
+
+// applyChanges applique des modifications (ou leur inverse) à l'état courant.
+// Échoue sans rien modifier si une tâche a changé depuis l'opération.
+func (tm *TodoManager) applyChanges(changes []TaskChange, inverse bool) error {
+	working := make(map[string][]Task)
+	for name, tasks := range tm.sections() {
+		working[name] = append([]Task(nil), (*tasks)...)
+	}
+
+	// Suppressions, puis modifications, puis insertions par position croissante :
+	// les tâches retrouvent leur ordre d'origine
+	oriented := make([]TaskChange, len(changes))
+	for i, change := range changes {
+		if inverse {
+			change.Before, change.After = change.After, change.Before
+		}
+		oriented[i] = change
+	}
+	sort.SliceStable(oriented, func(i, j int) bool {
+		return changeRank(oriented[i]) < changeRank(oriented[j])
+	})
+
+	for _, change := range oriented {
+		tasks, known := working[change.Section]
+		if !known {
+			return fmt.Errorf("section '%s' inconnue", change.Section)
+		}
+
+		current := -1
+		var key string
+		if change.Before != nil {
+			key = taskKey(*change.Before)
+		} else {
+			key = taskKey(*change.After)
+		}
+		for j, task := range tasks {
+			if taskKey(task) == key {
+				current = j
+				break
+			}
+		}
+
+		switch {
+		case change.Before == nil: // Ajout
+			if current != -1 {
+				return fmt.Errorf("la tâche [%d] existe déjà", change.After.ID)
+			}
+			index := change.Index
+			if index > len(tasks) {
+				index = len(tasks)
+			}
+			tasks = append(tasks[:index], append([]Task{*change.After}, tasks[index:]...)...)
+
+		case current == -1 || !sameTask(tasks[current], *change.Before):
+			return fmt.Errorf("la tâche [%d] a été modifiée depuis", change.Before.ID)
+
+		case change.After == nil: // Suppression
+			tasks = append(tasks[:current], tasks[current+1:]...)
+
+		default:
+			tasks[current] = *change.After
+		}
+		working[change.Section] = tasks
+	}
+
+	for name, tasks := range tm.sections() {
+		*tasks = working[name]
+		if *tasks == nil {
+			*tasks = []Task{}
+		}
+	}
+	return nil
+}
+
+// sameTask compare deux tâches telles qu'elles sont enregistrées
+// (une liste de tags vide et absente sont équivalentes)
+func sameTask(a Task, b Task) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(dataA) == string(dataB)
+}
+
+// equalTasks compare deux tâches champ par champ : appelée pour chaque tâche
+// à chaque sauvegarde, bien plus rapide que reflect.DeepEqual. Tout nouveau
+// champ de Task doit y figurer (vérifié par TestEqualTasks_AllFields).
+func equalTasks(a *Task, b *Task) bool {
+	return a.ID == b.ID && a.UUID == b.UUID && a.Text == b.Text && a.Status == b.Status &&
+		a.Priority == b.Priority && a.Due == b.Due && a.Created == b.Created && a.Updated == b.Updated &&
+		a.Deleted == b.Deleted && a.Parent == b.Parent && a.Reason == b.Reason &&
+		a.Scheduled == b.Scheduled && a.Wait == b.Wait && a.Until == b.Until &&
+		a.Recur == b.Recur && a.Series == b.Series && a.Estimate == b.Estimate &&
+		equalSlices(a.Tags, b.Tags) && equalSlices(a.DependsOn, b.DependsOn) && equalSlices(a.TimeLog, b.TimeLog)
+}
+
+// equalSlices compare deux listes ; nil et vide diffèrent (null ou [] en JSON)
+func equalSlices[T comparable](a []T, b []T) bool {
+	return (a == nil) == (b == nil) && slices.Equal(a, b)
+}
+
+// changeRank ordre d'application d'une modification
+func changeRank(change TaskChange) int {
+	switch {
+	case change.After == nil:
+		return 0
+	case change.Before == nil:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// replayHistory annule (undo) ou rejoue (redo) jusqu'à n opérations
+func (tm *TodoManager) replayHistory(n int, undo bool) ([]HistoryEntry, error) {
+	filename := tm.historyFile()
+	if filename == "" {
+		return nil, fmt.Errorf("historique indisponible pour ce stockage")
+	}
+
+	history, err := tm.loadHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	// Annuler part de la dernière opération active, rejouer de la plus ancienne annulée
+	var indexes []int
+	if undo {
+		for i := len(history.Entries) - 1; i >= 0 && len(indexes) < n; i-- {
+			if !history.Entries[i].Undone {
+				indexes = append(indexes, i)
+			}
+		}
+	} else {
+		for i := 0; i < len(history.Entries) && len(indexes) < n; i++ {
+			if history.Entries[i].Undone {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+
+	var replayed []HistoryEntry
+	for _, i := range indexes {
+		entry := &history.Entries[i]
+		if err := tm.applyChanges(entry.Changes, undo); err != nil {
+			if len(replayed) == 0 {
+				return nil, fmt.Errorf("impossible de traiter « %s » : %v", entry.Operation, err)
+			}
+			fmt.Fprintf(os.Stderr, "⚠️ Arrêt avant « %s » : %v\n", entry.Operation, err)
+			break
+		}
+		if undo {
+			tm.NextID = entry.NextIDBefore
+			tm.Tombstones = updateTombstones(tm.Tombstones, entry.RemovedTombstones, entry.AddedTombstones)
+		} else {
+			tm.NextID = entry.NextIDAfter
+			tm.Tombstones = updateTombstones(tm.Tombstones, entry.AddedTombstones, entry.RemovedTombstones)
+		}
+		entry.Undone = undo
+		replayed = append(replayed, *entry)
+	}
+
+	if len(replayed) == 0 {
+		return nil, nil
+	}
+
+	// Écrire les tâches sans créer de nouvelle entrée, puis le journal
+	if err := tm.storage().Save(tm); err != nil {
+		return nil, err
+	}
+	tm.snapshot()
+
+	verb := "redo"
+	if undo {
+		verb = "undo"
+	}
+	tm.commitToRepo(fmt.Sprintf("%s %s", verb, replayed[0].Operation))
+	return replayed, history.save(filename, tm.historySize, tm.cipher())
+}
+
+// Undo annule les n dernières opérations
+func (tm *TodoManager) Undo(n int) error {
+	undone, err := tm.replayHistory(n, true)
+	if err != nil {
+		return err
+	}
+	if len(undone) == 0 {
+		fmt.Println("📝 Aucune opération à annuler")
+		return nil
+	}
+	for _, entry := range undone {
+		fmt.Printf("↩️  Annulé : %s\n", entry.Operation)
+	}
+	return nil
+}
+
+// Redo rejoue les n dernières opérations annulées
+func (tm *TodoManager) Redo(n int) error {
+	redone, err := tm.replayHistory(n, false)
+	if err != nil {
+		return err
+	}
+	if len(redone) == 0 {
+		fmt.Println("📝 Aucune opération à rétablir")
+		return nil
+	}
+	for _, entry := range redone {
+		fmt.Printf("↪️  Rétabli : %s\n", entry.Operation)
+	}
+	return nil
+}
+
+// PrintHistory affiche le journal des opérations, la plus récente en premier
+func (tm *TodoManager) PrintHistory() error {
+	filename := tm.historyFile()
+	if filename == "" {
+		return fmt.Errorf("historique indisponible pour ce stockage")
+	}
+
+	history, err := tm.loadHistory()
+	if err != nil {
+		return err
+	}
+	if len(history.Entries) == 0 {
+		fmt.Println("📝 Aucune opération enregistrée")
+		return nil
+	}
+
+	for i := len(history.Entries) - 1; i >= 0; i-- {
+		entry := history.Entries[i]
+		color, marker := ColorReset, ""
+		if entry.Undone {
+			color, marker = ColorGray, " (annulée)"
+		}
+		fmt.Printf("%s%4d  %s  %s%s — %s%s\n", color, entry.ID, formatTimestamp(entry.Time), entry.Operation, marker,
+			describeChanges(entry.Changes), ColorReset)
+	}
+	return nil
+}
+
+// describeChanges résume une liste de modifications
+func describeChanges(changes []TaskChange) string {
+	var added, removed, modified int
+	for _, change := range changes {
+		switch {
+		case change.Before == nil:
+			added++
+		case change.After == nil:
+			removed++
+		default:
+			modified++
+		}
+	}
+
+	var parts []string
+	if added > 0 {
+		parts = append(parts, fmt.Sprintf("%d ajoutée(s)", added))
+	}
+	if modified > 0 {
+		parts = append(parts, fmt.Sprintf("%d modifiée(s)", modified))
+	}
+	if removed > 0 {
+		parts = append(parts, fmt.Sprintf("%d supprimée(s)", removed))
+	}
+	if len(parts) == 0 {
+		return "aucune tâche modifiée"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,239 @@
+// history_test.go - Tests de l'historique des opérations (undo/redo)
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// reloadManager relit le fichier de tâches comme le ferait une nouvelle commande
+func reloadManager(t *testing.T, filename string) *TodoManager {
+	t.Helper()
+	tm := newTestManager(filename, nil)
+	if err := tm.load(); err != nil {
+		t.Fatalf("Erreur de chargement: %v", err)
+	}
+	return tm
+}
+
+func TestHistory_UndoRedo(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Tâche 1", []string{"+dev"}, "high", "")
+	tm.Add("Tâche 2", nil, "", "")
+	tm.Add("Tâche 3", nil, "", "")
+	tm.Done(1)
+	tm.Edit(2, "Tâche 2 modifiée", []string{"@bureau"})
+
+	t.Run("annuler une modification", func(t *testing.T) {
+		tm := reloadManager(t, tm.filename)
+		if err := tm.Undo(1); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+
+		tm = reloadManager(t, tm.filename)
+		if task := assertTaskExists(t, tm, 2); task != nil && (task.Text != "Tâche 2" || len(task.Tags) != 0) {
+			t.Errorf("Texte et tags d'origine attendus: %+v", task)
+		}
+	})
+
+	t.Run("annuler plusieurs opérations", func(t *testing.T) {
+		tm := reloadManager(t, tm.filename)
+		if err := tm.Undo(2); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+
+		tm = reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 2)
+		if tm.Tasks[0].IsDone() {
+			t.Error("La tâche 1 ne devrait plus être terminée")
+		}
+		if tm.NextID != 3 {
+			t.Errorf("NextID attendu: 3, obtenu: %d", tm.NextID)
+		}
+	})
+
+	t.Run("rétablir", func(t *testing.T) {
+		tm := reloadManager(t, tm.filename)
+		if err := tm.Redo(1); err != nil {
+			t.Fatalf("Redo: %v", err)
+		}
+
+		tm = reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 3)
+		assertTaskExists(t, tm, 3)
+	})
+
+	t.Run("une nouvelle opération abandonne les annulations", func(t *testing.T) {
+		tm := reloadManager(t, tm.filename)
+		tm.Remove(3)
+
+		history, err := readHistory(tm.historyFile())
+		if err != nil {
+			t.Fatalf("Historique illisible: %v", err)
+		}
+		for _, entry := range history.Entries {
+			if entry.Undone {
+				t.Errorf("Opération annulée encore présente: %s", entry.Operation)
+			}
+		}
+
+		if err := tm.Redo(1); err != nil {
+			t.Fatalf("Redo: %v", err)
+		}
+		assertTaskCount(t, reloadManager(t, tm.filename), 2)
+	})
+}
+
+func TestHistory_UndoClearKeepsOrder(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	for _, text := range []string{"A", "B", "C", "D"} {
+		tm.Add(text, nil, "", "")
+	}
+	tm.Clear(true)
+
+	tm = reloadManager(t, tm.filename)
+	if err := tm.Undo(1); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+
+	tm = reloadManager(t, tm.filename)
+	var texts []string
+	for _, task := range tm.Tasks {
+		texts = append(texts, task.Text)
+	}
+	if strings.Join(texts, ",") != "A,B,C,D" {
+		t.Errorf("Ordre d'origine attendu, obtenu %v", texts)
+	}
+	if tm.NextID != 5 {
+		t.Errorf("NextID attendu: 5, obtenu: %d", tm.NextID)
+	}
+}
+
+func TestHistory_UndoEmptyTrash(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("A", nil, "", "")
+	tm.Remove(1)
+	tm.EmptyTrash(0)
+
+	tm = reloadManager(t, tm.filename)
+	if err := tm.Undo(1); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	tm = reloadManager(t, tm.filename)
+	if len(tm.Trash) != 1 || len(tm.Tombstones) != 0 {
+		t.Fatalf("Tâche en corbeille sans suppression définitive attendue: %+v / %+v", tm.Trash, tm.Tombstones)
+	}
+
+	t.Run("rétablir", func(t *testing.T) {
+		tm := reloadManager(t, tm.filename)
+		if err := tm.Redo(1); err != nil {
+			t.Fatalf("Redo: %v", err)
+		}
+		tm = reloadManager(t, tm.filename)
+		if len(tm.Trash) != 0 || len(tm.Tombstones) != 1 {
+			t.Errorf("Suppression définitive attendue après redo: %+v", tm.Tombstones)
+		}
+		if err := tm.Undo(1); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+	})
+
+	tm = reloadManager(t, tm.filename)
+	if err := tm.Restore("1"); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	tm = reloadManager(t, tm.filename)
+	if task := assertTaskExists(t, tm, 1); task != nil && tm.isDeleted(task.UUID) {
+		t.Errorf("La tâche restaurée ne doit plus être marquée supprimée: %+v", tm.Tombstones)
+	}
+}
+
+func TestHistory_Conflict(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Tâche", nil, "", "")
+	tm.Done(1)
+
+	// Modification hors historique (édition manuelle du fichier)
+	other := newTestManager(tm.filename, nil)
+	other.load()
+	other.Tasks[0].Text = "Modifiée à la main"
+	other.storage().Save(other)
+
+	tm = reloadManager(t, tm.filename)
+	if err := tm.Undo(1); err == nil || !strings.Contains(err.Error(), "modifiée depuis") {
+		t.Errorf("Conflit attendu, obtenu: %v", err)
+	}
+	if task := assertTaskExists(t, reloadManager(t, tm.filename), 1); task != nil && task.Text != "Modifiée à la main" {
+		t.Errorf("La modification manuelle doit être conservée: %s", task.Text)
+	}
+}
+
+func TestHistory_BoundedSize(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.historySize = 5
+	for i := 0; i < 12; i++ {
+		tm.Add("Tâche", nil, "", "")
+	}
+
+	history, err := readHistory(tm.historyFile())
+	if err != nil {
+		t.Fatalf("Historique illisible: %v", err)
+	}
+	if len(history.Entries) != 5 {
+		t.Errorf("5 opérations attendues, obtenu %d", len(history.Entries))
+	}
+	if history.Entries[4].Operation != "add [12] Tâche" {
+		t.Errorf("Dernière opération inattendue: %s", history.Entries[4].Operation)
+	}
+}
+
+func TestHistory_DisabledInMemory(t *testing.T) {
+	tm := newTestManager("todo.json", NewMemoryStore())
+	tm.Add("Tâche", nil, "", "")
+
+	if tm.historyFile() != "" {
+		t.Error("Pas d'historique attendu pour le stockage en mémoire")
+	}
+	if err := tm.Undo(1); err == nil {
+		t.Error("Undo devrait échouer sans historique")
+	}
+}
+
+func TestEqualTasks_AllFields(t *testing.T) {
+	taskType := reflect.TypeOf(Task{})
+	for i := 0; i < taskType.NumField(); i++ {
+		var changed Task
+		field := reflect.ValueOf(&changed).Elem().Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString("x")
+		case reflect.Int:
+			field.SetInt(1)
+		case reflect.Slice:
+			field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+		default:
+			t.Fatalf("Type du champ %s non prévu par le test", taskType.Field(i).Name)
+		}
+		if equalTasks(&Task{}, &changed) {
+			t.Errorf("equalTasks ignore le champ %s", taskType.Field(i).Name)
+		}
+	}
+
+	a := Task{ID: 1, Tags: []string{"+dev"}, TimeLog: []TimeEntry{{Start: "2025-07-16T09:00:00+02:00"}}}
+	b := a
+	b.Tags = append([]string(nil), a.Tags...)
+	if !equalTasks(&a, &b) {
+		t.Error("Deux tâches identiques doivent être égales")
+	}
+}
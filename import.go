@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -90,6 +91,7 @@ func (tm *TodoManager) ImportCSV(filename string, mode string, conflict string,
 
 	// Sauvegarder si pas en mode dry-run
 	if !options.DryRun && (result.NewTasks > 0 || result.UpdatedTasks > 0) {
+		tm.operation = fmt.Sprintf("import %s (%s)", filepath.Base(filename), mode)
 		tm.save()
 	}
 
@@ -178,9 +180,9 @@ func (tm *TodoManager) createTaskFromCSVRecord(record []string, columnMap map[st
 	task := Task{
 		ID:      tm.NextID,
 		Text:    strings.TrimSpace(record[textCol]),
-		Done:    false,
-		Created: time.Now().Format("2006-01-02 15:04:05"),
-		Updated: time.Now().Format("2006-01-02 15:04:05"),
+		Status:  StatusTodo,
+		Created: timestamp(time.Now()),
+		Updated: timestamp(time.Now()),
 	}
 
 	// Valider que le texte n'est pas vide
@@ -205,7 +207,16 @@ func (tm *TodoManager) createTaskFromCSVRecord(record []string, columnMap map[st
 	// Done
 	doneValue := strings.ToLower(getValue("done"))
 	if doneValue == "true" || doneValue == "1" {
-		task.Done = true
+		task.Status = StatusDone
+	}
+
+	// Status (prioritaire sur Done, absent des exports antérieurs)
+	if statusValue := getValue("status"); statusValue != "" {
+		if status, err := parseStatus(statusValue); err == nil {
+			task.Status = status
+		} else {
+			errors = append(errors, fmt.Sprintf("ligne %d: %v", lineNumber, err))
+		}
 	}
 
 	// Priority
@@ -221,8 +232,8 @@ func (tm *TodoManager) createTaskFromCSVRecord(record []string, columnMap map[st
 	// Due date
 	dueValue := getValue("due")
 	if dueValue != "" {
-		if validateDate(dueValue) {
-			task.Due = dueValue
+		if due, err := normalizeDue(dueValue); err == nil {
+			task.Due = due
 		} else {
 			errors = append(errors, fmt.Sprintf("ligne %d: date '%s' invalide, ignorée", lineNumber, dueValue))
 		}
@@ -237,8 +248,8 @@ func (tm *TodoManager) createTaskFromCSVRecord(record []string, columnMap map[st
 	// Created date
 	createdValue := getValue("created")
 	if createdValue != "" {
-		if tm.isValidDateTime(createdValue) {
-			task.Created = createdValue
+		if created, err := parseTimestamp(createdValue); err == nil {
+			task.Created = timestamp(created)
 		} else {
 			errors = append(errors, fmt.Sprintf("ligne %d: date de création '%s' invalide, date actuelle utilisée", lineNumber, createdValue))
 		}
@@ -247,18 +258,79 @@ func (tm *TodoManager) createTaskFromCSVRecord(record []string, columnMap map[st
 	// Updated date
 	updatedValue := getValue("updated")
 	if updatedValue != "" {
-		if tm.isValidDateTime(updatedValue) {
-			task.Updated = updatedValue
+		if updated, err := parseTimestamp(updatedValue); err == nil {
+			task.Updated = timestamp(updated)
 		} else {
 			errors = append(errors, fmt.Sprintf("ligne %d: date de mise à jour '%s' invalide, date actuelle utilisée", lineNumber, updatedValue))
 		}
 	}
 
+	// Parent (UUID de la tâche parente)
+	parentValue := getValue("parent")
+	if parentValue != "" {
+		if tm.isValidUUID(parentValue) {
+			task.Parent = parentValue
+		} else {
+			errors = append(errors, fmt.Sprintf("ligne %d: parent '%s' invalide, ignoré", lineNumber, parentValue))
+		}
+	}
+
+	// DependsOn (UUID des tâches bloquantes, séparés par des espaces)
+	for _, uuid := range strings.Fields(getValue("dependson")) {
+		if tm.isValidUUID(uuid) {
+			task.DependsOn = append(task.DependsOn, uuid)
+		} else {
+			errors = append(errors, fmt.Sprintf("ligne %d: dépendance '%s' invalide, ignorée", lineNumber, uuid))
+		}
+	}
+
+	// Recur, Series
+	if recurValue := getValue("recur"); recurValue != "" {
+		if rule, err := parseRecurrence(recurValue); err == nil {
+			task.Recur = rule.String()
+			task.Series = getValue("series")
+		} else {
+			errors = append(errors, fmt.Sprintf("ligne %d: %v", lineNumber, err))
+		}
+	}
+
+	// Scheduled, Wait, Until
+	for _, field := range []struct {
+		column string
+		value  *string
+	}{{"scheduled", &task.Scheduled}, {"wait", &task.Wait}, {"until", &task.Until}} {
+		if value := getValue(field.column); value != "" {
+			if date, err := normalizeDue(value); err == nil {
+				*field.value = date
+			} else {
+				errors = append(errors, fmt.Sprintf("ligne %d: date %s '%s' invalide, ignorée", lineNumber, field.column, value))
+			}
+		}
+	}
+
+	// Estimate
+	if value := getValue("estimate"); value != "" {
+		if estimate, err := normalizeEstimate(value); err == nil {
+			task.Estimate = estimate
+		} else {
+			errors = append(errors, fmt.Sprintf("ligne %d: estimation '%s' invalide, ignorée", lineNumber, value))
+		}
+	}
+
 	return task, errors
 }
 
 // processImportTask traite une tâche du CSV selon la stratégie de conflit
 func (tm *TodoManager) processImportTask(csvTask Task, existingTasks map[string]*Task, conflict string, options ImportOptions, result *ImportResult) bool {
+	// Tâche supprimée (corbeille ou suppression définitive) : ne pas la ressusciter
+	if tm.isDeleted(csvTask.UUID) {
+		result.SkippedTasks++
+		if options.Verbose {
+			fmt.Printf("⏭️ Tâche ignorée (supprimée, voir 'todo trash'): %s\n", csvTask.Text)
+		}
+		return false
+	}
+
 	existingTask, exists := existingTasks[csvTask.UUID]
 
 	if !exists {
@@ -318,11 +390,19 @@ func (tm *TodoManager) processImportTask(csvTask Task, existingTasks map[string]
 // updateExistingTask met à jour une tâche existante avec les données du CSV
 func (tm *TodoManager) updateExistingTask(existing *Task, csvTask Task) {
 	existing.Text = csvTask.Text
-	existing.Done = csvTask.Done
+	existing.Status = csvTask.Status
 	existing.Priority = csvTask.Priority
 	existing.Due = csvTask.Due
 	existing.Tags = csvTask.Tags
-	existing.Updated = time.Now().Format("2006-01-02 15:04:05")
+	existing.Parent = csvTask.Parent
+	existing.DependsOn = csvTask.DependsOn
+	existing.Recur = csvTask.Recur
+	existing.Series = csvTask.Series
+	existing.Scheduled = csvTask.Scheduled
+	existing.Wait = csvTask.Wait
+	existing.Until = csvTask.Until
+	existing.Estimate = csvTask.Estimate
+	existing.Updated = timestamp(time.Now())
 }
 
 // confirmReplace demande confirmation pour le mode replace
@@ -383,21 +463,10 @@ func (tm *TodoManager) isValidUUID(uuid string) bool {
 	return uuidRegex.MatchString(strings.ToLower(uuid))
 }
 
-// isValidDateTime vérifie si une date/heure est valide
+// isValidDateTime vérifie si une date/heure est valide (RFC 3339 ou heure locale)
 func (tm *TodoManager) isValidDateTime(dateTime string) bool {
-	formats := []string{
-		"2006-01-02 15:04:05",
-		"2006-01-02T15:04:05",
-		"2006-01-02 15:04",
-		"2006-01-02T15:04",
-	}
-
-	for _, format := range formats {
-		if _, err := time.Parse(format, dateTime); err == nil {
-			return true
-		}
-	}
-	return false
+	_, err := parseTimestamp(dateTime)
+	return err == nil
 }
 
 // parseTags parse une chaîne de tags séparés par des espaces
@@ -415,10 +484,11 @@ func (tm *TodoManager) parseTags(tagsStr string) []string {
 	return tags
 }
 
-// isNewer compare deux dates et retourne true si la première est plus récente
+// isNewer compare deux dates et retourne true si la première est plus récente.
+// Les horodatages RFC 3339 sont comparés en tenant compte de leur décalage.
 func (tm *TodoManager) isNewer(date1, date2 string) bool {
-	time1, err1 := time.Parse("2006-01-02 15:04:05", date1)
-	time2, err2 := time.Parse("2006-01-02 15:04:05", date2)
+	time1, err1 := parseTimestamp(date1)
+	time2, err2 := parseTimestamp(date2)
 
 	if err1 != nil || err2 != nil {
 		return false
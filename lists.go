@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultListName nom de la liste principale (todo.json)
+const DefaultListName = "default"
+
+// listNameRegex noms de listes autorisés (lettres, chiffres, - et _)
+var listNameRegex = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+// listFile retourne le fichier d'une liste nommée à partir du fichier principal
+func listFile(base string, name string) (string, error) {
+	if name == "" || name == DefaultListName {
+		return base, nil
+	}
+	if !listNameRegex.MatchString(name) {
+		return "", fmt.Errorf("nom de liste invalide '%s' (lettres, chiffres, - et _)", name)
+	}
+
+	dir := sidecarDir(base, "lists")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// ListInfo résumé d'une liste nommée
+type ListInfo struct {
+	Name     string
+	Filename string
+	Open     int
+	Total    int
+}
+
+// listNames énumère les listes existantes pour le stockage storeKind
+// (fichiers nommés comme par newStore), la liste principale en tête
+func listNames(base string, storeKind string) []string {
+	names := []string{DefaultListName}
+	storeExt := filepath.Ext(storeFile(storeKind, "liste.json"))
+
+	files, err := ioutil.ReadDir(sidecarDir(base, "lists"))
+	if err != nil {
+		return names
+	}
+
+	var named []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		name := strings.TrimSuffix(file.Name(), ext)
+		if file.IsDir() || ext != storeExt || !listNameRegex.MatchString(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		named = append(named, name)
+	}
+	sort.Strings(named)
+	return append(names, named...)
+}
+
+// collectLists charge le résumé de chaque liste
+func collectLists(base string, storeKind string) []ListInfo {
+	var lists []ListInfo
+
+	for _, name := range listNames(base, storeKind) {
+		filename, err := listFile(base, name)
+		if err != nil {
+			continue
+		}
+		store, err := newStore(storeKind, filename)
+		if err != nil {
+			continue
+		}
+
+		tm := &TodoManager{Tasks: []Task{}, NextID: 1, filename: filename, store: store}
+		tm.load()
+
+		info := ListInfo{Name: name, Filename: filename, Total: len(tm.Tasks)}
+		for _, task := range tm.Tasks {
+			if !task.IsClosed() {
+				info.Open++
+			}
+		}
+		lists = append(lists, info)
+	}
+
+	return lists
+}
+
+// PrintLists affiche les listes disponibles, la liste courante marquée
+func PrintLists(base string, current string, storeKind string) {
+	if current == "" {
+		current = DefaultListName
+	}
+
+	for _, info := range collectLists(base, storeKind) {
+		marker := "  "
+		color := ColorReset
+		if info.Name == current {
+			marker = "▶ "
+			color = ColorBold
+		}
+		fmt.Printf("%s%s%-15s%s %d ouverte(s) / %d tâche(s)\n",
+			color, marker, info.Name, ColorReset, info.Open, info.Total)
+	}
+}
+
+// MoveTask déplace une tâche et ses sous-tâches vers une autre liste en
+// conservant leurs UUID ; elles reçoivent de nouveaux ID dans la liste cible.
+// Une liste ne référence que ses propres tâches : le rattachement à une parente
+// et les dépendances entre les deux listes sont retirés et signalés.
+func (tm *TodoManager) MoveTask(id int, target *TodoManager, targetName string) error {
+	index := tm.findTask(id)
+	if index == -1 {
+		return fmt.Errorf("tâche [%d] introuvable", id)
+	}
+
+	task := tm.Tasks[index]
+	indexes := append([]int{index}, tm.descendants(task.UUID)...)
+	movedIndex := make(map[int]bool)
+	movedUUID := make(map[string]bool)
+	for _, i := range indexes {
+		movedIndex[i] = true
+		if tm.Tasks[i].UUID != "" {
+			movedUUID[tm.Tasks[i].UUID] = true
+		}
+	}
+	for _, existing := range target.Tasks {
+		if movedUUID[existing.UUID] {
+			return fmt.Errorf("la tâche [%d] existe déjà dans la liste '%s'", id, targetName)
+		}
+	}
+
+	now := timestamp(time.Now())
+	var notes []string
+	var moved []Task
+	for _, i := range indexes {
+		copied := tm.Tasks[i]
+		copied.ID = target.NextID
+		copied.Updated = now
+		target.NextID++
+		if copied.Parent != "" && !movedUUID[copied.Parent] {
+			copied.Parent = ""
+			notes = append(notes, fmt.Sprintf("[%d] de '%s' : tâche parente restée dans la liste d'origine, rattachement retiré", copied.ID, targetName))
+		}
+		copied.DependsOn = nil
+		for _, uuid := range tm.Tasks[i].DependsOn {
+			if movedUUID[uuid] {
+				copied.DependsOn = append(copied.DependsOn, uuid)
+			} else {
+				notes = append(notes, fmt.Sprintf("[%d] de '%s' : dépendance vers une tâche restée dans la liste d'origine retirée", copied.ID, targetName))
+			}
+		}
+		moved = append(moved, copied)
+	}
+	target.Tasks = append(target.Tasks, moved...)
+	target.operation = fmt.Sprintf("move [%d] %s (depuis une autre liste)", moved[0].ID, moved[0].Text)
+
+	// Écrire la cible d'abord : un crash entre les deux duplique la tâche au lieu de la perdre
+	if err := target.save(); err != nil {
+		return err
+	}
+
+	var remaining []Task
+	for i, other := range tm.Tasks {
+		if movedIndex[i] {
+			continue
+		}
+		var kept []string
+		for _, uuid := range other.DependsOn {
+			if !movedUUID[uuid] {
+				kept = append(kept, uuid)
+			}
+		}
+		if len(kept) != len(other.DependsOn) {
+			other.DependsOn = kept
+			other.Updated = now
+			notes = append(notes, fmt.Sprintf("[%d] : dépendance vers une tâche déplacée retirée", other.ID))
+		}
+		remaining = append(remaining, other)
+	}
+	if remaining == nil {
+		remaining = []Task{}
+	}
+	tm.Tasks = remaining
+	tm.operation = fmt.Sprintf("move [%d] %s vers '%s'", id, task.Text, targetName)
+	if err := tm.save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("📦 Tâche [%d] déplacée vers '%s' : [%d] %s\n", id, targetName, moved[0].ID, moved[0].Text)
+	if len(moved) > 1 {
+		fmt.Printf("   %d sous-tâche(s) déplacée(s) avec elle\n", len(moved)-1)
+	}
+	for _, note := range notes {
+		fmt.Printf("⚠️ %s\n", note)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// lists_test.go - Tests des listes nommées
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListFile(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "todo.json")
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"", base, false},
+		{"default", base, false},
+		{"work", filepath.Join(filepath.Dir(base), "lists", "work.json"), false},
+		{"release-2_0", filepath.Join(filepath.Dir(base), "lists", "release-2_0.json"), false},
+		{"../evil", "", true},
+		{"a/b", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listFile(base, tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("listFile(%q) devrait échouer", tt.name)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("listFile(%q): attendu %s, obtenu %s (%v)", tt.name, tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestTodoManager_MoveTask(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	base := filepath.Join(tempDir, "todo.json")
+	tm.filename = base
+	tm.Add("Tâche perso", nil, "", "")
+	tm.Add("Tâche équipe", []string{"+team"}, "high", "")
+	movedUUID := tm.Tasks[1].UUID
+
+	workFile, err := listFile(base, "work")
+	if err != nil {
+		t.Fatalf("listFile: %v", err)
+	}
+	work := newTestManager(workFile, nil)
+	work.Add("Existante", nil, "", "")
+
+	if err := tm.MoveTask(2, work, "work"); err != nil {
+		t.Fatalf("Erreur de déplacement: %v", err)
+	}
+
+	assertTaskCount(t, tm, 1)
+	assertTaskCount(t, work, 2)
+
+	moved := work.Tasks[1]
+	if moved.UUID != movedUUID {
+		t.Error("L'UUID doit être conservé")
+	}
+	if moved.ID != 2 || work.NextID != 3 {
+		t.Errorf("ID dans la liste cible: %d (NextID %d), attendu 2 (NextID 3)", moved.ID, work.NextID)
+	}
+	if tm.NextID != 3 {
+		t.Errorf("NextID de la source ne doit pas changer, obtenu: %d", tm.NextID)
+	}
+
+	// Les deux listes sont persistées
+	reloaded := newTestManager(workFile, nil)
+	reloaded.load()
+	assertTaskCount(t, reloaded, 2)
+
+	if got := listNames(base, StoreJSON); !reflect.DeepEqual(got, []string{"default", "work"}) {
+		t.Errorf("Listes attendues [default work], obtenues: %v", got)
+	}
+
+	// Chaque stockage retrouve les listes qu'il a écrites
+	for _, kind := range []string{StoreJournal, StoreEvents} {
+		store, _ := newStore(kind, filepath.Join(filepath.Dir(workFile), kind+".json"))
+		newTestManager(filepath.Join(filepath.Dir(workFile), kind+".json"), store).Add("Tâche", nil, "", "")
+		if got := listNames(base, kind); !reflect.DeepEqual(got, []string{"default", kind}) {
+			t.Errorf("Listes %s attendues [default %s], obtenues: %v", kind, kind, got)
+		}
+	}
+
+	if err := tm.MoveTask(99, work, "work"); err == nil {
+		t.Error("Déplacer une tâche inexistante devrait échouer")
+	}
+}
+
+func TestTodoManager_MoveTaskReferences(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Projet", nil, "", "")              // 1, reste
+	tm.Add("Préparer la release", nil, "", "") // 2, déplacée
+	tm.AddSubtask(2, "Changelog", nil, "", "") // 3, suit sa parente
+	tm.AddSubtask(1, "Annoncer", nil, "", "")  // 4
+	tm.Add("Publier", nil, "", "")             // 5, attend la 2
+	tm.AddDependency(5, 2)
+	tm.AddDependency(2, 1)
+	tm.Tasks[1].Parent = tm.Tasks[0].UUID // 2 rattachée à 1
+	tm.save()
+
+	work := newTestManager(filepath.Join(tempDir, "work.json"), nil)
+	if err := tm.MoveTask(2, work, "work"); err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+
+	work = reloadManager(t, work.filename)
+	assertTaskCount(t, work, 2)
+	release, changelog := work.Tasks[0], work.Tasks[1]
+	if release.Parent != "" || len(release.DependsOn) != 0 {
+		t.Errorf("Parente et dépendance restées dans la source à retirer: %+v", release)
+	}
+	if changelog.Text != "Changelog" || changelog.Parent != release.UUID {
+		t.Errorf("La sous-tâche doit suivre sa parente: %+v", changelog)
+	}
+
+	tm = reloadManager(t, tm.filename)
+	assertTaskCount(t, tm, 3)
+	if publish := assertTaskExists(t, tm, 5); len(publish.DependsOn) != 0 {
+		t.Errorf("La dépendance vers la tâche déplacée doit être retirée: %+v", publish)
+	}
+	if annonce := assertTaskExists(t, tm, 4); annonce.Parent != tm.Tasks[0].UUID {
+		t.Errorf("Les autres sous-tâches restent en place: %+v", annonce)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultLockTimeout délai d'attente par défaut du verrou
+const defaultLockTimeout = 5 * time.Second
+
+// lockRetryInterval intervalle entre deux tentatives de verrouillage
+const lockRetryInterval = 50 * time.Millisecond
+
+// acquireLock prend le verrou exclusif path, en réessayant jusqu'à timeout.
+// Le verrou est consultatif : seules les commandes todo le respectent.
+// Il est libéré par Unlock ou automatiquement à la fin du processus.
+func acquireLock(path string, timeout time.Duration) (*fileLock, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		lock, acquired, err := tryLockFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("impossible de verrouiller %s: %v", path, err)
+		}
+		if acquired {
+			return lock, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("verrou %s toujours occupé après %v (une autre commande todo est en cours ?)", path, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,64 @@
+// lock_test.go - Tests du verrouillage inter-processus
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "todo_lock_test")
+	if err != nil {
+		t.Fatalf("Impossible de créer le répertoire temporaire: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "todo.json.lock")
+
+	lock, err := acquireLock(path, time.Second)
+	if err != nil {
+		t.Fatalf("Premier verrou refusé: %v", err)
+	}
+
+	t.Run("verrou déjà pris", func(t *testing.T) {
+		start := time.Now()
+		_, err := acquireLock(path, 200*time.Millisecond)
+		if err == nil {
+			t.Fatal("Le second verrou aurait dû échouer")
+		}
+		if !strings.Contains(err.Error(), "occupé") {
+			t.Errorf("Message d'erreur peu clair: %v", err)
+		}
+		if time.Since(start) < 200*time.Millisecond {
+			t.Error("Le délai d'attente n'a pas été respecté")
+		}
+	})
+
+	t.Run("verrou libéré", func(t *testing.T) {
+		if err := lock.Unlock(); err != nil {
+			t.Fatalf("Erreur de libération: %v", err)
+		}
+		lock2, err := acquireLock(path, time.Second)
+		if err != nil {
+			t.Fatalf("Verrou refusé après libération: %v", err)
+		}
+		lock2.Unlock()
+	})
+
+	t.Run("option --lock-timeout", func(t *testing.T) {
+		opts, _, err := parseGlobalOptions([]string{"--lock-timeout=30s", "list"})
+		if err != nil {
+			t.Fatalf("Erreur inattendue: %v", err)
+		}
+		if opts.LockTimeout != 30*time.Second {
+			t.Errorf("Délai attendu: 30s, obtenu: %v", opts.LockTimeout)
+		}
+		if _, _, err := parseGlobalOptions([]string{"--lock-timeout=bientôt", "list"}); err == nil {
+			t.Error("Un délai invalide devrait être refusé")
+		}
+	})
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// fileLock verrou posé avec flock(2)
+type fileLock struct {
+	file *os.File
+}
+
+// tryLockFile tente de poser le verrou sans attendre
+func tryLockFile(path string) (*fileLock, bool, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &fileLock{file: file}, true, nil
+}
+
+// Unlock libère le verrou
+func (l *fileLock) Unlock() error {
+	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	return l.file.Close()
+}
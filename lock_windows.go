@@ -0,0 +1,44 @@
+//go:build windows
+
+package main
+
+import (
+	"syscall"
+)
+
+// errSharingViolation ERROR_SHARING_VIOLATION : fichier ouvert par un autre processus
+const errSharingViolation syscall.Errno = 32
+
+// fileLock verrou obtenu en ouvrant le fichier sans partage
+type fileLock struct {
+	handle syscall.Handle
+}
+
+// tryLockFile tente de poser le verrou sans attendre
+func tryLockFile(path string) (*fileLock, bool, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	handle, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+		0, // Aucun partage : un second processus échoue
+		nil,
+		syscall.OPEN_ALWAYS,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+	if err != nil {
+		if err == errSharingViolation {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &fileLock{handle: handle}, true, nil
+}
+
+// Unlock libère le verrou
+func (l *fileLock) Unlock() error {
+	return syscall.CloseHandle(l.handle)
+}
@@ -2,12 +2,13 @@ package main
 
 import (
 	"crypto/rand"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -22,22 +23,53 @@ var (
 
 // Task représente une tâche
 type Task struct {
-	ID       int      `json:"id"`
-	UUID     string   `json:"uuid"`
-	Text     string   `json:"text"`
-	Done     bool     `json:"done"`
-	Priority string   `json:"priority"`
-	Due      string   `json:"due"`
-	Tags     []string `json:"tags"`
-	Created  string   `json:"created"`
-	Updated  string   `json:"updated"`
+	ID        int         `json:"id"`
+	UUID      string      `json:"uuid"`
+	Text      string      `json:"text"`
+	Status    string      `json:"status"` // todo, in-progress, blocked, waiting, cancelled, done
+	Priority  string      `json:"priority"`
+	Due       string      `json:"due"`
+	Tags      []string    `json:"tags"`
+	Created   string      `json:"created"`
+	Updated   string      `json:"updated"`
+	Deleted   string      `json:"deleted,omitempty"`   // Date de mise à la corbeille
+	Parent    string      `json:"parent,omitempty"`    // UUID de la tâche parente
+	DependsOn []string    `json:"dependsOn,omitempty"` // UUID des tâches à terminer avant celle-ci
+	Reason    string      `json:"reason,omitempty"`    // Motif du blocage, de l'attente ou de l'annulation
+	Scheduled string      `json:"scheduled,omitempty"` // Début prévu du travail (YYYY-MM-DD)
+	Wait      string      `json:"wait,omitempty"`      // Masquée jusqu'à cette date
+	Until     string      `json:"until,omitempty"`     // Expire (masquée) après cette date
+	Recur     string      `json:"recur,omitempty"`     // Règle de récurrence (weekly:mon, monthly:15…)
+	Series    string      `json:"series,omitempty"`    // UUID commun aux occurrences d'une tâche récurrente
+	Estimate  string      `json:"estimate,omitempty"`  // Estimation : durée (2h) ou points (3pt)
+	TimeLog   []TimeEntry `json:"timeLog,omitempty"`   // Temps passé (todo start/stop, todo log)
 }
 
 // TodoManager gère les tâches
 type TodoManager struct {
-	Tasks    []Task `json:"tasks"`
-	NextID   int    `json:"nextId"`
-	filename string
+	SchemaVersion int         `json:"schemaVersion"`
+	Tasks         []Task      `json:"tasks"`
+	NextID        int         `json:"nextId"`
+	Trash         []Task      `json:"trash,omitempty"`      // Tâches supprimées, restaurables
+	Tombstones    []Tombstone `json:"tombstones,omitempty"` // UUID supprimés définitivement
+	filename      string
+	store         Store
+	loadErr       error
+
+	// Historique des opérations (undo/redo)
+	operation          string            // Description de la modification en cours
+	baseline           map[string][]Task // État au dernier chargement ou à la dernière sauvegarde
+	baselineNextID     int
+	baselineTombstones []Tombstone
+	historySize        int
+	history            *History
+
+	// Dépôt git du répertoire de données (todo git init), nil sinon
+	repo *GitRepo
+
+	// Instantanés horaires et quotidiens (nil : rétention par défaut)
+	snapshotPolicy *SnapshotPolicy
+	snapshotHour   string // Heure déjà photographiée par ce processus (20060102-15)
 }
 
 // generateUUID génère un UUID simple (version 4)
@@ -53,8 +85,8 @@ func generateUUID() string {
 		bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16])
 }
 
-// Constantes pour les couleurs
-const (
+// Couleurs (modifiables par le thème de la configuration)
+var (
 	ColorReset  = "\033[0m"
 	ColorRed    = "\033[31m"
 	ColorGreen  = "\033[32m"
@@ -64,7 +96,7 @@ const (
 	ColorBold   = "\033[1m"
 )
 
-// Clear supprime toutes les tâches avec confirmation
+// Clear supprime toutes les tâches avec confirmation (placées dans la corbeille)
 func (tm *TodoManager) Clear(force bool) {
 	if len(tm.Tasks) == 0 {
 		fmt.Println("📝 Aucune tâche à supprimer")
@@ -83,20 +115,22 @@ func (tm *TodoManager) Clear(force bool) {
 	}
 
 	count := len(tm.Tasks)
+	tm.moveToTrash(tm.Tasks...)
 	tm.Tasks = []Task{}
 	tm.NextID = 1
+	tm.operation = fmt.Sprintf("clear (%d tâches)", count)
 	tm.save()
 
 	fmt.Printf("🗑️  Toutes les tâches supprimées (%d tâches)\n", count)
 }
 
-// ClearDone supprime uniquement les tâches terminées
+// ClearDone supprime uniquement les tâches terminées (placées dans la corbeille)
 func (tm *TodoManager) ClearDone(force bool) {
 	var doneTasks []Task
 	var remainingTasks []Task
 
 	for _, task := range tm.Tasks {
-		if task.Done {
+		if task.IsClosed() {
 			doneTasks = append(doneTasks, task)
 		} else {
 			remainingTasks = append(remainingTasks, task)
@@ -119,7 +153,9 @@ func (tm *TodoManager) ClearDone(force bool) {
 		}
 	}
 
+	tm.moveToTrash(doneTasks...)
 	tm.Tasks = remainingTasks
+	tm.operation = fmt.Sprintf("clear --done (%d tâches)", len(doneTasks))
 	tm.save()
 
 	fmt.Printf("🗑️  Tâches terminées supprimées (%d tâches)\n", len(doneTasks))
@@ -127,64 +163,118 @@ func (tm *TodoManager) ClearDone(force bool) {
 
 // NewTodoManager crée un nouveau gestionnaire de tâches
 func NewTodoManager() *TodoManager {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		// Fallback sur le répertoire courant
-		homeDir = "."
-	}
-
-	todoDir := filepath.Join(homeDir, ".todo")
-	os.MkdirAll(todoDir, 0755)
-
-	filename := filepath.Join(todoDir, "todo.json")
+	filename := defaultDataFile()
+	return NewTodoManagerWithStore(filename, NewJSONFileStore(filename))
+}
 
+// NewTodoManagerWithStore crée un gestionnaire de tâches sur un stockage donné
+func NewTodoManagerWithStore(filename string, store Store) *TodoManager {
 	tm := &TodoManager{
 		Tasks:    []Task{},
 		NextID:   1,
 		filename: filename,
+		store:    store,
+		repo:     findDataRepo(filename),
 	}
 
-	tm.load()
+	if err := tm.load(); err != nil {
+		tm.loadErr = err
+		if errors.Is(err, errNewerSchema) || errors.Is(err, errKeyUnavailable) {
+			return tm // Signalé par l'appelant : ne surtout pas réécrire le fichier
+		}
+		fmt.Fprintf(os.Stderr, "⚠️ Impossible de lire %s : %v\n", filename, err)
+		fmt.Fprintln(os.Stderr, "   Le fichier sera mis en quarantaine à la prochaine modification.")
+		fmt.Fprintln(os.Stderr, "   Lancez 'todo doctor' pour le diagnostiquer et le réparer.")
+	}
 	return tm
 }
 
-// load charge les tâches depuis le fichier JSON
-func (tm *TodoManager) load() {
-	data, err := ioutil.ReadFile(tm.filename)
+// defaultDataFile retourne le chemin du fichier de tâches par défaut
+func defaultDataFile() string {
+	filename, _, err := resolveDataFile("")
 	if err != nil {
-		return // Fichier n'existe pas encore
+		return filepath.Join(".todo", "todo.json")
 	}
+	return filename
+}
 
-	json.Unmarshal(data, tm)
+// storage retourne le stockage utilisé (fichier JSON par défaut)
+func (tm *TodoManager) storage() Store {
+	if tm.store == nil {
+		tm.store = NewJSONFileStore(tm.filename)
+	}
+	return tm.store
 }
 
-// save sauvegarde les tâches dans le fichier JSON
+// load charge les tâches depuis le stockage
+func (tm *TodoManager) load() error {
+	err := tm.storage().Load(tm)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil // Fichier n'existe pas encore
+	}
+	if err == nil {
+		tm.snapshot()
+	}
+	return err
+}
+
+// save sauvegarde les tâches dans le stockage et enregistre l'opération
+// dans l'historique (todo undo). L'état précédent est photographié au
+// besoin (todo snapshots) et, dans un dépôt git, l'opération est commitée.
 func (tm *TodoManager) save() error {
-	data, err := json.MarshalIndent(tm, "", "  ")
-	if err != nil {
+	operation := tm.operation
+	if err := tm.takeSnapshots(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Instantané non enregistré : %v\n", err)
+	}
+	if err := tm.storage().Save(tm); err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(tm.filename, data, 0644)
+	if err := tm.recordHistory(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Historique non enregistré : %v\n", err)
+	}
+	tm.commitToRepo(operation)
+	tm.operation = ""
+	tm.snapshot()
+	return nil
 }
 
 // Add ajoute une nouvelle tâche avec tags séparés
 func (tm *TodoManager) Add(text string, tags []string, priority string, due string) {
-	task := Task{
-		ID:       tm.NextID,
-		UUID:     generateUUID(),
+	tm.add(Task{
 		Text:     text, // Texte intact, AUCUN nettoyage
-		Done:     false,
 		Priority: priority,
 		Due:      due,
 		Tags:     tags, // Tags passés en arguments uniquement
-		Created:  time.Now().Format("2006-01-02 15:04:05"),
-		Updated:  time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+// AddTask ajoute une tâche, éventuellement sous une tâche parente (parentID > 0)
+// et avec une règle de récurrence (rule non vide, voir parseRecurrence)
+func (tm *TodoManager) AddTask(task Task, parentID int, rule string) error {
+	if parentID > 0 {
+		i := tm.findTask(parentID)
+		if i < 0 {
+			return fmt.Errorf("tâche parente [%d] introuvable", parentID)
+		}
+		if tm.Tasks[i].IsClosed() {
+			return fmt.Errorf("la tâche parente [%d] est terminée ou annulée", parentID)
+		}
+		task.Parent = tm.Tasks[i].UUID
 	}
+	if rule != "" {
+		if err := task.setRecurrence(rule, time.Now()); err != nil {
+			return err
+		}
+	}
+	tm.add(task)
+	return nil
+}
 
-	tm.Tasks = append(tm.Tasks, task)
-	tm.NextID++
-	task.Updated = time.Now().Format("2006-01-02 15:04:05")
+// add enregistre une nouvelle tâche
+func (tm *TodoManager) add(task Task) {
+	task = tm.appendTask(task)
+	tm.operation = fmt.Sprintf("add [%d] %s", task.ID, task.Text)
 	tm.save()
 
 	// Debug - afficher ce qui est sauvegardé
@@ -192,12 +282,65 @@ func (tm *TodoManager) Add(text string, tags []string, priority string, due stri
 	fmt.Printf("   UUID: %s\n", task.UUID)
 	fmt.Printf("   Tags: %v\n", task.Tags)
 	fmt.Printf("   Priority: %s\n", task.Priority)
+	if task.Parent != "" {
+		fmt.Printf("   Parent: %s\n", task.Parent)
+	}
+	if task.Recur != "" {
+		fmt.Printf("   Recur: %s (due: %s)\n", task.Recur, task.Due)
+	}
+}
+
+// appendTask attribue ID, UUID et dates à une nouvelle tâche et l'ajoute
+// à la liste, sans sauvegarder
+func (tm *TodoManager) appendTask(task Task) Task {
+	task.ID = tm.NextID
+	task.UUID = generateUUID()
+	task.Status = StatusTodo
+	task.Created = timestamp(time.Now())
+	task.Updated = task.Created
+
+	tm.Tasks = append(tm.Tasks, task)
+	tm.NextID++
+	return task
+}
+
+// TaskFilter critères de sélection des tâches (list, search)
+
+type TaskFilter struct {
+	ShowDone bool     // Inclure les tâches terminées et annulées
+	Status   []string // Seulement ces statuts (remplace ShowDone)
+	Project  string   // Tag +projet (sous-chaîne)
+	Context  string   // Tag @contexte (sous-chaîne)
+	Priority string
+	Text     string    // Texte ou tag (sous-chaîne, sans casse)
+	Blocked  bool      // Seulement les tâches en attente d'une autre tâche
+	Ready    bool      // Seulement les tâches à faire ou en cours non bloquées
+	Waiting  bool      // Seulement les tâches masquées jusqu'à leur date Wait
+	DueBy    time.Time // Seulement les échéances au plus tard ce jour (zéro : pas de filtre)
 }
 
 // List affiche les tâches
 func (tm *TodoManager) List(showDone bool, projectFilter string, contextFilter string, priorityFilter string) {
-	filteredTasks := tm.filterTasks(showDone, projectFilter, contextFilter, priorityFilter)
+	tm.ListFiltered(TaskFilter{ShowDone: showDone, Project: projectFilter, Context: contextFilter, Priority: priorityFilter})
+}
 
+// ListFiltered affiche les tâches correspondant au filtre
+func (tm *TodoManager) ListFiltered(filter TaskFilter) {
+	tasks := filterTaskList(tm.Tasks, filter)
+	if filter.Blocked {
+		tasks = tm.filterBlocked(tasks, true)
+	}
+	if filter.Ready {
+		ready := TaskFilter{Status: []string{StatusTodo, StatusInProgress}} // Ni en attente, ni fermées
+		tasks = filterTaskList(tm.filterBlocked(tasks, false), ready)
+		tasks = slices.DeleteFunc(tasks, func(task Task) bool { return isScheduledLater(task, time.Now()) })
+	}
+	tm.printTaskList(tasks)
+	printEstimateSummary(tasks, time.Now())
+}
+
+// printTaskList trie et affiche une liste de tâches
+func (tm *TodoManager) printTaskList(filteredTasks []Task) {
 	if len(filteredTasks) == 0 {
 		fmt.Println("📝 Aucune tâche trouvée")
 		return
@@ -212,68 +355,112 @@ func (tm *TodoManager) List(showDone bool, projectFilter string, contextFilter s
 		return filteredTasks[i].ID < filteredTasks[j].ID
 	})
 
-	for _, task := range filteredTasks {
-		tm.printTask(task)
-	}
+	tm.printTaskTree(filteredTasks)
 }
 
 // filterTasks filtre les tâches selon les critères
 func (tm *TodoManager) filterTasks(showDone bool, projectFilter string, contextFilter string, priorityFilter string) []Task {
+	return filterTaskList(tm.Tasks, TaskFilter{ShowDone: showDone, Project: projectFilter, Context: contextFilter, Priority: priorityFilter})
+}
+
+// filterTaskList retourne les tâches correspondant au filtre
+func filterTaskList(tasks []Task, filter TaskFilter) []Task {
 	var filtered []Task
 
-	for _, task := range tm.Tasks {
-		// Filtre par statut
-		if !showDone && task.Done {
-			continue
+	for _, task := range tasks {
+		if filter.Matches(task) {
+			filtered = append(filtered, task)
 		}
+	}
 
-		// Filtre par projet (+tag)
-		if projectFilter != "" {
-			hasProject := false
-			for _, tag := range task.Tags {
-				if strings.HasPrefix(tag, "+") && strings.Contains(strings.ToLower(tag), strings.ToLower(projectFilter)) {
-					hasProject = true
-					break
-				}
-			}
-			if !hasProject {
-				continue
-			}
-		}
+	return filtered
+}
 
-		// Filtre par contexte (@tag)
-		if contextFilter != "" {
-			hasContext := false
-			for _, tag := range task.Tags {
-				if strings.HasPrefix(tag, "@") && strings.Contains(strings.ToLower(tag), strings.ToLower(contextFilter)) {
-					hasContext = true
-					break
-				}
-			}
-			if !hasContext {
-				continue
-			}
+// Matches indique si une tâche correspond au filtre
+func (filter TaskFilter) Matches(task Task) bool {
+	// Filtre par statut ; les tâches en attente (Wait) ou expirées (Until)
+	// sont masquées comme les tâches fermées
+	now := time.Now()
+	switch {
+	case filter.Waiting:
+		if !isWaiting(task, now) {
+			return false
 		}
-
-		// Filtre par priorité
-		if priorityFilter != "" && task.Priority != priorityFilter {
-			continue
+	case len(filter.Status) > 0:
+		if !slices.Contains(filter.Status, task.Status) {
+			return false
+		}
+	case !filter.ShowDone:
+		if task.IsClosed() || isWaiting(task, now) || isExpired(task, now) {
+			return false
 		}
+	}
 
-		filtered = append(filtered, task)
+	// Filtre par projet (+tag)
+	if filter.Project != "" && !hasTag(task, "+", filter.Project) {
+		return false
 	}
 
-	return filtered
+	// Filtre par contexte (@tag)
+	if filter.Context != "" && !hasTag(task, "@", filter.Context) {
+		return false
+	}
+
+	// Filtre par priorité
+	if filter.Priority != "" && task.Priority != filter.Priority {
+		return false
+	}
+
+	// Recherche de texte
+	if filter.Text != "" && !matchesText(task, filter.Text) {
+		return false
+	}
+
+	// Filtre par échéance (en retard compris)
+	if !filter.DueBy.IsZero() {
+		if cmp, ok := compareDay(task.Due, filter.DueBy); !ok || cmp > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasTag indique si une tâche a un tag de ce type contenant value (sans casse)
+func hasTag(task Task, prefix string, value string) bool {
+	for _, tag := range task.Tags {
+		if strings.HasPrefix(tag, prefix) && strings.Contains(strings.ToLower(tag), strings.ToLower(value)) {
+			return true
+		}
+	}
+	return false
 }
 
-// printTask affiche une tâche formatée
-func (tm *TodoManager) printTask(task Task) {
-	status := "⭕"
+// printTask affiche une tâche formatée, indentée selon sa profondeur.
+// index (newTaskIndex) sert aux dépendances et à la progression des sous-tâches.
+func (tm *TodoManager) printTask(task Task, depth int, index *taskIndex) {
+	status, known := statusIcons[task.Status]
+	if !known {
+		status = statusIcons[StatusTodo]
+	}
 	color := ColorReset
+	if task.IsClosed() || task.Status == StatusBlocked || task.Status == StatusWaiting {
+		color = ColorGray
+	}
 
-	if task.Done {
-		status = "✅"
+	// Tâche en attente d'une autre : grisée, avec les tâches qui la bloquent
+	blockedStr := ""
+	if blockers := index.openBlockers(task); len(blockers) > 0 && !task.IsClosed() {
+		status = statusIcons[StatusBlocked]
 		color = ColorGray
+		var ids []string
+		for _, blocker := range blockers {
+			ids = append(ids, strconv.Itoa(blocker.ID))
+		}
+		blockedStr = " " + ColorGray + "[bloquée par: " + strings.Join(ids, ", ") + "]" + ColorReset
+	}
+	if task.Reason != "" {
+		blockedStr += " " + ColorGray + "(" + task.Reason + ")" + ColorReset
 	}
 
 	// Icône de priorité
@@ -290,52 +477,66 @@ func (tm *TodoManager) printTask(task Task) {
 	// Date limite
 	dueStr := ""
 	if task.Due != "" {
-		dueDate, err := time.Parse("2006-01-02", task.Due)
+		dueDate, _, err := parseDue(task.Due, time.Local)
 		if err == nil {
 			now := time.Now()
 			if dueDate.Before(now) {
-				dueStr = ColorRed + "[due:" + task.Due + "]" + ColorReset
+				dueStr = ColorRed + "[due:" + formatDue(task.Due) + "]" + ColorReset
 			} else {
-				dueStr = ColorYellow + "[due:" + task.Due + "]" + ColorReset
+				dueStr = ColorYellow + "[due:" + formatDue(task.Due) + "]" + ColorReset
 			}
 		}
 	}
 
+	// Début prévu, attente, expiration ; estimation et temps passé
+	planningStr := planningLabels(task, time.Now())
+	if task.Estimate != "" {
+		planningStr += " " + ColorGray + "[est:" + task.Estimate + "]" + ColorReset
+	}
+	planningStr += timeLabel(task, time.Now())
+
 	// Tags
 	tagStr := ""
 	if len(task.Tags) > 0 {
 		tagStr = " " + ColorBlue + strings.Join(task.Tags, " ") + ColorReset
 	}
 
-	// Date de completion
+	// Récurrence
+	recurStr := ""
+	if task.Recur != "" {
+		recurStr = " " + ColorGray + "🔁 " + task.Recur + ColorReset
+	}
+
+	// Progression des sous-tâches
+	progressStr := ""
+	if done, total := index.progress(task); total > 0 {
+		progressStr = fmt.Sprintf(" %s[%d/%d]%s", ColorGray, done, total, ColorReset)
+	}
+
+	// Date de completion (ou d'annulation)
 	completedStr := ""
-	if task.Done {
-		completedStr = " " + ColorGray + "[done:" + task.Updated + "]" + ColorReset
+	if task.IsClosed() {
+		completedStr = " " + ColorGray + "[" + task.Status + ":" + formatTimestamp(task.Updated) + "]" + ColorReset
 	}
 
-	fmt.Printf("%s[%d] %s %s %s %s%s%s\n",
-		color, task.ID, status, priorityIcon, dueStr, task.Text, tagStr, completedStr)
+	fmt.Printf("%s%s[%d] %s %s %s %s%s%s%s%s%s%s\n",
+		color, taskIndent(depth), task.ID, status, priorityIcon, dueStr, task.Text, recurStr, progressStr, planningStr, tagStr, blockedStr, completedStr)
 }
 
 // Done marque une tâche comme terminée
 func (tm *TodoManager) Done(id int) {
-	for i, task := range tm.Tasks {
-		if task.ID == id {
-			tm.Tasks[i].Done = true
-			tm.Tasks[i].Updated = time.Now().Format("2006-01-02 15:04:05")
-			tm.save()
-			fmt.Printf("✅ Tâche [%d] marquée comme terminée\n", id)
-			return
-		}
+	if err := tm.Complete(id, false); err != nil {
+		fmt.Printf("❌ %v\n", err)
 	}
-	fmt.Printf("❌ Tâche [%d] introuvable\n", id)
 }
 
-// Remove supprime une tâche
+// Remove supprime une tâche (placée dans la corbeille)
 func (tm *TodoManager) Remove(id int) {
 	for i, task := range tm.Tasks {
 		if task.ID == id {
+			tm.moveToTrash(task)
 			tm.Tasks = append(tm.Tasks[:i], tm.Tasks[i+1:]...)
+			tm.operation = fmt.Sprintf("remove [%d] %s", id, task.Text)
 			tm.save()
 			fmt.Printf("🗑️ Tâche [%d] supprimée\n", id)
 			return
@@ -344,43 +545,87 @@ func (tm *TodoManager) Remove(id int) {
 	fmt.Printf("❌ Tâche [%d] introuvable\n", id)
 }
 
-// Edit modifie une tâche
+// dateNone valeur de todo edit --due (ou --scheduled…) qui efface la date
+const dateNone = "none"
+
+// TaskEdit modifications demandées par todo edit
+type TaskEdit struct {
+	Text      string   // Nouveau texte (vide : texte et tags inchangés)
+	Tags      []string // Nouveaux tags, avec le texte
+	Due       string   // Dates normalisées (vide : inchangée, dateNone : effacée)
+	Scheduled string
+	Wait      string
+	Until     string
+	Estimate  string // Estimation normalisée (vide : inchangée, dateNone : effacée)
+}
+
+// Edit modifie le texte et les tags d'une tâche
 func (tm *TodoManager) Edit(id int, newText string, tags []string) {
-	for i, task := range tm.Tasks {
-		if task.ID == id {
-			tm.Tasks[i].Text = newText
-			tm.Tasks[i].Tags = tags
-			tm.Tasks[i].Updated = time.Now().Format("2006-01-02 15:04:05")
-			tm.save()
-			fmt.Printf("✏️ Tâche [%d] modifiée\n", id)
-			return
+	tm.Update(id, TaskEdit{Text: newText, Tags: tags})
+}
+
+// Update applique les modifications de todo edit à une tâche
+func (tm *TodoManager) Update(id int, edit TaskEdit) {
+	i := tm.findTask(id)
+	if i < 0 {
+		fmt.Printf("❌ Tâche [%d] introuvable\n", id)
+		return
+	}
+
+	task := &tm.Tasks[i]
+	if edit.Text != "" {
+		task.Text = edit.Text
+		task.Tags = edit.Tags
+	}
+	for _, change := range []struct{ value, field *string }{
+		{&edit.Due, &task.Due}, {&edit.Scheduled, &task.Scheduled}, {&edit.Wait, &task.Wait}, {&edit.Until, &task.Until},
+		{&edit.Estimate, &task.Estimate},
+	} {
+		switch *change.value {
+		case "":
+		case dateNone:
+			*change.field = ""
+		default:
+			*change.field = *change.value
 		}
 	}
-	fmt.Printf("❌ Tâche [%d] introuvable\n", id)
+	task.Updated = timestamp(time.Now())
+	tm.operation = fmt.Sprintf("edit [%d] %s", id, task.Text)
+	tm.save()
+	fmt.Printf("✏️ Tâche [%d] modifiée\n", id)
 }
 
 // ExportCSV exporte les tâches en CSV
 func (tm *TodoManager) ExportCSV(filename string) error {
 	var lines []string
-	lines = append(lines, "ID,UUID,Text,Done,Priority,Due,Tags,Created,Updated")
+	lines = append(lines, "ID,UUID,Text,Done,Priority,Due,Tags,Created,Updated,Parent,DependsOn,Recur,Series,Status,Scheduled,Wait,Until,Estimate")
 
 	for _, task := range tm.Tasks {
-		line := fmt.Sprintf("%d,%s,\"%s\",%t,%s,%s,\"%s\",%s,%s",
+		line := fmt.Sprintf("%d,%s,\"%s\",%t,%s,%s,\"%s\",%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s",
 			task.ID,
 			task.UUID,
 			strings.ReplaceAll(task.Text, "\"", "\"\""),
-			task.Done,
+			task.IsDone(),
 			task.Priority,
 			task.Due,
 			strings.Join(task.Tags, " "),
 			task.Created,
 			task.Updated,
+			task.Parent,
+			strings.Join(task.DependsOn, " "),
+			task.Recur,
+			task.Series,
+			task.Status,
+			task.Scheduled,
+			task.Wait,
+			task.Until,
+			task.Estimate,
 		)
 		lines = append(lines, line)
 	}
 
 	content := strings.Join(lines, "\n")
-	return ioutil.WriteFile(filename, []byte(content), 0644)
+	return ioutil.WriteFile(filename, []byte(content), dataFileMode)
 }
 
 // parsePriority convertit les alias de priorité
@@ -397,12 +642,10 @@ func parsePriority(priority string) string {
 	}
 }
 
-// validateDate valide le format de date
+// validateDate valide une date saisie : YYYY-MM-DD (heure facultative) ou
+// expression relative (demain, friday, +3d…)
 func validateDate(dateStr string) bool {
-	if dateStr == "" {
-		return true
-	}
-	_, err := time.Parse("2006-01-02", dateStr)
+	_, err := normalizeDue(dateStr)
 	return err == nil
 }
 
@@ -415,25 +658,97 @@ func Usage() {
 	fmt.Println(`📋 Todo Manager CLI
 
 Usage:
-  todo add "Ma tâche" [+projet] [@contexte] [--priority=high] [--due=2025-07-20]
-  todo list [--all] [--project=dev] [--context=maison] [--priority=high]
-  todo done <id>
+  todo [--list=nom] [--store=json|journal|memory|encrypted|events] [--lock-timeout=5s] [--verbose] <commande> [options]
+
+  todo add "Ma tâche" [+projet] [@contexte] [--priority=high] [--due=2025-07-20] [--parent=12] [--recur=weekly:mon]
+           [--scheduled=2025-07-18] [--wait=2025-07-15] [--until=2025-07-31] [--estimate=2h|3pt]
+  todo list [--all] [--project=dev] [--context=maison] [--priority=high] [--status=in-progress,blocked] [--blocked | --ready | --waiting] [--archived]
+  todo search <texte>
+  todo archive [--before=2025-07-01]
+  todo done <id> [--cascade]
+  todo start <id> | block <id> [--reason="..."] | cancel <id> [--reason="..."]
+  todo status <id> <todo|in-progress|blocked|waiting|cancelled|done> [--reason="..."]
+  todo stop
+  todo log <id> <durée> [--date=hier]
+  todo timesheet [--week | --month | --since=2025-07-01]
+  todo accuracy
+  todo depends <id> <id-bloquante> [--remove]
   todo remove <id>
-  todo edit <id> "Nouveau texte" [+projet] [@contexte]
+  todo edit <id> ["Nouveau texte" [+projet] [@contexte]] [--due=friday] [--scheduled|--wait|--until=…]
   todo export [filename.csv]
   todo import <fichier.csv> [--mode=merge|replace] [--conflict=skip|update|newer] [--dry-run] [--verbose]
   todo clear [--done] [--force]
   todo reset
+  todo init
+  todo lists
+  todo move <id> <liste>
+  todo doctor [--fix] [--check]
+  todo migrate [--dry-run | --xdg]
+  todo config get <clé> | set <clé> <valeur> | list
+  todo undo [n] | redo [n] | history
+  todo trash [empty [--older-than=30d]]
+  todo restore <id|uuid>
+  todo snapshots
+  todo restore --at="2025-07-20 10:00"
+  todo diff --since=<instantané|"2025-07-20 10:00">
+  todo encrypt | decrypt | lock
+  todo git init [<dépôt distant>] | push | pull | log
+  todo audit [<id|uuid>]
+
+Options globales (avant la commande):
+  --file           Fichier de tâches à utiliser - défaut: $TODO_FILE
+  --list           Liste de tâches nommée (ex: work, perso) - défaut: $TODO_LIST ou default
+  --store          Stockage (json, journal, memory, encrypted, events) - défaut: $TODO_STORE ou json
+  --key-file       Fichier de clé des fichiers chiffrés - défaut: $TODO_KEY_FILE
+  --lock-timeout   Attente maximale du verrou si une autre commande tourne - défaut: 5s
+  --verbose        Afficher le fichier de tâches utilisé
+
+Fichier de tâches (par ordre de priorité):
+  --file ou $TODO_FILE, puis le fichier .todo/todo.json (ou .todo.json) du projet
+  courant cherché en remontant depuis le répertoire courant, puis $TODO_DIR/todo.json,
+  puis ~/.todo/todo.json s'il existe encore, sinon $XDG_DATA_HOME/todo/todo.json
+  (~/.local/share/todo/todo.json). todo init crée une liste propre au projet dans
+  le répertoire courant ; todo migrate --xdg déplace ~/.todo vers le répertoire XDG.
+
+Configuration ($XDG_CONFIG_HOME/todo/config, défaut ~/.config/todo/config, format INI):
+  [core]   store = json                  Stockage par défaut
+  [list]   filter = --priority=high      Options ajoutées à 'todo list'
+  [alias]  t = add                       'todo t "Tâche"' équivaut à 'todo add "Tâche"'
+  [color]  theme = default|bright|none   red = magenta, blue = 1;36…
+  [export] path = ~/todo_export.csv      Fichier d'export par défaut
+  [import] conflict = newer              Stratégie de conflit par défaut
+  [history] size = 100                   Opérations conservées pour todo undo
+  [snapshots] hourly = 24, daily = 7     Instantanés conservés (0 : désactivés)
+  [crypto] keyfile = ~/.todo.key, cache = 15m   Clé et cache de la phrase secrète
 
 Options pour add:
   --priority, -p    Priorité (low, medium, high)
-  --due, -d        Date limite (format: YYYY-MM-DD)
+  --due, -d        Date limite (YYYY-MM-DD, "YYYY-MM-DD HH:MM" ou relative, voir Dates)
+  --parent         ID de la tâche parente (sous-tâche)
+  --recur          Récurrence : daily, weekly[:mon,thu], monthly[:15], after:3d
+  --scheduled      Début prévu du travail
+  --wait           Masquer la tâche jusqu'à cette date
+  --until          Expiration : la tâche est masquée après cette date
+  --estimate       Estimation : durée (2h, 45m, 1h30) ou points (3pt, 5sp, 3)
+
+Options pour edit:
+  --due, --scheduled, --wait, --until   Nouvelle date (none pour l'effacer)
+  --estimate                            Nouvelle estimation (none pour l'effacer)
+
+Options pour done:
+  --cascade        Terminer aussi les sous-tâches ouvertes (sinon la tâche reste ouverte)
 
 Options pour list:
   --all, -a        Afficher toutes les tâches (y compris terminées)
   --project       Filtrer par projet (cherche dans les tags +projet)
   --context       Filtrer par contexte (cherche dans les tags @contexte)
   --priority      Filtrer par priorité
+  --status        Filtrer par statut (liste séparée par des virgules, ex: in-progress,blocked)
+  --blocked       Seulement les tâches bloquées (statut blocked ou en attente d'une tâche ouverte)
+  --ready         Seulement les tâches ouvertes non bloquées dont le début prévu est atteint
+  --waiting       Seulement les tâches masquées jusqu'à leur date --wait
+  --due           Échéance au plus tard ce jour, retards compris (ex: --due=friday)
+  --archived      Afficher les tâches archivées (lecture seule)
   --help, -h      Afficher cette aide
 
 Tags (arguments séparés du texte):
@@ -450,6 +765,89 @@ Options pour clear:
   --done           Supprimer uniquement les tâches terminées
   --force, -f      Supprimer sans demander confirmation
 
+Statuts:
+  Une tâche est todo ⭕, in-progress 🔄, blocked ⛔, waiting ⏳, cancelled 🚫 ou done ✅.
+  todo start la démarre, todo block et todo cancel la bloquent ou l'annulent (--reason
+  pour le motif), todo status <id> <statut> choisit n'importe quel statut. Les tâches
+  terminées ou annulées ne sont plus listées (sauf --all ou --status).
+
+Suivi du temps:
+  todo start <id> lance aussi un minuteur (▶️ dans todo list), arrêté par todo stop, par
+  le démarrage d'une autre tâche ou quand la tâche est terminée : un seul tourne à la
+  fois, et il survit à la fermeture du terminal. todo log 3 45m ajoute du temps à la
+  main (1h30, 2h, 90). todo timesheet totalise la semaine par projet (+tag).
+
+Estimations:
+  todo add "Refonte" --estimate=2h (ou 3pt pour des points) affiche [est:2h00]. todo list
+  somme les estimations et le reste à faire (estimation moins temps suivi) par +projet et
+  @contexte ; todo accuracy compare estimations et temps suivi des tâches terminées.
+
+Tâches récurrentes:
+  todo add "Rapport hebdo" --recur=weekly:fri crée une tâche marquée 🔁. Quand elle est
+  terminée, l'occurrence suivante est créée avec la prochaine échéance (after:3d : trois
+  jours après la complétion). Les occurrences partagent un UUID de série.
+
+Dates:
+  Les options de date acceptent 2025-07-20, "2025-07-20 14:00" ou une date relative :
+  today, tomorrow, friday, next friday, next week, next month, eow, eom, eoy, +3d, +2w,
+  +1m, "in 2 weeks" ; en français aujourd'hui, demain, après-demain, vendredi,
+  "vendredi prochain", "semaine prochaine", "fin du mois", "dans 3 jours". Une heure
+  peut suivre : "demain 14h", "friday 9:30". Un jour seul (friday) inclut aujourd'hui.
+
+Dates de planification:
+  --scheduled indique quand commencer (jaune une fois atteint, exclu de --ready avant),
+  --wait masque la tâche jusqu'à cette date (voir list --waiting) et --until la fait
+  expirer : passé ce jour, elle n'est plus listée (sauf --all). Une tâche récurrente
+  garde ces écarts par rapport à son échéance.
+
+Dépendances:
+  todo depends 5 3 indique que la tâche 5 attend la tâche 3 : tant que 3 est ouverte,
+  5 est affichée grisée avec ⛔. Les dépendances circulaires sont refusées ; terminer
+  une tâche encore bloquée affiche un avertissement.
+
+Corbeille:
+  remove et clear placent les tâches dans la corbeille. todo restore les récupère ;
+  todo trash empty les supprime définitivement (--older-than=30d : seulement celles
+  supprimées depuis plus de 30 jours). Un import ne ressuscite jamais une tâche supprimée.
+
+Archives:
+  todo archive déplace les tâches terminées (avant --before si précisé) vers
+  archive/AAAA-MM.json à côté du fichier de tâches, selon le mois de complétion.
+  todo list --archived et todo search les consultent sans les modifier.
+
+Instantanés:
+  Avant chaque modification, l'état du fichier est photographié une fois par heure
+  et une fois par jour dans snapshots/ (stockage json uniquement). todo restore
+  --at revient au dernier instantané antérieur à la date (annulable avec todo undo) ;
+  todo diff --since affiche les tâches ajoutées (+), supprimées (-) et modifiées (~).
+
+Chiffrement:
+  todo encrypt chiffre le fichier de tâches, son historique et ses archives (AES-256-GCM,
+  clé dérivée par scrypt d'un fichier de clé, de $TODO_PASSPHRASE ou d'une phrase saisie).
+  Une phrase saisie reste en cache 15 minutes ; todo lock l'oublie. todo decrypt
+  revient au fichier en clair. Les fichiers de tâches sont créés en mode 0600.
+
+Synchronisation git:
+  todo git init fait du répertoire de données un dépôt git : chaque modification y
+  crée un commit décrivant l'opération (ex: "done [12] Préparer CV"). todo git push
+  et todo git pull synchronisent avec le dépôt distant (ex: un dépôt nu partagé) ;
+  si les deux côtés ont changé, les tâches sont fusionnées par UUID.
+
+Stockage events:
+  --store=events enregistre chaque modification comme un événement (TaskAdded,
+  TaskCompleted, TaskEdited, TaskRemoved…) ajouté à todo.events ; l'état est
+  reconstruit au chargement. Tous les 500 événements, le segment est archivé
+  (todo.events.000001) et remplacé par un instantané. todo audit affiche
+  l'historique complet, ou celui d'une tâche.
+
+Options pour migrate:
+  --dry-run        Afficher les migrations de schéma en attente sans rien modifier
+  --xdg            Déplacer les données de ~/.todo vers $XDG_DATA_HOME/todo
+
+Options pour doctor:
+  --fix            Réparer sans demander confirmation (l'original est mis en quarantaine)
+  --check          Diagnostiquer uniquement (code de sortie 1 si problème)
+
 Exemples d'import:
   todo import backup.csv
   todo import tasks.csv --mode=merge --conflict=newer
@@ -470,36 +868,233 @@ Exemples:
   todo clear --force            # Supprimer toutes les tâches sans confirmation
   todo clear --done             # Supprimer uniquement les tâches terminées
   todo reset                    # Supprimer toutes les tâches sans confirmation (alias)
+  todo undo                     # Annuler la dernière modification
+  todo undo 3                   # Annuler les 3 dernières modifications
+  todo redo                     # Rétablir la dernière modification annulée
 
 Note: Les tags dans le texte ne sont PAS interprétés.
 Seuls les arguments +tag @tag après le texte sont utilisés comme tags.`)
 }
 
+// builtinCommands commandes reconnues (les alias ne peuvent pas les remplacer)
+var builtinCommands = []string{
+	"add", "list", "done", "start", "block", "cancel", "status", "depends", "stop", "log", "timesheet", "accuracy", "remove", "edit", "export", "import", "clear", "reset",
+	"init", "lists", "move", "doctor", "migrate", "config", "undo", "redo", "history",
+	"trash", "restore", "archive", "search", "snapshots", "diff",
+	"encrypt", "decrypt", "lock", "git", "audit", "version", "help",
+}
+
+// isBuiltinCommand indique si name est une commande intégrée
+func isBuiltinCommand(name string) bool {
+	for _, command := range builtinCommands {
+		if command == name {
+			return true
+		}
+	}
+	return false
+}
+
+// globalOptions options acceptées avant la sous-commande
+type globalOptions struct {
+	File        string
+	KeyFile     string
+	Store       string
+	List        string
+	LockTimeout time.Duration
+	Verbose     bool
+}
+
+// parseGlobalOptions extrait les options globales placées avant la sous-commande
+func parseGlobalOptions(args []string) (globalOptions, []string, error) {
+	opts := globalOptions{
+		Store:       os.Getenv("TODO_STORE"),
+		List:        os.Getenv("TODO_LIST"),
+		LockTimeout: defaultLockTimeout,
+	}
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[0], "--"), "=")
+
+		// Options booléennes
+		if name == "verbose" {
+			opts.Verbose = !hasValue || value == "true"
+			args = args[1:]
+			continue
+		}
+
+		switch name {
+		case "file", "key-file", "store", "list", "lock-timeout":
+		default:
+			// Pas une option globale : laisser la sous-commande (ex: --help) la traiter
+			return opts, args, nil
+		}
+
+		if !hasValue {
+			if len(args) < 2 {
+				return opts, nil, fmt.Errorf("option --%s sans valeur", name)
+			}
+			value = args[1]
+			args = args[1:]
+		}
+		args = args[1:]
+
+		switch name {
+		case "file":
+			opts.File = value
+		case "key-file":
+			opts.KeyFile = value
+		case "store":
+			opts.Store = value
+		case "list":
+			opts.List = value
+		case "lock-timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return opts, nil, fmt.Errorf("délai de verrou invalide '%s' (ex: 10s)", value)
+			}
+			opts.LockTimeout = timeout
+		}
+	}
+
+	return opts, args, nil
+}
+
 func main() {
-	if len(os.Args) < 2 {
+	opts, rest, err := parseGlobalOptions(os.Args[1:])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	config, err := LoadConfig(defaultConfigFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Configuration : %v\n", err)
+	}
+	config.applyColors()
+	if opts.Store == "" {
+		opts.Store = config.GetDefault("core.store", "")
+	}
+	keyring.KeyFile = opts.KeyFile
+	if keyring.KeyFile == "" {
+		keyring.KeyFile = config.GetDefault("crypto.keyfile", "")
+	}
+	if ttl, err := parseAge(config.GetDefault("crypto.cache", "")); err == nil {
+		keyring.CacheTTL = ttl
+	}
+
+	args := append([]string{os.Args[0]}, config.expandAlias(rest)...)
+
+	if len(args) < 2 {
 		Usage()
 		os.Exit(1)
 	}
 
-	tm := NewTodoManager()
-	command := os.Args[1]
+	command := args[1]
+
+	if command == "config" {
+		if err := RunConfigCommand(config, args[2:]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if command == "lock" {
+		if err := keyring.Forget(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("🔒 Clés en cache oubliées")
+		return
+	}
+
+	if command == "init" {
+		cwd, _ := os.Getwd()
+		filename, err := InitProject(cwd)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📁 Liste de projet créée : %s\n", filename)
+		return
+	}
+
+	if command == "migrate" && len(args) > 2 && args[2] == "--xdg" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		filename, err := MigrateLegacyData(homeDir, opts.LockTimeout)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📦 Données déplacées de %s vers %s\n", legacyDataDir(homeDir), filepath.Dir(filename))
+		return
+	}
+
+	baseFile, source, err := resolveDataFile(opts.File)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	filename, err := listFile(baseFile, opts.List)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "📂 Fichier de tâches : %s (%s)\n", filename, source)
+	}
+
+	store, err := newStore(opts.Store, filename)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	// Verrou tenu pendant tout le cycle chargement-modification-sauvegarde
+	switch command {
+	case "version", "help", "-h", "--help":
+	default:
+		lock, err := acquireLock(filename+".lock", opts.LockTimeout)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer lock.Unlock()
+	}
+
+	tm := NewTodoManagerWithStore(filename, store)
+	tm.historySize, _ = strconv.Atoi(config.GetDefault("history.size", ""))
+	tm.snapshotPolicy = snapshotPolicyFromConfig(config)
+	if errors.Is(tm.loadErr, errNewerSchema) {
+		fmt.Printf("❌ %s : %v\n", filename, tm.loadErr)
+		fmt.Println("   Mettez à jour todo pour lire ce fichier.")
+		os.Exit(1)
+	}
+	if errors.Is(tm.loadErr, errKeyUnavailable) {
+		fmt.Printf("❌ %s : %v\n", filename, tm.loadErr)
+		os.Exit(1)
+	}
 
 	switch command {
 	case "add":
-		if len(os.Args) < 3 {
+		if len(args) < 3 {
 			fmt.Println("❌ Usage: todo add \"Ma tâche\" [+projet] [@contexte] [--priority=high] [--due=2025-07-20]")
 			os.Exit(1)
 		}
 
-		text := os.Args[2]
+		text := args[2]
 
 		// Extraire les tags des arguments restants (avant les flags)
 		var tags []string
 		var flagStart = 3
 
 		// Parcourir les arguments pour trouver les tags et où commencent les flags
-		for i := 3; i < len(os.Args); i++ {
-			arg := os.Args[i]
+		for i := 3; i < len(args); i++ {
+			arg := args[i]
 			if strings.HasPrefix(arg, "--") || strings.HasPrefix(arg, "-") {
 				flagStart = i
 				break
@@ -514,11 +1109,17 @@ func main() {
 		addFlags := flag.NewFlagSet("add", flag.ExitOnError)
 		priority := addFlags.String("priority", "", "Priorité (low, medium, high)")
 		priorityShort := addFlags.String("p", "", "Priorité (alias)")
-		due := addFlags.String("due", "", "Date limite (YYYY-MM-DD)")
+		due := addFlags.String("due", "", "Date limite (YYYY-MM-DD ou \"YYYY-MM-DD HH:MM\")")
 		dueShort := addFlags.String("d", "", "Date limite (alias)")
-
-		if flagStart < len(os.Args) {
-			addFlags.Parse(os.Args[flagStart:])
+		parent := addFlags.Int("parent", 0, "ID de la tâche parente")
+		recur := addFlags.String("recur", "", "Récurrence (daily, weekly:mon,thu, monthly:15, after:3d)")
+		scheduled := addFlags.String("scheduled", "", "Début prévu (YYYY-MM-DD)")
+		wait := addFlags.String("wait", "", "Masquer jusqu'à cette date (YYYY-MM-DD)")
+		until := addFlags.String("until", "", "Expiration (YYYY-MM-DD)")
+		estimate := addFlags.String("estimate", "", "Estimation (2h, 45m, 3pt)")
+
+		if flagStart < len(args) {
+			addFlags.Parse(args[flagStart:])
 		} else {
 			addFlags.Parse([]string{})
 		}
@@ -533,12 +1134,30 @@ func main() {
 
 		*priority = parsePriority(*priority)
 
-		if !validateDate(*due) {
-			fmt.Println("❌ Format de date invalide. Utilisez YYYY-MM-DD")
+		for _, date := range []*string{due, scheduled, wait, until} {
+			normalized, err := normalizeDue(*date)
+			if err != nil {
+				fmt.Println("❌ Format de date invalide. Utilisez YYYY-MM-DD ou \"YYYY-MM-DD HH:MM\"")
+				os.Exit(1)
+			}
+			*date = normalized
+		}
+
+		normalizedEstimate, err := normalizeEstimate(*estimate)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
 			os.Exit(1)
 		}
 
-		tm.Add(text, tags, *priority, *due)
+		if *parent > 0 || *recur != "" || *scheduled != "" || *wait != "" || *until != "" || normalizedEstimate != "" {
+			task := Task{Text: text, Tags: tags, Priority: *priority, Due: *due, Scheduled: *scheduled, Wait: *wait, Until: *until, Estimate: normalizedEstimate}
+			if err := tm.AddTask(task, *parent, *recur); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			tm.Add(text, tags, *priority, *due)
+		}
 
 	case "list":
 		listFlags := flag.NewFlagSet("list", flag.ExitOnError)
@@ -547,33 +1166,219 @@ func main() {
 		project := listFlags.String("project", "", "Filtrer par projet (+tag)")
 		context := listFlags.String("context", "", "Filtrer par contexte (@tag)")
 		priority := listFlags.String("priority", "", "Filtrer par priorité")
+		archived := listFlags.Bool("archived", false, "Afficher les tâches archivées")
+		blocked := listFlags.Bool("blocked", false, "Seulement les tâches bloquées")
+		ready := listFlags.Bool("ready", false, "Seulement les tâches prêtes")
+		statusFilter := listFlags.String("status", "", "Filtrer par statut (ex: in-progress,blocked)")
+		waiting := listFlags.Bool("waiting", false, "Seulement les tâches masquées jusqu'à leur date wait")
+		dueBy := listFlags.String("due", "", "Échéance au plus tard ce jour (ex: today, friday, +3d)")
 
-		listFlags.Parse(os.Args[2:])
+		// Filtre par défaut de la configuration, surchargé par la ligne de commande
+		defaults := strings.Fields(config.GetDefault("list.filter", ""))
+		listFlags.Parse(append(defaults, args[2:]...))
 
 		showDone := *showAll || *showAllShort
-		tm.List(showDone, *project, *context, *priority)
+		filter := TaskFilter{ShowDone: showDone, Project: *project, Context: *context, Priority: *priority, Blocked: *blocked, Ready: *ready, Waiting: *waiting}
+		for _, value := range strings.Split(*statusFilter, ",") {
+			if value = strings.TrimSpace(value); value == "" {
+				continue
+			}
+			status, err := parseStatus(value)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			filter.Status = append(filter.Status, status)
+		}
+		if *dueBy != "" {
+			day, _, err := parseDate(*dueBy, clock())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			filter.DueBy = day
+		}
+		if *archived {
+			if err := tm.ListArchived(filter); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			break
+		}
+		tm.ListFiltered(filter)
 
 	case "done":
-		if len(os.Args) < 3 {
-			fmt.Println("❌ Usage: todo done <id>")
+		if len(args) < 3 {
+			fmt.Println("❌ Usage: todo done <id> [--cascade]")
+			os.Exit(1)
+		}
+
+		id, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Println("❌ ID invalide")
+			os.Exit(1)
+		}
+
+		doneFlags := flag.NewFlagSet("done", flag.ExitOnError)
+		cascade := doneFlags.Bool("cascade", false, "Terminer aussi les sous-tâches ouvertes")
+		doneFlags.Parse(args[3:])
+
+		if err := tm.Complete(id, *cascade); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "stop":
+		if err := tm.Stop(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "log":
+		logFlags := flag.NewFlagSet("log", flag.ExitOnError)
+		date := logFlags.String("date", "", "Fin du travail (ex: hier, \"2025-07-20 18:00\") - défaut: maintenant")
+		if len(args) < 4 {
+			fmt.Println("❌ Usage: todo log <id> <durée> [--date=hier]")
+			os.Exit(1)
+		}
+		logFlags.Parse(args[4:])
+
+		id, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Println("❌ ID invalide")
+			os.Exit(1)
+		}
+		spent, err := parseTimeSpent(args[3])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		end := clock()
+		if *date != "" {
+			day, hasTime, err := parseDate(*date, end)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			if !hasTime {
+				day = time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, day.Location())
+			}
+			end = day
+		}
+		if err := tm.LogTime(id, spent, end); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "accuracy":
+		if err := tm.Accuracy(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "timesheet":
+		timesheetFlags := flag.NewFlagSet("timesheet", flag.ExitOnError)
+		timesheetFlags.Bool("week", false, "Semaine en cours (défaut)")
+		month := timesheetFlags.Bool("month", false, "Mois en cours")
+		since := timesheetFlags.String("since", "", "Depuis cette date (ex: 2025-07-01, -2w)")
+		timesheetFlags.Parse(args[2:])
+
+		from, to := timesheetPeriod(*month, clock())
+		if *since != "" {
+			day, _, err := parseDate(*since, clock())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			from, to = day, clock()
+		}
+		if err := tm.Timesheet(from, to); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "start", "block", "cancel", "status":
+		statusFlags := flag.NewFlagSet(command, flag.ExitOnError)
+		reason := statusFlags.String("reason", "", "Motif (block, cancel, status)")
+		var positional []string
+		for i := 2; i < len(args); i++ {
+			if strings.HasPrefix(args[i], "-") {
+				statusFlags.Parse(args[i:])
+				positional = append(positional, statusFlags.Args()...)
+				break
+			}
+			positional = append(positional, args[i])
+		}
+
+		status := map[string]string{"start": StatusInProgress, "block": StatusBlocked, "cancel": StatusCancelled}[command]
+		wanted := 1
+		if command == "status" {
+			wanted = 2
+		}
+		if len(positional) != wanted {
+			if command == "status" {
+				fmt.Printf("❌ Usage: todo status <id> <%s> [--reason=\"...\"]\n", strings.Join(taskStatuses, "|"))
+			} else {
+				fmt.Printf("❌ Usage: todo %s <id> [--reason=\"...\"]\n", command)
+			}
 			os.Exit(1)
 		}
 
-		id, err := strconv.Atoi(os.Args[2])
+		id, err := strconv.Atoi(positional[0])
 		if err != nil {
 			fmt.Println("❌ ID invalide")
 			os.Exit(1)
 		}
+		if command == "status" {
+			if status, err = parseStatus(positional[1]); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		tm.Done(id)
+		if command == "start" {
+			err = tm.Start(id) // Démarre aussi le minuteur
+		} else {
+			err = tm.SetStatus(id, status, *reason)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "depends":
+		dependsFlags := flag.NewFlagSet("depends", flag.ExitOnError)
+		remove := dependsFlags.Bool("remove", false, "Supprimer la dépendance")
+		var ids []int
+		for _, arg := range args[2:] {
+			if id, err := strconv.Atoi(arg); err == nil {
+				ids = append(ids, id)
+			} else {
+				dependsFlags.Parse([]string{arg})
+			}
+		}
+		if len(ids) != 2 {
+			fmt.Println("❌ Usage: todo depends <id> <id-bloquante> [--remove]")
+			os.Exit(1)
+		}
+
+		if *remove {
+			err = tm.RemoveDependency(ids[0], ids[1])
+		} else {
+			err = tm.AddDependency(ids[0], ids[1])
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
 
 	case "remove":
-		if len(os.Args) < 3 {
+		if len(args) < 3 {
 			fmt.Println("❌ Usage: todo remove <id>")
 			os.Exit(1)
 		}
 
-		id, err := strconv.Atoi(os.Args[2])
+		id, err := strconv.Atoi(args[2])
 		if err != nil {
 			fmt.Println("❌ ID invalide")
 			os.Exit(1)
@@ -582,47 +1387,82 @@ func main() {
 		tm.Remove(id)
 
 	case "edit":
-		if len(os.Args) < 4 {
-			fmt.Println("❌ Usage: todo edit <id> \"Nouveau texte\" [+projet] [@contexte]")
+		if len(args) < 4 {
+			fmt.Println("❌ Usage: todo edit <id> [\"Nouveau texte\" [+projet] [@contexte]] [--due=friday]")
 			os.Exit(1)
 		}
 
-		id, err := strconv.Atoi(os.Args[2])
+		id, err := strconv.Atoi(args[2])
 		if err != nil {
 			fmt.Println("❌ ID invalide")
 			os.Exit(1)
 		}
 
-		newText := os.Args[3]
+		// Texte facultatif si seules les dates changent : todo edit 3 --due=friday
+		var edit TaskEdit
+		var flagArgs []string
+		for i := 3; i < len(args); i++ {
+			arg := args[i]
+			switch {
+			case strings.HasPrefix(arg, "--"):
+				flagArgs = append(flagArgs, arg)
+			case i == 3:
+				edit.Text = arg
+			case strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "@"):
+				// Si l'argument commence par + ou @, c'est un tag
+				edit.Tags = append(edit.Tags, arg)
+			}
+		}
 
-		// Extraire les tags des arguments restants (comme pour add)
-		var tags []string
-		for i := 4; i < len(os.Args); i++ {
-			arg := os.Args[i]
-			// Si l'argument commence par + ou @, c'est un tag
-			if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "@") {
-				tags = append(tags, arg)
+		editFlags := flag.NewFlagSet("edit", flag.ExitOnError)
+		due := editFlags.String("due", "", "Date limite (none pour l'effacer)")
+		scheduled := editFlags.String("scheduled", "", "Début prévu (none pour l'effacer)")
+		wait := editFlags.String("wait", "", "Masquer jusqu'à cette date (none pour l'effacer)")
+		until := editFlags.String("until", "", "Expiration (none pour l'effacer)")
+		estimate := editFlags.String("estimate", "", "Estimation : 2h, 3pt (none pour l'effacer)")
+		editFlags.Parse(flagArgs)
+
+		for _, date := range []struct{ value, field *string }{
+			{due, &edit.Due}, {scheduled, &edit.Scheduled}, {wait, &edit.Wait}, {until, &edit.Until},
+		} {
+			if *date.value == dateNone {
+				*date.field = dateNone
+				continue
+			}
+			normalized, err := normalizeDue(*date.value)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
 			}
+			*date.field = normalized
+		}
+		if *estimate == dateNone {
+			edit.Estimate = dateNone
+		} else if normalized, err := normalizeEstimate(*estimate); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		} else {
+			edit.Estimate = normalized
 		}
 
-		tm.Edit(id, newText, tags)
+		tm.Update(id, edit)
 
 	case "import":
-		if len(os.Args) < 3 {
+		if len(args) < 3 {
 			fmt.Println("❌ Usage: todo import <fichier.csv> [--mode=merge|replace] [--conflict=skip|update|newer] [--dry-run] [--verbose]")
 			os.Exit(1)
 		}
 
-		filename := os.Args[2]
+		filename := args[2]
 
 		// Parse des flags
 		importFlags := flag.NewFlagSet("import", flag.ExitOnError)
 		mode := importFlags.String("mode", "merge", "Mode d'import (merge, replace)")
-		conflict := importFlags.String("conflict", "skip", "Stratégie de conflit (skip, update, newer)")
+		conflict := importFlags.String("conflict", config.GetDefault("import.conflict", "skip"), "Stratégie de conflit (skip, update, newer)")
 		dryRun := importFlags.Bool("dry-run", false, "Aperçu sans modification")
 		verbose := importFlags.Bool("verbose", false, "Mode verbeux")
 
-		importFlags.Parse(os.Args[3:])
+		importFlags.Parse(args[3:])
 
 		// Valider les paramètres
 		if *mode != "merge" && *mode != "replace" {
@@ -647,9 +1487,9 @@ func main() {
 		}
 
 	case "export":
-		filename := "todo_export.csv"
-		if len(os.Args) > 2 {
-			filename = os.Args[2]
+		filename := expandHome(config.GetDefault("export.path", "todo_export.csv"))
+		if len(args) > 2 {
+			filename = args[2]
 		}
 
 		err := tm.ExportCSV(filename)
@@ -667,7 +1507,7 @@ func main() {
 		forceShort := clearFlags.Bool("f", false, "Supprimer sans confirmation (alias)")
 		doneOnly := clearFlags.Bool("done", false, "Supprimer uniquement les tâches terminées")
 
-		clearFlags.Parse(os.Args[2:])
+		clearFlags.Parse(args[2:])
 
 		forceDelete := *force || *forceShort
 
@@ -681,6 +1521,269 @@ func main() {
 		// Alias pour clear --force
 		tm.Clear(true)
 
+	case "lists":
+		PrintLists(baseFile, opts.List, opts.Store)
+
+	case "move":
+		if len(args) < 4 {
+			fmt.Println("❌ Usage: todo [--list=source] move <id> <liste>")
+			os.Exit(1)
+		}
+
+		id, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Println("❌ ID invalide")
+			os.Exit(1)
+		}
+
+		targetName := args[3]
+		targetFile, err := listFile(baseFile, targetName)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if targetFile == filename {
+			fmt.Printf("❌ La tâche [%d] est déjà dans la liste '%s'\n", id, targetName)
+			os.Exit(1)
+		}
+
+		targetLock, err := acquireLock(targetFile+".lock", opts.LockTimeout)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer targetLock.Unlock()
+
+		targetStore, _ := newStore(opts.Store, targetFile)
+		target := NewTodoManagerWithStore(targetFile, targetStore)
+		if target.loadErr != nil {
+			fmt.Printf("❌ Liste '%s' illisible : %v\n", targetName, target.loadErr)
+			os.Exit(1)
+		}
+
+		if err := tm.MoveTask(id, target, targetName); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "migrate":
+		migrateFlags := flag.NewFlagSet("migrate", flag.ExitOnError)
+		dryRun := migrateFlags.Bool("dry-run", false, "Aperçu sans modification")
+
+		migrateFlags.Parse(args[2:])
+
+		if err := tm.Migrate(*dryRun); err != nil {
+			fmt.Printf("❌ Erreur lors de la migration : %v\n", err)
+			os.Exit(1)
+		}
+
+	case "doctor":
+		doctorFlags := flag.NewFlagSet("doctor", flag.ExitOnError)
+		fix := doctorFlags.Bool("fix", false, "Réparer sans demander confirmation")
+		check := doctorFlags.Bool("check", false, "Diagnostiquer uniquement")
+
+		doctorFlags.Parse(args[2:])
+
+		if !tm.Doctor(*fix, *check) {
+			os.Exit(1)
+		}
+
+	case "archive":
+		archiveFlags := flag.NewFlagSet("archive", flag.ExitOnError)
+		before := archiveFlags.String("before", "", "Archiver les tâches terminées avant cette date (YYYY-MM-DD)")
+		archiveFlags.Parse(args[2:])
+
+		var limit time.Time
+		if *before != "" {
+			limit, err = time.ParseInLocation("2006-01-02", *before, time.Local)
+			if err != nil {
+				fmt.Println("❌ Format de date invalide. Utilisez YYYY-MM-DD")
+				os.Exit(1)
+			}
+		}
+
+		if _, err := tm.Archive(limit); err != nil {
+			fmt.Printf("❌ Erreur lors de l'archivage : %v\n", err)
+			os.Exit(1)
+		}
+
+	case "search":
+		if len(args) < 3 {
+			fmt.Println("❌ Usage: todo search <texte>")
+			os.Exit(1)
+		}
+
+		if err := tm.Search(strings.Join(args[2:], " ")); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "trash":
+		if len(args) < 3 {
+			tm.PrintTrash()
+			break
+		}
+		if args[2] != "empty" {
+			fmt.Println("❌ Usage: todo trash [empty [--older-than=30d]]")
+			os.Exit(1)
+		}
+
+		trashFlags := flag.NewFlagSet("trash empty", flag.ExitOnError)
+		olderThan := trashFlags.String("older-than", "", "Âge minimal des tâches à supprimer (ex: 30d)")
+		trashFlags.Parse(args[3:])
+
+		var age time.Duration
+		if *olderThan != "" {
+			age, err = parseAge(*olderThan)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		}
+		tm.EmptyTrash(age)
+
+	case "restore":
+		if len(args) < 3 {
+			fmt.Println("❌ Usage: todo restore <id|uuid> | --at=\"2025-07-20 10:00\"")
+			os.Exit(1)
+		}
+
+		if strings.HasPrefix(args[2], "--at") {
+			restoreFlags := flag.NewFlagSet("restore", flag.ExitOnError)
+			at := restoreFlags.String("at", "", "Date ou nom de l'instantané à restaurer")
+			restoreFlags.Parse(args[2:])
+			err = tm.RestoreSnapshot(*at)
+		} else {
+			err = tm.Restore(args[2])
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "git":
+		if len(args) < 3 {
+			fmt.Println("❌ Usage: todo git init [<dépôt distant>] | push | pull | log")
+			os.Exit(1)
+		}
+
+		repo := findDataRepo(baseFile)
+		if args[2] != "init" && repo == nil {
+			fmt.Printf("❌ %s n'est pas un dépôt git de todo, lancez 'todo git init'\n", filepath.Dir(baseFile))
+			os.Exit(1)
+		}
+
+		switch args[2] {
+		case "init":
+			remote := ""
+			if len(args) > 3 {
+				remote = args[3]
+			}
+			repo, err = InitGitRepo(filepath.Dir(baseFile), remote)
+			if err == nil {
+				fmt.Printf("📚 Dépôt git initialisé : %s\n", repo.dir)
+			}
+		case "push":
+			err = repo.Push()
+		case "pull":
+			err = repo.Pull()
+		case "log":
+			err = repo.PrintGitLog(20)
+		default:
+			err = fmt.Errorf("sous-commande git inconnue '%s' (init, push, pull, log)", args[2])
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "encrypt", "decrypt":
+		// Les autres listes suivent la liste courante : aucune ne reste en clair
+		var others []*TodoManager
+		for _, name := range listNames(baseFile, opts.Store) {
+			otherFile, err := listFile(baseFile, name)
+			if err != nil || otherFile == filename {
+				continue
+			}
+			if data, err := ioutil.ReadFile(storeFile(opts.Store, otherFile)); err != nil || isEncrypted(data) == (command == "encrypt") {
+				continue // Autre stockage ou liste déjà convertie
+			}
+			otherLock, err := acquireLock(otherFile+".lock", opts.LockTimeout)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer otherLock.Unlock()
+			otherStore, _ := newStore(opts.Store, otherFile)
+			others = append(others, NewTodoManagerWithStore(otherFile, otherStore))
+		}
+
+		if command == "encrypt" {
+			err = tm.Encrypt(others)
+		} else {
+			err = tm.Decrypt(others)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "snapshots":
+		if err := tm.PrintSnapshots(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "diff":
+		diffFlags := flag.NewFlagSet("diff", flag.ExitOnError)
+		since := diffFlags.String("since", "", "Date ou nom de l'instantané de référence")
+		diffFlags.Parse(args[2:])
+
+		if *since == "" {
+			fmt.Println("❌ Usage: todo diff --since=<instantané|\"2025-07-20 10:00\">")
+			os.Exit(1)
+		}
+		if err := tm.Diff(*since); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "audit":
+		ref := ""
+		if len(args) > 2 {
+			ref = args[2]
+		}
+		if err := tm.Audit(ref); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "undo", "redo":
+		count := 1
+		if len(args) > 2 {
+			count, err = strconv.Atoi(args[2])
+			if err != nil || count < 1 {
+				fmt.Printf("❌ Usage: todo %s [n]\n", command)
+				os.Exit(1)
+			}
+		}
+
+		if command == "undo" {
+			err = tm.Undo(count)
+		} else {
+			err = tm.Redo(count)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+	case "history":
+		if err := tm.PrintHistory(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
 	case "version":
 		fmt.Printf("Todo CLI Go %s\n", version)
 		fmt.Printf("Build time: %s\n", buildTime)
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// currentSchemaVersion version du format de fichier écrite par save()
+const currentSchemaVersion = 4
+
+// errNewerSchema fichier écrit par une version plus récente de todo
+var errNewerSchema = errors.New("schéma plus récent que cette version de todo")
+
+// migration fait passer un document JSON de la version From à From+1
+type migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]interface{}) error
+}
+
+// migrations registre des migrations, dans l'ordre
+var migrations = []migration{
+	{
+		From:        0,
+		Description: "ajout du numéro de version du schéma",
+		Apply: func(doc map[string]interface{}) error {
+			return nil // Le numéro de version est posé par migrateTodoData
+		},
+	},
+	{
+		From:        1,
+		Description: "ajout de la corbeille (trash, tombstones)",
+		Apply: func(doc map[string]interface{}) error {
+			return nil // Champs facultatifs, absents tant que rien n'est supprimé
+		},
+	},
+	{
+		From:        2,
+		Description: "statut des tâches (done: true/false → status: done/todo)",
+		Apply: func(doc map[string]interface{}) error {
+			for _, key := range []string{"tasks", "trash"} {
+				tasks, _ := doc[key].([]interface{})
+				for _, raw := range tasks {
+					task, ok := raw.(map[string]interface{})
+					if !ok {
+						return fmt.Errorf("tâche invalide dans '%s': %v", key, raw)
+					}
+					if _, exists := task["status"]; !exists {
+						task["status"] = StatusTodo
+						if done, _ := task["done"].(bool); done {
+							task["status"] = StatusDone
+						}
+					}
+					delete(task, "done")
+				}
+			}
+			return nil
+		},
+	},
+	{
+		From:        3,
+		Description: "horodatages RFC 3339 avec décalage horaire (created, updated, deleted)",
+		Apply: func(doc map[string]interface{}) error {
+			for _, key := range []string{"tasks", "trash", "tombstones"} {
+				entries, _ := doc[key].([]interface{})
+				for _, raw := range entries {
+					entry, ok := raw.(map[string]interface{})
+					if !ok {
+						return fmt.Errorf("entrée invalide dans '%s': %v", key, raw)
+					}
+					for _, field := range []string{"created", "updated", "deleted"} {
+						value, _ := entry[field].(string)
+						if t, err := parseTimestamp(value); err == nil {
+							entry[field] = timestamp(t)
+						}
+					}
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// schemaVersionOf lit la version de schéma d'un document (0 si absente)
+func schemaVersionOf(doc map[string]interface{}) (int, error) {
+	raw, exists := doc["schemaVersion"]
+	if !exists {
+		return 0, nil
+	}
+	number, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("schemaVersion invalide: %v", raw)
+	}
+	version, err := number.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("schemaVersion invalide: %v", raw)
+	}
+	return int(version), nil
+}
+
+// decodeDocument décode un document JSON en conservant les nombres exacts
+func decodeDocument(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("document JSON vide")
+	}
+	return doc, nil
+}
+
+// pendingMigrations retourne les migrations à appliquer depuis version
+func pendingMigrations(version int) ([]migration, error) {
+	if version > currentSchemaVersion {
+		return nil, fmt.Errorf("%w (fichier v%d, todo v%d)", errNewerSchema, version, currentSchemaVersion)
+	}
+
+	var pending []migration
+	for _, m := range migrations {
+		if m.From >= version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// migrateTodoData met un document à la version courante du schéma.
+// onStep, s'il n'est pas nil, est appelé après chaque étape avec le
+// document avant et après (sauvegarde, description des changements).
+func migrateTodoData(data []byte, onStep func(m migration, before []byte, after []byte) error) ([]byte, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	version, err := schemaVersionOf(doc)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := pendingMigrations(version)
+	if err != nil || len(pending) == 0 {
+		return data, err
+	}
+
+	for _, m := range pending {
+		if err := m.Apply(doc); err != nil {
+			return nil, fmt.Errorf("migration v%d → v%d: %v", m.From, m.From+1, err)
+		}
+		doc["schemaVersion"] = json.Number(fmt.Sprint(m.From + 1))
+
+		migrated, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		if onStep != nil {
+			if err := onStep(m, data, migrated); err != nil {
+				return nil, err
+			}
+		}
+		data = migrated
+	}
+
+	return data, nil
+}
+
+// writeSchemaBackup sauvegarde un document avant migration (filename.vN.bak).
+// La toute première sauvegarde d'une version est conservée.
+func writeSchemaBackup(filename string, version int, data []byte) error {
+	backup := fmt.Sprintf("%s.v%d.bak", filename, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	if err := writeFileAtomic(backup, data, dataFileMode, ""); err != nil {
+		return fmt.Errorf("sauvegarde avant migration v%d: %v", version, err)
+	}
+	return nil
+}
+
+// describeDocChanges résume les différences entre deux versions d'un document
+func describeDocChanges(before []byte, after []byte) []string {
+	oldDoc, err1 := decodeDocument(before)
+	newDoc, err2 := decodeDocument(after)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	var changes []string
+	for _, key := range sortedKeys(oldDoc, newDoc) {
+		if key == "tasks" {
+			continue
+		}
+		oldValue, inOld := oldDoc[key]
+		newValue, inNew := newDoc[key]
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("%s : ajouté (%v)", key, newValue))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("%s : supprimé", key))
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, fmt.Sprintf("%s : %v → %v", key, oldValue, newValue))
+		}
+	}
+
+	// Changements des tâches regroupés par champ
+	oldTasks, _ := oldDoc["tasks"].([]interface{})
+	newTasks, _ := newDoc["tasks"].([]interface{})
+	counts := make(map[string]int)
+	for i := 0; i < len(oldTasks) && i < len(newTasks); i++ {
+		oldTask, _ := oldTasks[i].(map[string]interface{})
+		newTask, _ := newTasks[i].(map[string]interface{})
+		for _, key := range sortedKeys(oldTask, newTask) {
+			oldValue, inOld := oldTask[key]
+			newValue, inNew := newTask[key]
+			switch {
+			case !inOld:
+				counts[fmt.Sprintf("champ '%s' ajouté", key)]++
+			case !inNew:
+				counts[fmt.Sprintf("champ '%s' supprimé", key)]++
+			case !reflect.DeepEqual(oldValue, newValue):
+				counts[fmt.Sprintf("champ '%s' modifié", key)]++
+			}
+		}
+	}
+	for _, change := range sortedKeys(counts) {
+		changes = append(changes, fmt.Sprintf("tâches : %s (%d)", change, counts[change]))
+	}
+	if len(newTasks) != len(oldTasks) {
+		changes = append(changes, fmt.Sprintf("tâches : %d → %d", len(oldTasks), len(newTasks)))
+	}
+
+	return changes
+}
+
+// sortedKeys retourne les clés réunies de plusieurs maps, triées
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Migrate décrit les migrations en attente et les applique sauf en dry-run
+func (tm *TodoManager) Migrate(dryRun bool) error {
+	if _, ok := tm.storage().(*JSONFileStore); !ok {
+		return fmt.Errorf("migrate ne s'applique qu'au stockage json (les autres sont migrés au chargement)")
+	}
+
+	data, err := readDataFile(tm.filename)
+	if os.IsNotExist(err) {
+		fmt.Println("📝 Aucun fichier de tâches, rien à migrer")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return fmt.Errorf("%s illisible (%v), lancez 'todo doctor'", tm.filename, err)
+	}
+	version, err := schemaVersionOf(doc)
+	if err != nil {
+		return err
+	}
+	pending, err := pendingMigrations(version)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Printf("✅ %s est à jour (schéma v%d)\n", tm.filename, version)
+		return nil
+	}
+
+	fmt.Printf("📦 %s : schéma v%d → v%d\n", tm.filename, version, currentSchemaVersion)
+	_, err = migrateTodoData(data, func(m migration, before []byte, after []byte) error {
+		fmt.Printf("\n🔄 v%d → v%d : %s\n", m.From, m.From+1, m.Description)
+		for _, change := range describeDocChanges(before, after) {
+			fmt.Printf("  - %s\n", change)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Println("\n🔍 Mode dry-run: Aucune modification effectuée")
+		return nil
+	}
+
+	// Le chargement a déjà migré les données en mémoire et sauvegardé l'original
+	if err := tm.save(); err != nil {
+		return err
+	}
+	fmt.Printf("\n✅ Migration terminée (sauvegarde : %s.v%d.bak)\n", tm.filename, version)
+	return nil
+}
@@ -0,0 +1,130 @@
+// migrate_test.go - Tests des migrations de schéma
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const legacyTodoJSON = `{
+  "tasks": [
+    {"id": 1, "uuid": "a1b2c3d4-e5f6-4789-8abc-def012345678", "text": "Ancienne tâche", "done": false,
+     "priority": "high", "due": "2025-07-20", "tags": ["+dev"],
+     "created": "2025-07-09 10:00:00", "updated": "2025-07-09 10:00:00"}
+  ],
+  "nextId": 2
+}`
+
+func TestMigrationRegistry(t *testing.T) {
+	if len(migrations) != currentSchemaVersion {
+		t.Fatalf("%d migrations pour le schéma v%d", len(migrations), currentSchemaVersion)
+	}
+	for i, m := range migrations {
+		if m.From != i {
+			t.Errorf("Migration %d part de v%d, attendu v%d", i, m.From, i)
+		}
+		if m.Description == "" || m.Apply == nil {
+			t.Errorf("Migration v%d incomplète", m.From)
+		}
+	}
+}
+
+func TestMigrateTodoData(t *testing.T) {
+	t.Run("fichier sans version", func(t *testing.T) {
+		steps := 0
+		data, err := migrateTodoData([]byte(legacyTodoJSON), func(m migration, before []byte, after []byte) error {
+			steps++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Erreur de migration: %v", err)
+		}
+		if steps != currentSchemaVersion {
+			t.Errorf("Étapes attendues: %d, obtenues: %d", currentSchemaVersion, steps)
+		}
+
+		doc, _ := decodeDocument(data)
+		if version, _ := schemaVersionOf(doc); version != currentSchemaVersion {
+			t.Errorf("Version après migration: %d", version)
+		}
+	})
+
+	t.Run("fichier à jour inchangé", func(t *testing.T) {
+		tm := &TodoManager{Tasks: []Task{}, NextID: 1}
+		current, _ := encodeTodoData(tm)
+		data, err := migrateTodoData(current, func(m migration, before []byte, after []byte) error {
+			t.Error("Aucune étape attendue")
+			return nil
+		})
+		if err != nil || string(data) != string(current) {
+			t.Errorf("Données modifiées ou erreur: %v", err)
+		}
+	})
+
+	t.Run("schéma plus récent refusé", func(t *testing.T) {
+		_, err := migrateTodoData([]byte(`{"schemaVersion": 999, "tasks": []}`), nil)
+		if !errors.Is(err, errNewerSchema) {
+			t.Errorf("errNewerSchema attendue, obtenu: %v", err)
+		}
+	})
+}
+
+func TestJSONFileStore_Migration(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := ioutil.WriteFile(tm.filename, []byte(legacyTodoJSON), 0644); err != nil {
+		t.Fatalf("Impossible d'écrire le fichier: %v", err)
+	}
+
+	t.Run("dry-run", func(t *testing.T) {
+		if err := tm.Migrate(true); err != nil {
+			t.Fatalf("Erreur: %v", err)
+		}
+		content, _ := ioutil.ReadFile(tm.filename)
+		if string(content) != legacyTodoJSON {
+			t.Error("Le dry-run ne doit pas modifier le fichier")
+		}
+	})
+
+	t.Run("chargement et sauvegarde", func(t *testing.T) {
+		if err := tm.load(); err != nil {
+			t.Fatalf("Erreur de chargement: %v", err)
+		}
+		assertTaskCount(t, tm, 1)
+
+		if _, err := os.Stat(tm.filename + ".v0.bak"); !os.IsNotExist(err) {
+			t.Error("La lecture seule ne doit rien écrire")
+		}
+
+		if err := tm.Migrate(false); err != nil {
+			t.Fatalf("Erreur de migration: %v", err)
+		}
+
+		backup, err := ioutil.ReadFile(tm.filename + ".v0.bak")
+		if err != nil || string(backup) != legacyTodoJSON {
+			t.Errorf("Sauvegarde avant migration absente ou différente: %v", err)
+		}
+		content, _ := ioutil.ReadFile(tm.filename)
+		if !strings.Contains(string(content), fmt.Sprintf(`"schemaVersion": %d`, currentSchemaVersion)) {
+			t.Errorf("schemaVersion absent du fichier migré: %s", content)
+		}
+	})
+
+	t.Run("fichier plus récent préservé", func(t *testing.T) {
+		newer := `{"schemaVersion": 999, "tasks": [], "nextId": 1}`
+		ioutil.WriteFile(tm.filename, []byte(newer), 0644)
+
+		tm2 := newTestManager(tm.filename, nil)
+		if err := tm2.load(); !errors.Is(err, errNewerSchema) {
+			t.Fatalf("errNewerSchema attendue, obtenu: %v", err)
+		}
+		if _, err := os.Stat(tm.filename); err != nil {
+			t.Errorf("Le fichier plus récent ne doit pas être déplacé: %v", err)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+// naturaldate.go - Dates relatives et en langage naturel (today, demain, friday, +3d, eom…)
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clock heure courante utilisée pour interpréter les dates relatives
+// (remplacée dans les tests pour des résultats déterministes)
+var clock = time.Now
+
+// dateKeywords mots-clés relatifs au jour courant (anglais et français)
+var dateKeywords = map[string]func(today time.Time) time.Time{
+	"today":             func(today time.Time) time.Time { return today },
+	"aujourd'hui":       func(today time.Time) time.Time { return today },
+	"auj":               func(today time.Time) time.Time { return today },
+	"tomorrow":          func(today time.Time) time.Time { return today.AddDate(0, 0, 1) },
+	"demain":            func(today time.Time) time.Time { return today.AddDate(0, 0, 1) },
+	"après-demain":      func(today time.Time) time.Time { return today.AddDate(0, 0, 2) },
+	"apres-demain":      func(today time.Time) time.Time { return today.AddDate(0, 0, 2) },
+	"yesterday":         func(today time.Time) time.Time { return today.AddDate(0, 0, -1) },
+	"hier":              func(today time.Time) time.Time { return today.AddDate(0, 0, -1) },
+	"eow":               endOfWeek,
+	"fin de semaine":    endOfWeek,
+	"eom":               endOfMonth,
+	"fin du mois":       endOfMonth,
+	"eoy":               func(today time.Time) time.Time { return time.Date(today.Year(), 12, 31, 0, 0, 0, 0, today.Location()) },
+	"fin d'année":       func(today time.Time) time.Time { return time.Date(today.Year(), 12, 31, 0, 0, 0, 0, today.Location()) },
+	"next week":         nextMonday,
+	"semaine prochaine": nextMonday,
+	"next month":        func(today time.Time) time.Time { return endOfMonth(today).AddDate(0, 0, 1) },
+	"mois prochain":     func(today time.Time) time.Time { return endOfMonth(today).AddDate(0, 0, 1) },
+}
+
+// dateUnit durée d'une unité de +3d, in 2 weeks, dans 3 jours
+type dateUnit struct {
+	months, days int
+}
+
+// dateUnits unités acceptées (anglais et français)
+var dateUnits = map[string]dateUnit{
+	"d": {0, 1}, "day": {0, 1}, "days": {0, 1}, "j": {0, 1}, "jour": {0, 1}, "jours": {0, 1},
+	"w": {0, 7}, "week": {0, 7}, "weeks": {0, 7}, "sem": {0, 7}, "semaine": {0, 7}, "semaines": {0, 7},
+	"m": {1, 0}, "month": {1, 0}, "months": {1, 0}, "mois": {1, 0},
+	"y": {12, 0}, "year": {12, 0}, "years": {12, 0}, "an": {12, 0}, "ans": {12, 0},
+}
+
+var (
+	offsetPattern    = regexp.MustCompile(`^([+-])(\d+)\s*(\pL+)$`)
+	inPattern        = regexp.MustCompile(`^(?:in|dans)\s+(\d+)\s*(\pL+)$`)
+	timeOfDayPattern = regexp.MustCompile(`^(.+?)\s+(?:à\s+|at\s+)?(\d{1,2})(?::(\d{2})|h(\d{2})?)$`)
+)
+
+// endOfWeek retourne le dimanche de la semaine (aujourd'hui si dimanche)
+func endOfWeek(today time.Time) time.Time {
+	return today.AddDate(0, 0, (7-int(today.Weekday()))%7)
+}
+
+// endOfMonth retourne le dernier jour du mois
+func endOfMonth(today time.Time) time.Time {
+	return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, today.Location())
+}
+
+// nextMonday retourne le lundi de la semaine suivante
+func nextMonday(today time.Time) time.Time {
+	return nextWeekday(today, time.Monday)
+}
+
+// nextWeekday retourne le prochain jour donné, strictement après today
+func nextWeekday(today time.Time, day time.Weekday) time.Time {
+	offset := (int(day) - int(today.Weekday()) + 7) % 7
+	if offset == 0 {
+		offset = 7
+	}
+	return today.AddDate(0, 0, offset)
+}
+
+// relativeDay interprète une expression sans heure par rapport à today
+func relativeDay(expr string, today time.Time) (time.Time, bool) {
+	if keyword, ok := dateKeywords[expr]; ok {
+		return keyword(today), true
+	}
+
+	// friday, vendredi (aujourd'hui compris) ; next friday, vendredi prochain
+	if day, ok := weekdayNames[expr]; ok {
+		return today.AddDate(0, 0, (int(day)-int(today.Weekday())+7)%7), true
+	}
+	if name, ok := strings.CutPrefix(expr, "next "); ok {
+		if day, ok := weekdayNames[name]; ok {
+			return nextWeekday(today, day), true
+		}
+	}
+	if name, ok := strings.CutSuffix(expr, " prochain"); ok {
+		if day, ok := weekdayNames[name]; ok {
+			return nextWeekday(today, day), true
+		}
+	}
+
+	// +3d, -1w ; in 2 weeks, dans 3 jours
+	sign, count, unitName := "+", "", ""
+	if m := offsetPattern.FindStringSubmatch(expr); m != nil {
+		sign, count, unitName = m[1], m[2], m[3]
+	} else if m := inPattern.FindStringSubmatch(expr); m != nil {
+		count, unitName = m[1], m[2]
+	} else {
+		return time.Time{}, false
+	}
+	unit, ok := dateUnits[unitName]
+	n, err := strconv.Atoi(count)
+	if !ok || err != nil {
+		return time.Time{}, false
+	}
+	if sign == "-" {
+		n = -n
+	}
+	return today.AddDate(0, unit.months*n, unit.days*n), true
+}
+
+// parseDate interprète une date saisie : format absolu (voir parseDue) ou
+// expression relative à now (today, demain, friday, next week, +3d, eom,
+// in 2 weeks…), suivie d'une heure facultative ("demain 14h", "friday 9:30").
+func parseDate(value string, now time.Time) (due time.Time, hasTime bool, err error) {
+	value = strings.TrimSpace(value)
+	if due, hasTime, err := parseDue(value, now.Location()); err == nil {
+		return due, hasTime, nil
+	}
+
+	expr := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	hour, minute := 0, 0
+	if m := timeOfDayPattern.FindStringSubmatch(expr); m != nil {
+		hour, _ = strconv.Atoi(m[2])
+		minute, _ = strconv.Atoi(m[3] + m[4])
+		if hour > 23 || minute > 59 {
+			return time.Time{}, false, fmt.Errorf("heure invalide dans '%s'", value)
+		}
+		expr, hasTime = m[1], true
+	}
+
+	day, ok := relativeDay(expr, startOfDay(now))
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("date '%s' invalide (ex: 2025-07-20, demain, friday, +3d, in 2 weeks)", value)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()), hasTime, nil
+}
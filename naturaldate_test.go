@@ -0,0 +1,114 @@
+// naturaldate_test.go - Tests des dates relatives
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// Mercredi 16 juillet 2025, 10h
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"2025-07-20", "2025-07-20", false},
+		{"today", "2025-07-16", false},
+		{"Demain", "2025-07-17", false},
+		{"après-demain", "2025-07-18", false},
+		{"hier", "2025-07-15", false},
+		{"friday", "2025-07-18", false},
+		{"vendredi", "2025-07-18", false},
+		{"wednesday", "2025-07-16", false},
+		{"next wednesday", "2025-07-23", false},
+		{"mercredi prochain", "2025-07-23", false},
+		{"next week", "2025-07-21", false},
+		{"semaine prochaine", "2025-07-21", false},
+		{"next month", "2025-08-01", false},
+		{"eow", "2025-07-20", false},
+		{"eom", "2025-07-31", false},
+		{"fin du mois", "2025-07-31", false},
+		{"eoy", "2025-12-31", false},
+		{"+3d", "2025-07-19", false},
+		{"+2w", "2025-07-30", false},
+		{"+1m", "2025-08-16", false},
+		{"-1d", "2025-07-15", false},
+		{"in 2 weeks", "2025-07-30", false},
+		{"dans  3 jours", "2025-07-19", false},
+		{"demain 14h", "2025-07-17 14:00", false},
+		{"friday 9:30", "2025-07-18 09:30", false},
+		{"tomorrow at 8h15", "2025-07-17 08:15", false},
+		{"demain 25h", "", true},
+		{"bientôt", "", true},
+		{"+3x", "", true},
+		{"in weeks", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			due, hasTime, err := parseDate(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseDate(%q) devrait échouer, obtenu %s", tt.value, due)
+				}
+				return
+			}
+			got := due.Format("2006-01-02")
+			if hasTime {
+				got = due.Format("2006-01-02 15:04")
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseDate(%q) = %q, %v ; attendu %q", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDue_Clock(t *testing.T) {
+	defer func(saved func() time.Time) { clock = saved }(clock)
+	clock = func() time.Time { return time.Date(2025, 1, 31, 9, 0, 0, 0, time.Local) }
+
+	if due, err := normalizeDue("eom"); err != nil || due != "2025-01-31" {
+		t.Errorf("normalizeDue(eom) = %q, %v", due, err)
+	}
+	if !validateDate("demain") || validateDate("un jour") {
+		t.Error("validateDate doit accepter les dates relatives et refuser le reste")
+	}
+	if validStoredDate("demain") {
+		t.Error("Une date relative enregistrée telle quelle est invalide")
+	}
+}
+
+func TestUpdate_Dates(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Rapport", []string{"+travail"}, "", "2025-07-20")
+	tm.Update(1, TaskEdit{Due: dateNone, Wait: "2025-07-18"})
+
+	task := assertTaskExists(t, reloadManager(t, tm.filename), 1)
+	if task.Due != "" || task.Wait != "2025-07-18" {
+		t.Errorf("Dates modifiées attendues: %+v", task)
+	}
+	if task.Text != "Rapport" || len(task.Tags) != 1 {
+		t.Errorf("Le texte et les tags doivent être conservés sans nouveau texte: %+v", task)
+	}
+}
+
+func TestFilter_DueBy(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Due: "2025-07-10"},
+		{ID: 2, Due: "2025-07-18T17:00:00+02:00"},
+		{ID: 3, Due: "2025-07-25"},
+		{ID: 4},
+	}
+	filter := TaskFilter{ShowDone: true, DueBy: time.Date(2025, 7, 18, 0, 0, 0, 0, time.Local)}
+
+	got := filterTaskList(tasks, filter)
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 2 {
+		t.Errorf("Échéances d'ici le 18 attendues (retards compris): %+v", got)
+	}
+}
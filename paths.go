@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Ordre de recherche du fichier de tâches :
+//
+//  1. option globale --file
+//  2. $TODO_FILE
+//  3. fichier du projet courant (.todo/todo.json ou .todo.json)
+//  4. $TODO_DIR/todo.json
+//  5. ~/.todo/todo.json si ce répertoire existe (ancien emplacement)
+//  6. $XDG_DATA_HOME/todo/todo.json (défaut: ~/.local/share/todo/todo.json)
+
+// legacyDataDir retourne l'ancien répertoire de données (~/.todo)
+func legacyDataDir(homeDir string) string {
+	return filepath.Join(homeDir, ".todo")
+}
+
+// xdgDir retourne $envVar s'il désigne un chemin absolu, sinon ~/fallback
+func xdgDir(envVar string, homeDir string, fallback string) (string, error) {
+	if dir := os.Getenv(envVar); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	if homeDir == "" {
+		return "", fmt.Errorf("répertoire personnel introuvable et $%s non défini", envVar)
+	}
+	return filepath.Join(homeDir, fallback), nil
+}
+
+// xdgDataDir retourne le répertoire de données XDG de todo
+func xdgDataDir(homeDir string) (string, error) {
+	dir, err := xdgDir("XDG_DATA_HOME", homeDir, filepath.Join(".local", "share"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todo"), nil
+}
+
+// resolveDataFile choisit le fichier de tâches selon l'ordre ci-dessus.
+// Retourne aussi l'origine du choix, affichée par --verbose.
+func resolveDataFile(explicit string) (string, string, error) {
+	if explicit != "" {
+		return prepareDataFile(explicit, "option --file")
+	}
+	if file := os.Getenv("TODO_FILE"); file != "" {
+		return prepareDataFile(file, "$TODO_FILE")
+	}
+
+	homeDir, _ := os.UserHomeDir()
+
+	if cwd, err := os.Getwd(); err == nil {
+		if project := findProjectFile(cwd, homeDir); project != "" {
+			return project, "projet", nil
+		}
+	}
+
+	if dir := os.Getenv("TODO_DIR"); dir != "" {
+		return prepareDataFile(filepath.Join(dir, "todo.json"), "$TODO_DIR")
+	}
+
+	if homeDir != "" {
+		legacy := legacyDataDir(homeDir)
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			return filepath.Join(legacy, "todo.json"), "global", nil
+		}
+	}
+
+	dataDir, err := xdgDataDir(homeDir)
+	if err != nil {
+		return "", "", fmt.Errorf("%v : définissez $TODO_FILE ou $TODO_DIR", err)
+	}
+	return prepareDataFile(filepath.Join(dataDir, "todo.json"), "global")
+}
+
+// prepareDataFile rend le chemin absolu et crée son répertoire
+func prepareDataFile(filename string, source string) (string, string, error) {
+	filename, err := filepath.Abs(expandHome(filename))
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", "", err
+	}
+	return filename, source, nil
+}
+
+// MigrateLegacyData déplace ~/.todo vers le répertoire de données XDG.
+// Le verrou de l'ancien fichier est tenu pendant le déplacement et n'est pas déplacé.
+func MigrateLegacyData(homeDir string, lockTimeout time.Duration) (string, error) {
+	legacy := legacyDataDir(homeDir)
+	if _, err := os.Stat(filepath.Join(legacy, "todo.json")); err != nil {
+		return "", fmt.Errorf("aucune donnée à migrer dans %s", legacy)
+	}
+
+	dataDir, err := xdgDataDir(homeDir)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "todo.json")); err == nil {
+		return "", fmt.Errorf("%s existe déjà : migration annulée", filepath.Join(dataDir, "todo.json"))
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return "", err
+	}
+
+	lockName := filepath.Join(legacy, "todo.json.lock")
+	lock, err := acquireLock(lockName, lockTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := ioutil.ReadDir(legacy)
+	if err != nil {
+		lock.Unlock()
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.Name() == filepath.Base(lockName) {
+			continue
+		}
+		if err := moveEntry(filepath.Join(legacy, entry.Name()), filepath.Join(dataDir, entry.Name())); err != nil {
+			lock.Unlock()
+			return "", err
+		}
+	}
+
+	lock.Unlock()
+	os.Remove(lockName)
+	os.Remove(legacy) // Laissé en place s'il contient encore des fichiers
+	return filepath.Join(dataDir, "todo.json"), nil
+}
+
+// moveEntry déplace un fichier ou un répertoire, par copie si le renommage
+// est impossible (autre système de fichiers)
+func moveEntry(src string, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s existe déjà", dst)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copie récursivement un fichier ou un répertoire
+func copyTree(src string, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return copyFile(src, dst)
+	}
+
+	if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
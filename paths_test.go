@@ -0,0 +1,145 @@
+// paths_test.go - Tests de l'emplacement du fichier de tâches
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setPathEnv isole les variables d'environnement utilisées pour choisir le fichier
+func setPathEnv(t *testing.T, home string) {
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, name := range []string{"TODO_FILE", "TODO_DIR", "XDG_DATA_HOME", "XDG_CONFIG_HOME"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestResolveDataFile(t *testing.T) {
+	home := t.TempDir()
+	setPathEnv(t, home)
+
+	workDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	os.Chdir(workDir)
+	defer os.Chdir(oldDir)
+
+	check := func(t *testing.T, explicit string, wantFile string, wantSource string) {
+		t.Helper()
+		got, source, err := resolveDataFile(explicit)
+		if err != nil {
+			t.Fatalf("resolveDataFile: %v", err)
+		}
+		if got != wantFile || source != wantSource {
+			t.Errorf("Attendu %s (%s), obtenu %s (%s)", wantFile, wantSource, got, source)
+		}
+	}
+
+	t.Run("XDG par défaut", func(t *testing.T) {
+		check(t, "", filepath.Join(home, ".local", "share", "todo", "todo.json"), "global")
+	})
+
+	t.Run("XDG_DATA_HOME", func(t *testing.T) {
+		dataHome := filepath.Join(home, "data")
+		t.Setenv("XDG_DATA_HOME", dataHome)
+		check(t, "", filepath.Join(dataHome, "todo", "todo.json"), "global")
+
+		t.Setenv("XDG_DATA_HOME", "relatif")
+		check(t, "", filepath.Join(home, ".local", "share", "todo", "todo.json"), "global")
+	})
+
+	t.Run("ancien répertoire conservé", func(t *testing.T) {
+		os.Mkdir(filepath.Join(home, ".todo"), 0755)
+		defer os.RemoveAll(filepath.Join(home, ".todo"))
+		check(t, "", filepath.Join(home, ".todo", "todo.json"), "global")
+	})
+
+	t.Run("TODO_DIR", func(t *testing.T) {
+		dir := filepath.Join(home, "perso")
+		t.Setenv("TODO_DIR", dir)
+		check(t, "", filepath.Join(dir, "todo.json"), "$TODO_DIR")
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("Le répertoire devrait être créé: %v", err)
+		}
+	})
+
+	t.Run("TODO_FILE et --file", func(t *testing.T) {
+		envFile := filepath.Join(home, "env.json")
+		t.Setenv("TODO_FILE", envFile)
+		check(t, "", envFile, "$TODO_FILE")
+		check(t, "autre.json", filepath.Join(workDir, "autre.json"), "option --file")
+	})
+
+	t.Run("le projet passe avant TODO_DIR", func(t *testing.T) {
+		t.Setenv("TODO_DIR", filepath.Join(home, "perso"))
+		project, err := InitProject(workDir)
+		if err != nil {
+			t.Fatalf("InitProject: %v", err)
+		}
+		defer os.RemoveAll(filepath.Join(workDir, ".todo"))
+
+		got, _, _ := resolveDataFile("")
+		if !sameDir(filepath.Dir(got), filepath.Dir(project)) {
+			t.Errorf("Fichier de projet attendu %s, obtenu %s", project, got)
+		}
+	})
+}
+
+func TestDefaultConfigFile(t *testing.T) {
+	home := t.TempDir()
+	setPathEnv(t, home)
+
+	if got, want := defaultConfigFile(), filepath.Join(home, ".config", "todo", "config"); got != want {
+		t.Errorf("Attendu %s, obtenu %s", want, got)
+	}
+
+	configHome := filepath.Join(home, "cfg")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	if got, want := defaultConfigFile(), filepath.Join(configHome, "todo", "config"); got != want {
+		t.Errorf("Attendu %s, obtenu %s", want, got)
+	}
+}
+
+func TestMigrateLegacyData(t *testing.T) {
+	home := t.TempDir()
+	setPathEnv(t, home)
+
+	if _, err := MigrateLegacyData(home, defaultLockTimeout); err == nil {
+		t.Error("La migration sans données devrait échouer")
+	}
+
+	legacy := filepath.Join(home, ".todo")
+	os.MkdirAll(filepath.Join(legacy, "lists"), 0755)
+	ioutil.WriteFile(filepath.Join(legacy, "todo.json"), []byte(`{"tasks":[],"nextId":1}`), 0644)
+	ioutil.WriteFile(filepath.Join(legacy, "lists", "work.json"), []byte(`{"tasks":[],"nextId":1}`), 0644)
+
+	filename, err := MigrateLegacyData(home, defaultLockTimeout)
+	if err != nil {
+		t.Fatalf("MigrateLegacyData: %v", err)
+	}
+
+	dataDir := filepath.Join(home, ".local", "share", "todo")
+	if filename != filepath.Join(dataDir, "todo.json") {
+		t.Errorf("Nouveau fichier inattendu: %s", filename)
+	}
+	for _, moved := range []string{"todo.json", filepath.Join("lists", "work.json")} {
+		if _, err := os.Stat(filepath.Join(dataDir, moved)); err != nil {
+			t.Errorf("%s devrait être déplacé: %v", moved, err)
+		}
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("L'ancien répertoire devrait être supprimé: %v", err)
+	}
+
+	if got, _, _ := resolveDataFile(""); got != filename {
+		t.Errorf("Le nouvel emplacement devrait être utilisé, obtenu %s", got)
+	}
+
+	os.MkdirAll(legacy, 0755)
+	ioutil.WriteFile(filepath.Join(legacy, "todo.json"), []byte(`{}`), 0644)
+	if _, err := MigrateLegacyData(home, defaultLockTimeout); err == nil {
+		t.Error("La migration ne doit pas écraser un fichier existant")
+	}
+}
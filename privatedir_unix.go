@@ -0,0 +1,21 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateOwner vérifie qu'un répertoire appartient à l'utilisateur
+// courant et lui est réservé (0700)
+func checkPrivateOwner(dir string, info os.FileInfo) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s appartient à un autre utilisateur (uid %d)", dir, stat.Uid)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		return fmt.Errorf("%s est accessible à d'autres utilisateurs (%04o au lieu de 0700)", dir, perm)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+)
+
+// checkPrivateOwner sans effet : le répertoire temporaire de Windows est
+// propre à chaque utilisateur et les droits Unix n'y ont pas de sens
+func checkPrivateOwner(dir string, info os.FileInfo) error {
+	return nil
+}
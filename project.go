@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// projectGitignore contenu du .gitignore créé par todo init
+const projectGitignore = `# Fichiers techniques de todo (le fichier de tâches, lui, est versionné)
+*.lock
+*.bak
+*.tmp
+*.corrupt-*
+*.history
+snapshots/
+`
+
+// findProjectFile cherche un fichier de tâches de projet en remontant depuis dir,
+// à la manière de git. La recherche s'arrête au répertoire personnel, dont le
+// fichier .todo/todo.json est le fichier global et non un fichier de projet.
+func findProjectFile(dir string, homeDir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if sameDir(dir, homeDir) {
+			return ""
+		}
+
+		for _, candidate := range []string{
+			filepath.Join(dir, ".todo", "todo.json"),
+			filepath.Join(dir, ".todo.json"),
+		} {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// sameDir indique si deux chemins désignent le même répertoire (liens compris)
+func sameDir(a string, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// InitProject crée une liste de tâches propre au projet dans dir
+func InitProject(dir string) (string, error) {
+	homeDir, _ := os.UserHomeDir()
+	if homeDir != "" && sameDir(dir, homeDir) {
+		return "", fmt.Errorf("le répertoire personnel utilise déjà la liste globale ~/.todo")
+	}
+
+	for _, existing := range []string{
+		filepath.Join(dir, ".todo", "todo.json"),
+		filepath.Join(dir, ".todo.json"),
+	} {
+		if _, err := os.Stat(existing); err == nil {
+			return "", fmt.Errorf("liste de projet déjà présente : %s", existing)
+		}
+	}
+
+	todoDir := filepath.Join(dir, ".todo")
+	if err := os.MkdirAll(todoDir, 0755); err != nil {
+		return "", err
+	}
+
+	filename := filepath.Join(todoDir, "todo.json")
+	tm := &TodoManager{Tasks: []Task{}, NextID: 1, filename: filename}
+	if err := tm.save(); err != nil {
+		return "", err
+	}
+
+	gitignore := filepath.Join(todoDir, ".gitignore")
+	if _, err := os.Stat(gitignore); os.IsNotExist(err) {
+		ioutil.WriteFile(gitignore, []byte(projectGitignore), 0644)
+	}
+
+	return filename, nil
+}
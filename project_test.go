@@ -0,0 +1,61 @@
+// project_test.go - Tests des listes de projet
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	project := filepath.Join(home, "code", "projet")
+	nested := filepath.Join(project, "src", "pkg")
+	os.MkdirAll(nested, 0755)
+	os.MkdirAll(filepath.Join(home, ".todo"), 0755)
+	ioutil.WriteFile(filepath.Join(home, ".todo", "todo.json"), []byte(`{}`), 0644)
+
+	t.Run("aucun projet", func(t *testing.T) {
+		if got := findProjectFile(nested, home); got != "" {
+			t.Errorf("Le fichier global ne doit pas être pris pour un projet: %s", got)
+		}
+	})
+
+	t.Run("fichier .todo.json", func(t *testing.T) {
+		want := filepath.Join(project, ".todo.json")
+		ioutil.WriteFile(want, []byte(`{}`), 0644)
+		defer os.Remove(want)
+
+		if got := findProjectFile(nested, home); got != want {
+			t.Errorf("Attendu %s, obtenu %s", want, got)
+		}
+	})
+
+	t.Run("répertoire .todo le plus proche", func(t *testing.T) {
+		if _, err := InitProject(project); err != nil {
+			t.Fatalf("InitProject: %v", err)
+		}
+		inner := filepath.Join(project, "src")
+		if _, err := InitProject(inner); err != nil {
+			t.Fatalf("InitProject: %v", err)
+		}
+
+		want := filepath.Join(inner, ".todo", "todo.json")
+		if got := findProjectFile(nested, home); got != want {
+			t.Errorf("Attendu %s, obtenu %s", want, got)
+		}
+		if _, err := InitProject(inner); err == nil {
+			t.Error("Un second init devrait échouer")
+		}
+	})
+
+	t.Run("hors du répertoire personnel", func(t *testing.T) {
+		outside := filepath.Join(root, "ailleurs")
+		os.MkdirAll(outside, 0755)
+		if got := findProjectFile(outside, home); got != "" {
+			t.Errorf("Aucun projet attendu, obtenu %s", got)
+		}
+	})
+}
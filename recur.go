@@ -0,0 +1,261 @@
+// recur.go - Tâches récurrentes : règles de récurrence et occurrences suivantes
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types de récurrence
+const (
+	RecurDaily   = "daily"   // Tous les jours
+	RecurWeekly  = "weekly"  // Certains jours de la semaine (weekly:mon,thu)
+	RecurMonthly = "monthly" // Un jour du mois (monthly:15)
+	RecurAfter   = "after"   // N jours après la complétion (after:3d)
+)
+
+// weekdayNames noms des jours acceptés (anglais et français)
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "dim": time.Sunday, "dimanche": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "lun": time.Monday, "lundi": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "mar": time.Tuesday, "mardi": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "mer": time.Wednesday, "mercredi": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "jeu": time.Thursday, "jeudi": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "ven": time.Friday, "vendredi": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "sam": time.Saturday, "samedi": time.Saturday,
+}
+
+// Recurrence règle de récurrence d'une tâche
+type Recurrence struct {
+	Kind     string
+	Weekdays []time.Weekday // weekly : jours de la semaine
+	Day      int            // monthly : jour du mois (ramené au dernier jour si besoin)
+	Days     int            // after : jours entre la complétion et l'échéance suivante
+}
+
+// parseRecurrence analyse une règle : daily, weekly[:mon,thu], monthly[:15]
+// ou after:3d (aussi after:2w). Sans précision, weekly et monthly reprennent
+// le jour de l'échéance (voir Task.setRecurrence).
+func parseRecurrence(rule string) (Recurrence, error) {
+	kind, arg, _ := strings.Cut(strings.ToLower(strings.TrimSpace(rule)), ":")
+	r := Recurrence{Kind: kind}
+
+	switch kind {
+	case RecurDaily:
+		if arg != "" {
+			return r, fmt.Errorf("récurrence '%s' invalide (daily sans paramètre)", rule)
+		}
+	case RecurWeekly:
+		for _, name := range strings.Split(arg, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			day, ok := weekdayNames[name]
+			if !ok {
+				return r, fmt.Errorf("jour '%s' inconnu dans '%s' (ex: weekly:mon,thu)", name, rule)
+			}
+			r.Weekdays = append(r.Weekdays, day)
+		}
+	case RecurMonthly:
+		if arg != "" {
+			day, err := strconv.Atoi(arg)
+			if err != nil || day < 1 || day > 31 {
+				return r, fmt.Errorf("jour du mois '%s' invalide (1 à 31)", arg)
+			}
+			r.Day = day
+		}
+	case RecurAfter:
+		days, err := parseDays(arg)
+		if err != nil {
+			return r, fmt.Errorf("récurrence '%s' invalide (ex: after:3d, after:2w)", rule)
+		}
+		r.Days = days
+	default:
+		return r, fmt.Errorf("récurrence '%s' inconnue (daily, weekly:mon,thu, monthly:15, after:3d)", rule)
+	}
+	return r, nil
+}
+
+// parseDays convertit "3d", "2w" ou "3" en nombre de jours (strictement positif)
+func parseDays(value string) (int, error) {
+	unit := 1
+	switch {
+	case strings.HasSuffix(value, "d"):
+		value = strings.TrimSuffix(value, "d")
+	case strings.HasSuffix(value, "w"):
+		value, unit = strings.TrimSuffix(value, "w"), 7
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("durée '%s' invalide", value)
+	}
+	return n * unit, nil
+}
+
+// String forme canonique de la règle, telle qu'enregistrée dans Task.Recur
+func (r Recurrence) String() string {
+	switch r.Kind {
+	case RecurWeekly:
+		var names []string
+		for _, day := range r.Weekdays {
+			names = append(names, strings.ToLower(day.String()[:3]))
+		}
+		if len(names) == 0 {
+			return r.Kind
+		}
+		return r.Kind + ":" + strings.Join(names, ",")
+	case RecurMonthly:
+		if r.Day == 0 {
+			return r.Kind
+		}
+		return fmt.Sprintf("%s:%d", r.Kind, r.Day)
+	case RecurAfter:
+		return fmt.Sprintf("%s:%dd", r.Kind, r.Days)
+	default:
+		return r.Kind
+	}
+}
+
+// after retourne la première échéance strictement postérieure au jour base
+// (daily, weekly, monthly)
+func (r Recurrence) after(base time.Time) time.Time {
+	switch r.Kind {
+	case RecurWeekly:
+		weekdays := r.Weekdays
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{base.Weekday()}
+		}
+		for day := base.AddDate(0, 0, 1); ; day = day.AddDate(0, 0, 1) {
+			for _, weekday := range weekdays {
+				if day.Weekday() == weekday {
+					return day
+				}
+			}
+		}
+	case RecurMonthly:
+		for month := 0; ; month++ {
+			first := time.Date(base.Year(), base.Month()+time.Month(month), 1, 0, 0, 0, 0, base.Location())
+			day := r.Day
+			if day == 0 {
+				day = base.Day()
+			}
+			if last := first.AddDate(0, 1, -1).Day(); day > last {
+				day = last
+			}
+			if candidate := first.AddDate(0, 0, day-1); candidate.After(base) {
+				return candidate
+			}
+		}
+	default:
+		return base.AddDate(0, 0, 1)
+	}
+}
+
+// startOfDay retourne le jour de t à minuit
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// setRecurrence applique une règle à une nouvelle tâche et démarre une série.
+// weekly et monthly sans précision prennent le jour de l'échéance (ou
+// d'aujourd'hui) ; sans échéance, la première occurrence est calculée.
+func (task *Task) setRecurrence(rule string, now time.Time) error {
+	r, err := parseRecurrence(rule)
+	if err != nil {
+		return err
+	}
+
+	anchor := startOfDay(now)
+	if task.Due != "" {
+		due, _, err := parseDue(task.Due, now.Location())
+		if err != nil {
+			return fmt.Errorf("date limite '%s' invalide", task.Due)
+		}
+		anchor = startOfDay(due)
+	}
+	if r.Kind == RecurWeekly && len(r.Weekdays) == 0 {
+		r.Weekdays = []time.Weekday{anchor.Weekday()}
+	}
+	if r.Kind == RecurMonthly && r.Day == 0 {
+		r.Day = anchor.Day()
+	}
+	if task.Due == "" && r.Kind != RecurAfter {
+		task.Due = r.after(anchor.AddDate(0, 0, -1)).Format("2006-01-02")
+	}
+
+	task.Recur = r.String()
+	task.Series = generateUUID()
+	return nil
+}
+
+// nextOccurrence prépare l'occurrence qui suit une tâche récurrente terminée
+// le jour completed. L'échéance suivante part de l'échéance actuelle, ou
+// d'aujourd'hui si elle est dépassée ; after:Nd part de la complétion.
+// Une échéance avec heure garde son heure.
+func nextOccurrence(task Task, completed time.Time) (Task, bool) {
+	r, err := parseRecurrence(task.Recur)
+	if err != nil {
+		return Task{}, false
+	}
+
+	today := startOfDay(completed)
+	current, hasTime, err := parseDue(task.Due, completed.Location())
+	var due time.Time
+	if r.Kind == RecurAfter {
+		due = today.AddDate(0, 0, r.Days)
+	} else {
+		base := today
+		if err == nil && startOfDay(current).After(base) {
+			base = startOfDay(current)
+		}
+		due = r.after(base)
+	}
+	dueValue := formatDueValue(due, false)
+	if err == nil && hasTime {
+		dueValue = formatDueValue(time.Date(due.Year(), due.Month(), due.Day(), current.Hour(), current.Minute(), current.Second(), 0, due.Location()), true)
+	}
+
+	next := Task{
+		Text:     task.Text,
+		Priority: task.Priority,
+		Due:      dueValue,
+		Tags:     task.Tags,
+		Parent:   task.Parent,
+		Recur:    task.Recur,
+		Series:   task.Series,
+		Estimate: task.Estimate,
+	}
+
+	// Début prévu, attente et expiration gardent leur écart avec l'échéance
+	if err == nil {
+		days := int(due.Sub(startOfDay(current)).Hours()/24 + 0.5)
+		next.Scheduled = shiftDate(task.Scheduled, days)
+		next.Wait = shiftDate(task.Wait, days)
+		next.Until = shiftDate(task.Until, days)
+	}
+	return next, true
+}
+
+// spawnNext ajoute l'occurrence suivante d'une tâche récurrente (index i)
+// qui vient d'être terminée. Retourne la nouvelle tâche. Une série n'a qu'une
+// occurrence ouverte : terminer à nouveau une occurrence rouverte n'en crée pas d'autre.
+func (tm *TodoManager) spawnNext(i int, completed time.Time) (Task, bool) {
+	if tm.Tasks[i].Recur == "" {
+		return Task{}, false
+	}
+	if tm.Tasks[i].Series == "" {
+		tm.Tasks[i].Series = generateUUID() // Tâche importée sans série
+	}
+	for j, other := range tm.Tasks {
+		if j != i && other.Series == tm.Tasks[i].Series && !other.IsClosed() {
+			return Task{}, false
+		}
+	}
+	next, ok := nextOccurrence(tm.Tasks[i], completed)
+	if !ok {
+		return Task{}, false
+	}
+	return tm.appendTask(next), true
+}
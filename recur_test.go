@@ -0,0 +1,120 @@
+// recur_test.go - Tests des tâches récurrentes
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{"daily", "daily", false},
+		{"Weekly:lundi,THU", "weekly:mon,thu", false},
+		{"monthly:31", "monthly:31", false},
+		{"after:2w", "after:14d", false},
+		{"after:3", "after:3d", false},
+		{"monthly:32", "", true},
+		{"weekly:funday", "", true},
+		{"after:0d", "", true},
+		{"yearly", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := parseRecurrence(tt.rule)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseRecurrence(%q) devrait échouer", tt.rule)
+				}
+				return
+			}
+			if err != nil || r.String() != tt.want {
+				t.Errorf("parseRecurrence(%q) = %q, %v ; attendu %q", tt.rule, r.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	// Mercredi 16 juillet 2025, 18h
+	completed := time.Date(2025, 7, 16, 18, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name  string
+		recur string
+		due   string
+		want  string
+	}{
+		{"quotidienne", "daily", "2025-07-16", "2025-07-17"},
+		{"hebdomadaire en avance", "weekly:fri", "2025-07-18", "2025-07-25"},
+		{"hebdomadaire en retard", "weekly:mon,thu", "2025-07-07", "2025-07-17"},
+		{"mensuelle fin de mois", "monthly:31", "2025-07-31", "2025-08-31"},
+		{"mensuelle février", "monthly:31", "2026-01-31", "2026-02-28"},
+		{"après complétion", "after:3d", "2025-07-01", "2025-07-19"},
+		{"après complétion sans échéance", "after:1w", "", "2025-07-23"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := nextOccurrence(Task{Text: "Rapport", Recur: tt.recur, Due: tt.due, Series: "s", Estimate: "1h00"}, completed)
+			if !ok || next.Due != tt.want {
+				t.Errorf("Échéance suivante de %s (due %s): %q, attendu %q", tt.recur, tt.due, next.Due, tt.want)
+			}
+			if next.Series != "s" || next.Recur != tt.recur || next.Estimate != "1h00" || next.IsDone() {
+				t.Errorf("L'occurrence doit rester dans la série: %+v", next)
+			}
+		})
+	}
+}
+
+func TestRecurringTask_Done(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	task := Task{Text: "Rapport hebdo", Tags: []string{"+travail"}}
+	if err := tm.AddTask(task, 0, "weekly"); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	first := *assertTaskExists(t, tm, 1)
+	if first.Due == "" || first.Series == "" || first.Recur == "weekly" {
+		t.Fatalf("Échéance, série et jour de la semaine attendus: %+v", first)
+	}
+	if err := tm.AddTask(task, 0, "hourly"); err == nil {
+		t.Error("Une règle inconnue doit être refusée")
+	}
+
+	tm.Done(1)
+	tm = reloadManager(t, tm.filename)
+	assertTaskCount(t, tm, 2)
+	next := assertTaskExists(t, tm, 2)
+	if next.IsDone() || next.Series != first.Series || next.Due <= first.Due || len(next.Tags) != 1 {
+		t.Errorf("Occurrence suivante inattendue: %+v", next)
+	}
+
+	t.Run("terminée deux fois", func(t *testing.T) {
+		tm.Done(1)
+		tm = reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 2)
+	})
+
+	t.Run("rouverte puis terminée", func(t *testing.T) {
+		if err := tm.SetStatus(1, StatusTodo, ""); err != nil {
+			t.Fatalf("SetStatus: %v", err)
+		}
+		tm.Done(1)
+		tm = reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 2)
+		if err := tm.Undo(2); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+	})
+
+	if err := tm.Undo(1); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	assertTaskCount(t, tm, 1)
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rétention par défaut des instantanés
+const (
+	defaultHourlySnapshots = 24
+	defaultDailySnapshots  = 7
+)
+
+// snapshotNameRegex nom d'un instantané : hourly-20250720-103512.json
+var snapshotNameRegex = regexp.MustCompile(`^(hourly|daily)-(\d{8}-\d{6})\.json$`)
+
+// SnapshotPolicy nombre d'instantanés conservés par type (0 : désactivé)
+type SnapshotPolicy struct {
+	Hourly int
+	Daily  int
+}
+
+// Snapshot instantané du fichier de tâches
+type Snapshot struct {
+	Name string
+	Kind string // hourly ou daily
+	Time time.Time
+	Path string
+}
+
+// snapshotPolicyFromConfig lit la rétention dans la configuration
+func snapshotPolicyFromConfig(config *Config) *SnapshotPolicy {
+	policy := &SnapshotPolicy{Hourly: defaultHourlySnapshots, Daily: defaultDailySnapshots}
+	if count, err := strconv.Atoi(config.GetDefault("snapshots.hourly", "")); err == nil && count >= 0 {
+		policy.Hourly = count
+	}
+	if count, err := strconv.Atoi(config.GetDefault("snapshots.daily", "")); err == nil && count >= 0 {
+		policy.Daily = count
+	}
+	return policy
+}
+
+// snapshotDir retourne le répertoire des instantanés d'un fichier de tâches
+func snapshotDir(filename string) string {
+	return sidecarDir(filename, "snapshots")
+}
+
+// snapshotSource retourne le fichier à photographier, vide si le stockage
+// ne s'y prête pas (journal, mémoire)
+func (tm *TodoManager) snapshotSource() string {
+	if tm.filename == "" {
+		return ""
+	}
+	if _, isJSON := tm.storage().(*JSONFileStore); !isJSON {
+		return ""
+	}
+	return tm.filename
+}
+
+// policy retourne la rétention configurée, ou celle par défaut
+func (tm *TodoManager) policy() SnapshotPolicy {
+	if tm.snapshotPolicy != nil {
+		return *tm.snapshotPolicy
+	}
+	return SnapshotPolicy{Hourly: defaultHourlySnapshots, Daily: defaultDailySnapshots}
+}
+
+// takeSnapshots photographie le fichier avant sa réécriture si l'heure (ou le
+// jour) courante n'a pas encore d'instantané, puis applique la rétention.
+// Un lien physique suffit : la sauvegarde remplace le fichier par renommage.
+// Le répertoire n'est relu qu'une fois par heure et par processus.
+func (tm *TodoManager) takeSnapshots(now time.Time) error {
+	source := tm.snapshotSource()
+	if source == "" || tm.snapshotHour == now.Format("20060102-15") {
+		return nil
+	}
+	if _, err := os.Stat(source); err != nil {
+		return nil // Première sauvegarde
+	}
+
+	policy := tm.policy()
+	slots := []struct {
+		kind   string
+		prefix string
+		keep   int
+	}{
+		{"hourly", now.Format("20060102-15"), policy.Hourly},
+		{"daily", now.Format("20060102"), policy.Daily},
+	}
+
+	dir := snapshotDir(source)
+	existing, err := listSnapshots(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range slots {
+		if slot.keep <= 0 {
+			continue
+		}
+
+		taken := false
+		for _, snapshot := range existing {
+			if snapshot.Kind == slot.kind && strings.HasPrefix(snapshot.Name, slot.kind+"-"+slot.prefix) {
+				taken = true
+				break
+			}
+		}
+		if taken {
+			continue
+		}
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s-%s.json", slot.kind, now.Format("20060102-150405"))
+		target := filepath.Join(dir, name)
+		if err := os.Link(source, target); err != nil {
+			if err := copyFile(source, target); err != nil {
+				return err
+			}
+		}
+		existing = append(existing, Snapshot{Name: strings.TrimSuffix(name, ".json"), Kind: slot.kind, Time: now, Path: target})
+	}
+
+	if err := pruneSnapshots(existing, policy); err != nil {
+		return err
+	}
+	tm.snapshotHour = now.Format("20060102-15")
+	return nil
+}
+
+// pruneSnapshots supprime les instantanés les plus anciens au-delà de la rétention
+func pruneSnapshots(snapshots []Snapshot, policy SnapshotPolicy) error {
+	keep := map[string]int{"hourly": policy.Hourly, "daily": policy.Daily}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Time.After(snapshots[j].Time) })
+
+	count := make(map[string]int)
+	for _, snapshot := range snapshots {
+		count[snapshot.Kind]++
+		if keep[snapshot.Kind] > 0 && count[snapshot.Kind] > keep[snapshot.Kind] {
+			if err := os.Remove(snapshot.Path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// listSnapshots retourne les instantanés d'un répertoire, du plus ancien au plus récent
+func listSnapshots(dir string) ([]Snapshot, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		match := snapshotNameRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		taken, err := time.ParseInLocation("20060102-150405", match[2], time.Local)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Name: strings.TrimSuffix(entry.Name(), ".json"),
+			Kind: match[1],
+			Time: taken,
+			Path: filepath.Join(dir, entry.Name()),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// loadSnapshot lit le contenu d'un instantané
+func loadSnapshot(snapshot Snapshot) (*TodoManager, error) {
+	data, err := readDataFile(snapshot.Path)
+	if err != nil {
+		return nil, err
+	}
+	state := &TodoManager{Tasks: []Task{}, NextID: 1}
+	if err := decodeTodoData(data, state); err != nil {
+		return nil, fmt.Errorf("instantané %s illisible : %v", snapshot.Name, err)
+	}
+	return state, nil
+}
+
+// snapshotTimeLayouts formats acceptés pour --at et --since
+var snapshotTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"}
+
+// findSnapshot retrouve un instantané par nom, ou le dernier pris avant une date
+func (tm *TodoManager) findSnapshot(ref string) (Snapshot, error) {
+	source := tm.snapshotSource()
+	if source == "" {
+		return Snapshot{}, fmt.Errorf("instantanés indisponibles pour ce stockage")
+	}
+
+	snapshots, err := listSnapshots(snapshotDir(source))
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	ref = strings.TrimSuffix(ref, ".json")
+	for _, snapshot := range snapshots {
+		if snapshot.Name == ref {
+			return snapshot, nil
+		}
+	}
+
+	for _, layout := range snapshotTimeLayouts {
+		at, err := time.ParseInLocation(layout, ref, time.Local)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			at = at.Add(24*time.Hour - time.Second) // Fin de journée
+		}
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if !snapshots[i].Time.After(at) {
+				return snapshots[i], nil
+			}
+		}
+		return Snapshot{}, fmt.Errorf("aucun instantané antérieur au %s", ref)
+	}
+
+	return Snapshot{}, fmt.Errorf("instantané '%s' introuvable (nom ou date AAAA-MM-JJ HH:MM)", ref)
+}
+
+// PrintSnapshots affiche les instantanés disponibles
+func (tm *TodoManager) PrintSnapshots() error {
+	source := tm.snapshotSource()
+	if source == "" {
+		return fmt.Errorf("instantanés indisponibles pour ce stockage")
+	}
+
+	snapshots, err := listSnapshots(snapshotDir(source))
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Println("📸 Aucun instantané (le premier est pris à la prochaine modification)")
+		return nil
+	}
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		summary := ""
+		if state, err := loadSnapshot(snapshot); err == nil {
+			summary = fmt.Sprintf("%d tâche(s)", len(state.Tasks))
+		} else {
+			summary = ColorRed + "illisible" + ColorReset
+		}
+		fmt.Printf("📸 %s  %-6s  %-28s %s\n", snapshot.Time.Format("2006-01-02 15:04:05"), snapshot.Kind, snapshot.Name, summary)
+	}
+	return nil
+}
+
+// RestoreSnapshot remplace les tâches par celles d'un instantané.
+// L'opération est enregistrée dans l'historique : todo undo la défait.
+func (tm *TodoManager) RestoreSnapshot(ref string) error {
+	snapshot, err := tm.findSnapshot(ref)
+	if err != nil {
+		return err
+	}
+	state, err := loadSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tm.Tasks = state.Tasks
+	tm.NextID = state.NextID
+	tm.Trash = state.Trash
+	tm.Tombstones = state.Tombstones
+	tm.operation = fmt.Sprintf("restore --at=%s", snapshot.Time.Format("2006-01-02 15:04:05"))
+	if err := tm.save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("⏪ Instantané du %s restauré (%d tâches)\n", snapshot.Time.Format("2006-01-02 15:04:05"), len(tm.Tasks))
+	return nil
+}
+
+// Diff affiche les modifications depuis un instantané
+func (tm *TodoManager) Diff(ref string) error {
+	snapshot, err := tm.findSnapshot(ref)
+	if err != nil {
+		return err
+	}
+	state, err := loadSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔍 Modifications depuis l'instantané du %s\n", snapshot.Time.Format("2006-01-02 15:04:05"))
+
+	changes := diffTasks("tasks", state.Tasks, tm.Tasks)
+	if len(changes) == 0 {
+		fmt.Println("📝 Aucune modification")
+		return nil
+	}
+
+	for _, change := range changes {
+		switch {
+		case change.Before == nil:
+			fmt.Printf("%s+ [%d] %s%s\n", ColorGreen, change.After.ID, change.After.Text, ColorReset)
+		case change.After == nil:
+			fmt.Printf("%s- [%d] %s%s\n", ColorRed, change.Before.ID, change.Before.Text, ColorReset)
+		default:
+			fmt.Printf("%s~ [%d] %s%s (%s)\n", ColorYellow, change.After.ID, change.After.Text, ColorReset,
+				strings.Join(changedFields(*change.Before, *change.After), ", "))
+		}
+	}
+	return nil
+}
+
+// changedFields retourne le nom JSON des champs modifiés entre deux versions d'une tâche
+func changedFields(before Task, after Task) []string {
+	var fields []string
+	valueBefore, valueAfter := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < valueBefore.NumField(); i++ {
+		if !reflect.DeepEqual(valueBefore.Field(i).Interface(), valueAfter.Field(i).Interface()) {
+			name, _, _ := strings.Cut(valueBefore.Type().Field(i).Tag.Get("json"), ",")
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
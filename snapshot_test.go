@@ -0,0 +1,128 @@
+// snapshot_test.go - Tests des instantanés horaires et quotidiens
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// at construit une heure locale fixe pour les instantanés
+func at(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+	if err != nil {
+		t.Fatalf("Date invalide %s: %v", value, err)
+	}
+	return parsed
+}
+
+func TestSnapshots_TakeAndPrune(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.snapshotPolicy = &SnapshotPolicy{Hourly: 2, Daily: 1}
+	if err := tm.takeSnapshots(at(t, "2025-07-20 09:00")); err != nil {
+		t.Fatalf("takeSnapshots sans fichier: %v", err)
+	}
+	if snapshots, _ := listSnapshots(snapshotDir(tm.filename)); len(snapshots) != 0 {
+		t.Fatalf("Aucun instantané attendu avant la première sauvegarde: %+v", snapshots)
+	}
+
+	tm.snapshotPolicy = &SnapshotPolicy{}
+	tm.Add("Tâche 1", nil, "", "")
+	tm.snapshotPolicy = &SnapshotPolicy{Hourly: 2, Daily: 1}
+
+	for _, moment := range []string{"2025-07-20 09:05", "2025-07-20 09:40", "2025-07-20 10:15", "2025-07-20 11:30", "2025-07-21 08:00"} {
+		if err := tm.takeSnapshots(at(t, moment)); err != nil {
+			t.Fatalf("takeSnapshots(%s): %v", moment, err)
+		}
+	}
+
+	snapshots, err := listSnapshots(snapshotDir(tm.filename))
+	if err != nil {
+		t.Fatalf("listSnapshots: %v", err)
+	}
+	var names []string
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+
+	want := []string{"hourly-20250720-113000", "daily-20250721-080000", "hourly-20250721-080000"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Instantanés attendus %v, obtenu %v", want, names)
+	}
+}
+
+func TestSnapshots_RestoreAndDiff(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.snapshotPolicy = &SnapshotPolicy{}
+	tm.Add("Tâche 1", nil, "", "")
+	tm.Add("Tâche 2", nil, "", "")
+
+	tm.snapshotPolicy = &SnapshotPolicy{Hourly: 24, Daily: 7}
+	if err := tm.takeSnapshots(at(t, "2025-07-20 10:00")); err != nil {
+		t.Fatalf("takeSnapshots: %v", err)
+	}
+
+	tm.snapshotPolicy = &SnapshotPolicy{}
+	tm.Edit(2, "Tâche 2 modifiée", nil)
+	tm.Add("Tâche 3", nil, "", "")
+	tm.Clear(true)
+
+	t.Run("diff depuis l'instantané", func(t *testing.T) {
+		snapshot, err := tm.findSnapshot("2025-07-20 10:30")
+		if err != nil {
+			t.Fatalf("findSnapshot: %v", err)
+		}
+		state, err := loadSnapshot(snapshot)
+		if err != nil {
+			t.Fatalf("loadSnapshot: %v", err)
+		}
+		if changes := diffTasks("tasks", state.Tasks, tm.Tasks); len(changes) != 2 {
+			t.Errorf("2 suppressions attendues: %+v", changes)
+		}
+		if err := tm.Diff(snapshot.Name); err != nil {
+			t.Errorf("Diff: %v", err)
+		}
+	})
+
+	t.Run("restauration", func(t *testing.T) {
+		if err := tm.RestoreSnapshot("2025-07-20"); err != nil {
+			t.Fatalf("RestoreSnapshot: %v", err)
+		}
+
+		tm := reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 2)
+		if task := assertTaskExists(t, tm, 2); task != nil && task.Text != "Tâche 2" {
+			t.Errorf("Texte d'origine attendu: %+v", task)
+		}
+		if tm.NextID != 3 {
+			t.Errorf("NextID 3 attendu, obtenu %d", tm.NextID)
+		}
+
+		if err := tm.Undo(1); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+		assertTaskCount(t, reloadManager(t, tm.filename), 0)
+	})
+
+	t.Run("instantané introuvable", func(t *testing.T) {
+		for _, ref := range []string{"2025-07-19 23:00", "hier", "hourly-20250101-000000"} {
+			if _, err := tm.findSnapshot(ref); err == nil {
+				t.Errorf("findSnapshot(%q) devrait échouer", ref)
+			}
+		}
+	})
+}
+
+func TestChangedFields(t *testing.T) {
+	before := Task{ID: 1, Text: "Avant", Tags: []string{"+dev"}}
+	after := Task{ID: 1, Text: "Après", Tags: []string{"+dev"}, Status: StatusDone}
+
+	if got := changedFields(before, after); !reflect.DeepEqual(got, []string{"text", "status"}) {
+		t.Errorf("Champs modifiés inattendus: %v", got)
+	}
+}
@@ -0,0 +1,127 @@
+// status.go - Statut des tâches (start, block, cancel, status, list --status)
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Statuts d'une tâche
+const (
+	StatusTodo       = "todo"        // À faire
+	StatusInProgress = "in-progress" // En cours
+	StatusBlocked    = "blocked"     // Bloquée (motif facultatif)
+	StatusWaiting    = "waiting"     // En attente d'un tiers
+	StatusCancelled  = "cancelled"   // Annulée
+	StatusDone       = "done"        // Terminée
+)
+
+// taskStatuses statuts reconnus, dans l'ordre du cycle de vie
+var taskStatuses = []string{StatusTodo, StatusInProgress, StatusBlocked, StatusWaiting, StatusCancelled, StatusDone}
+
+// statusIcons icône affichée par printTask pour chaque statut
+var statusIcons = map[string]string{
+	StatusTodo:       "⭕",
+	StatusInProgress: "🔄",
+	StatusBlocked:    "⛔",
+	StatusWaiting:    "⏳",
+	StatusCancelled:  "🚫",
+	StatusDone:       "✅",
+}
+
+// parseStatus valide un statut (canceled et in_progress sont acceptés)
+func parseStatus(value string) (string, error) {
+	status := strings.ToLower(strings.TrimSpace(value))
+	switch status {
+	case "canceled":
+		status = StatusCancelled
+	case "in_progress", "inprogress":
+		status = StatusInProgress
+	}
+	if _, ok := statusIcons[status]; !ok {
+		return "", fmt.Errorf("statut '%s' inconnu (%s)", value, strings.Join(taskStatuses, ", "))
+	}
+	return status, nil
+}
+
+// IsDone indique si la tâche est terminée
+func (task Task) IsDone() bool {
+	return task.Status == StatusDone
+}
+
+// IsClosed indique si la tâche est terminée ou annulée : elle n'apparaît plus
+// dans todo list, ne bloque plus d'autre tâche et peut être archivée
+func (task Task) IsClosed() bool {
+	return task.Status == StatusDone || task.Status == StatusCancelled
+}
+
+// UnmarshalJSON lit une tâche et convertit l'ancien champ "done" (historique,
+// archives et instantanés écrits avant le schéma v3)
+func (task *Task) UnmarshalJSON(data []byte) error {
+	type plainTask Task
+	var decoded struct {
+		plainTask
+		Done *bool `json:"done"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*task = Task(decoded.plainTask)
+	if task.Status == "" {
+		task.Status = StatusTodo
+		if decoded.Done != nil && *decoded.Done {
+			task.Status = StatusDone
+		}
+	}
+	return nil
+}
+
+// SetStatus change le statut d'une tâche. Terminer une tâche passe par
+// Complete (sous-tâches, récurrence) ; le motif n'est conservé que pour les
+// statuts blocked, waiting et cancelled.
+func (tm *TodoManager) SetStatus(id int, status string, reason string) error {
+	if status == StatusDone {
+		return tm.Complete(id, false)
+	}
+
+	i := tm.findTask(id)
+	if i < 0 {
+		return fmt.Errorf("tâche [%d] introuvable", id)
+	}
+	task := tm.Tasks[i]
+	if task.Status == status && task.Reason == reason {
+		return fmt.Errorf("la tâche [%d] est déjà %s", id, status)
+	}
+
+	switch status {
+	case StatusBlocked, StatusWaiting, StatusCancelled:
+	default:
+		reason = ""
+	}
+
+	if status != StatusInProgress {
+		tm.stopTimer(i, time.Now()) // Le minuteur ne tourne que sur une tâche en cours
+	}
+	tm.Tasks[i].Status = status
+	tm.Tasks[i].Reason = reason
+	tm.Tasks[i].Updated = timestamp(time.Now())
+	tm.operation = fmt.Sprintf("status %s [%d] %s", status, id, task.Text)
+	tm.save()
+
+	messages := map[string]string{
+		StatusTodo:       "rouverte",
+		StatusInProgress: "démarrée",
+		StatusBlocked:    "bloquée",
+		StatusWaiting:    "en attente",
+		StatusCancelled:  "annulée",
+	}
+	fmt.Printf("%s Tâche [%d] %s", statusIcons[status], id, messages[status])
+	if reason != "" {
+		fmt.Printf(" : %s", reason)
+	}
+	fmt.Println()
+	return nil
+}
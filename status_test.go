@@ -0,0 +1,93 @@
+// status_test.go - Tests du statut des tâches
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMigrateStatus(t *testing.T) {
+	legacy := `{"schemaVersion": 2, "tasks": [
+		{"id": 1, "uuid": "a", "text": "Ouverte", "done": false},
+		{"id": 2, "uuid": "b", "text": "Terminée", "done": true}
+	], "trash": [{"id": 3, "uuid": "c", "text": "Supprimée", "done": true, "deleted": "2025-07-01 10:00:00"}],
+	"nextId": 4}`
+
+	data, err := migrateTodoData([]byte(legacy), nil)
+	if err != nil {
+		t.Fatalf("Migration: %v", err)
+	}
+	if strings.Contains(string(data), `"done":`) {
+		t.Errorf("Le champ done doit disparaître: %s", data)
+	}
+
+	var tm TodoManager
+	if err := json.Unmarshal(data, &tm); err != nil {
+		t.Fatalf("Décodage: %v", err)
+	}
+	got := []string{tm.Tasks[0].Status, tm.Tasks[1].Status, tm.Trash[0].Status}
+	if strings.Join(got, " ") != "todo done done" {
+		t.Errorf("Statuts migrés inattendus: %v", got)
+	}
+}
+
+func TestTask_UnmarshalLegacyDone(t *testing.T) {
+	// Historique et archives écrits avant le schéma v3
+	var tasks []Task
+	if err := json.Unmarshal([]byte(`[{"id": 1, "done": true}, {"id": 2, "done": false}, {"id": 3, "status": "waiting"}]`), &tasks); err != nil {
+		t.Fatalf("Décodage: %v", err)
+	}
+	if !tasks[0].IsDone() || tasks[1].Status != StatusTodo || tasks[2].Status != StatusWaiting {
+		t.Errorf("Statuts inattendus: %+v", tasks)
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Écrire la doc", nil, "", "")
+	tm.Add("Attendre la validation", nil, "", "")
+	tm.Add("Ancienne idée", nil, "", "")
+
+	if err := tm.SetStatus(1, StatusInProgress, "ignoré"); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if err := tm.SetStatus(2, StatusBlocked, "en attente du client"); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if err := tm.SetStatus(3, StatusCancelled, ""); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if err := tm.SetStatus(3, StatusCancelled, ""); err == nil {
+		t.Error("Un statut inchangé doit être signalé")
+	}
+	if err := tm.SetStatus(42, StatusInProgress, ""); err == nil {
+		t.Error("Une tâche inexistante doit être refusée")
+	}
+
+	tm = reloadManager(t, tm.filename)
+	if task := assertTaskExists(t, tm, 1); task.Status != StatusInProgress || task.Reason != "" {
+		t.Errorf("Tâche démarrée sans motif attendue: %+v", task)
+	}
+	if task := assertTaskExists(t, tm, 2); task.Reason != "en attente du client" || !tm.isBlocked(*task) {
+		t.Errorf("Tâche bloquée avec motif attendue: %+v", task)
+	}
+
+	filter := TaskFilter{}
+	if got := filterTaskList(tm.Tasks, filter); len(got) != 2 {
+		t.Errorf("Une tâche annulée ne doit pas être listée: %+v", got)
+	}
+	filter.Status = []string{StatusCancelled}
+	if got := filterTaskList(tm.Tasks, filter); len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("Filtre par statut inattendu: %+v", got)
+	}
+
+	if err := tm.SetStatus(1, StatusDone, ""); err != nil || !assertTaskExists(t, tm, 1).IsDone() {
+		t.Errorf("Le statut done doit terminer la tâche: %v", err)
+	}
+	if err := tm.SetStatus(3, StatusDone, ""); err != nil || !assertTaskExists(t, reloadManager(t, tm.filename), 3).IsDone() {
+		t.Errorf("Une tâche annulée doit pouvoir être terminée: %v", err)
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Store abstrait la persistance d'un TodoManager
+type Store interface {
+	// Load remplit tm depuis le support. Retourne une erreur satisfaisant
+	// errors.Is(err, os.ErrNotExist) si aucune donnée n'existe encore.
+	Load(tm *TodoManager) error
+	// Save écrit l'état complet de tm sur le support
+	Save(tm *TodoManager) error
+}
+
+// Types de stockage disponibles
+const (
+	StoreJSON      = "json"
+	StoreJournal   = "journal"
+	StoreMemory    = "memory"
+	StoreEncrypted = "encrypted"
+	StoreEvents    = "events"
+)
+
+// storeFile retourne le fichier où le stockage kind écrit les tâches de filename
+func storeFile(kind string, filename string) string {
+	switch strings.ToLower(kind) {
+	case StoreJournal:
+		return strings.TrimSuffix(filename, ".json") + ".journal"
+	case StoreEvents:
+		return strings.TrimSuffix(filename, ".json") + ".events"
+	default:
+		return filename
+	}
+}
+
+// newStore crée le stockage correspondant au type demandé
+func newStore(kind string, filename string) (Store, error) {
+	switch strings.ToLower(kind) {
+	case "", StoreJSON:
+		return NewJSONFileStore(filename), nil
+	case StoreJournal:
+		return NewJournalStore(storeFile(kind, filename)), nil
+	case StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreEncrypted:
+		return &JSONFileStore{filename: filename, encrypt: true}, nil
+	case StoreEvents:
+		return NewEventStore(storeFile(kind, filename)), nil
+	default:
+		return nil, fmt.Errorf("stockage '%s' inconnu (json, journal, memory, encrypted, events)", kind)
+	}
+}
+
+// encodeTodoData sérialise l'état persistant d'un TodoManager
+func encodeTodoData(tm *TodoManager) ([]byte, error) {
+	tm.SchemaVersion = currentSchemaVersion
+	return json.MarshalIndent(tm, "", "  ")
+}
+
+// decodeTodoData charge des données JSON dans tm sans le modifier en cas d'erreur.
+// Les données d'un ancien schéma sont migrées à la volée.
+func decodeTodoData(data []byte, tm *TodoManager) error {
+	data, err := migrateTodoData(data, nil)
+	if err != nil {
+		return err
+	}
+
+	loaded := *tm
+	loaded.Tasks = nil
+
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+	if loaded.Tasks == nil {
+		loaded.Tasks = []Task{}
+	}
+
+	*tm = loaded
+	return nil
+}
+
+// JSONFileStore stocke les tâches dans un unique fichier JSON.
+// Chaque sauvegarde est atomique et forcée sur disque ; l'état d'avant la
+// commande est conservé dans un fichier .bak, utilisé si le fichier principal est illisible.
+// Un fichier principal illisible est mis en quarantaine avant d'être remplacé.
+// Un fichier chiffré (todo encrypt) est reconnu au chargement et reste chiffré.
+type JSONFileStore struct {
+	filename    string
+	primaryBad  bool
+	migrated    map[int][]byte // Versions d'origine à sauvegarder avant réécriture
+	cipher      *fileCipher    // Chiffrement du fichier, nil s'il est en clair
+	encrypt     bool           // Chiffrer un fichier créé en clair (stockage encrypted)
+	saved       []byte         // Contenu en clair du fichier, tel que lu ou écrit en dernier
+	savedCipher *fileCipher    // Chiffrement de ce contenu
+	rotated     bool           // Sauvegarde .bak déjà renouvelée par cette commande
+}
+
+// NewJSONFileStore crée un stockage fichier JSON
+func NewJSONFileStore(filename string) *JSONFileStore {
+	return &JSONFileStore{filename: filename}
+}
+
+// backupFilename retourne le chemin de la génération précédente
+func (s *JSONFileStore) backupFilename() string {
+	return s.filename + ".bak"
+}
+
+// Load charge le fichier JSON, ou sa sauvegarde s'il est illisible.
+// Un ancien schéma est migré en mémoire ; l'original sera sauvegardé
+// (todo.json.vN.bak) avant la première réécriture.
+func (s *JSONFileStore) Load(tm *TodoManager) error {
+	data, err := ioutil.ReadFile(s.filename)
+	if err == nil {
+		data, s.cipher, err = openData(data)
+	}
+	if err == nil {
+		data, err = migrateTodoData(data, func(m migration, before []byte, after []byte) error {
+			if s.migrated == nil {
+				s.migrated = make(map[int][]byte)
+			}
+			s.migrated[m.From] = before
+			return nil
+		})
+	}
+	if err == nil {
+		err = decodeTodoData(data, tm)
+	}
+	if err == nil && s.migrated == nil {
+		s.saved, s.savedCipher = data, s.cipher
+	}
+	if err == nil || errors.Is(err, os.ErrNotExist) || errors.Is(err, errNewerSchema) || errors.Is(err, errKeyUnavailable) {
+		return err
+	}
+	s.primaryBad = true
+
+	backupData, backupErr := readDataFile(s.backupFilename())
+	if backupErr != nil || decodeTodoData(backupData, tm) != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️ %s illisible (%v), sauvegarde %s chargée\n", s.filename, err, s.backupFilename())
+	return nil
+}
+
+// Save réécrit le fichier JSON de façon atomique, chiffré si besoin
+func (s *JSONFileStore) Save(tm *TodoManager) error {
+	if s.cipher == nil && s.encrypt {
+		c, err := keyring.newCipher()
+		if err != nil {
+			return err
+		}
+		s.cipher = c
+	}
+
+	plain, err := encodeTodoData(tm)
+	if err != nil {
+		return err
+	}
+	// Contenu inchangé : ni réécriture, ni sauvegarde .bak identique au fichier
+	if !s.primaryBad && s.migrated == nil && s.cipher == s.savedCipher && bytes.Equal(plain, s.saved) {
+		return nil
+	}
+	data, err := sealData(plain, s.cipher)
+	if err != nil {
+		return err
+	}
+
+	for version, original := range s.migrated {
+		original, err := sealData(original, s.cipher)
+		if err != nil {
+			return err
+		}
+		if err := writeSchemaBackup(s.filename, version, original); err != nil {
+			return err
+		}
+	}
+	s.migrated = nil
+
+	// Ne jamais écraser un fichier illisible : il part en quarantaine
+	// et ne remplace pas la sauvegarde précédente
+	backup := s.backupFilename()
+	if s.primaryBad {
+		quarantine, err := quarantineFile(s.filename)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "⚠️ Fichier illisible mis en quarantaine : %s\n", quarantine)
+		}
+		backup = ""
+	}
+
+	// Seule la première écriture d'une commande renouvelle le .bak : il garde
+	// l'état d'avant la commande, déjà sur disque
+	if s.rotated {
+		backup = ""
+	}
+	_, statErr := os.Stat(s.filename) // Sans fichier précédent, pas de .bak
+	if err := writeFileAtomic(s.filename, data, dataFileMode, backup); err != nil {
+		return err
+	}
+	s.rotated = s.rotated || (statErr == nil && backup != "")
+	s.primaryBad = false
+	s.saved, s.savedCipher = plain, s.cipher
+	return nil
+}
+
+// sealedWith retourne le chiffrement du fichier, nil s'il est en clair
+func (s *JSONFileStore) sealedWith() *fileCipher {
+	return s.cipher
+}
+
+// sealWith change le chiffrement des prochaines sauvegardes (nil : en clair)
+func (s *JSONFileStore) sealWith(c *fileCipher) {
+	s.cipher = c
+	if c == nil {
+		s.encrypt = false
+	}
+}
+
+// journalCompactThreshold nombre d'entrées avant compaction du journal
+const journalCompactThreshold = 100
+
+// JournalStore ajoute un état complet par ligne à un fichier journal.
+// Le dernier état lisible fait foi : une ligne tronquée par un crash est ignorée.
+// Un journal chiffré (todo encrypt) chiffre chaque ligne séparément.
+type JournalStore struct {
+	filename string
+	entries  int
+	cipher   *fileCipher // Chiffrement des entrées, nil si elles sont en clair
+	rewrite  bool        // Chiffrement changé : compacter pour ne garder aucune ancienne entrée
+}
+
+// NewJournalStore crée un stockage journal en ajout seul
+func NewJournalStore(filename string) *JournalStore {
+	return &JournalStore{filename: filename}
+}
+
+// Load rejoue le journal et garde le dernier état valide
+func (s *JournalStore) Load(tm *TodoManager) error {
+	file, err := os.Open(s.filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var last []byte
+	s.entries = 0
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && json.Valid(line) {
+			last = line
+			s.entries++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if last == nil {
+		return fmt.Errorf("journal %s: aucun état valide", s.filename)
+	}
+	data, c, err := openData(last)
+	if err != nil {
+		return err
+	}
+	s.cipher = c
+	return decodeTodoData(data, tm)
+}
+
+// Save ajoute l'état courant en fin de journal, sur une ligne
+func (s *JournalStore) Save(tm *TodoManager) error {
+	indented, err := encodeTodoData(tm)
+	if err != nil {
+		return err
+	}
+	var line bytes.Buffer
+	if err := json.Compact(&line, indented); err != nil {
+		return err
+	}
+	sealed, err := sealData(line.Bytes(), s.cipher)
+	if err != nil {
+		return err
+	}
+	data := append(sealed, '\n')
+
+	if s.rewrite || s.entries >= journalCompactThreshold {
+		return s.compact(data)
+	}
+
+	file, err := os.OpenFile(s.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, dataFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	s.entries++
+	return nil
+}
+
+// compact remplace le journal par une seule entrée
+func (s *JournalStore) compact(entry []byte) error {
+	if err := writeFileAtomic(s.filename, entry, dataFileMode, ""); err != nil {
+		return err
+	}
+
+	s.entries = 1
+	s.rewrite = false
+	return nil
+}
+
+// sealedWith retourne le chiffrement des entrées, nil si elles sont en clair
+func (s *JournalStore) sealedWith() *fileCipher {
+	return s.cipher
+}
+
+// sealWith change le chiffrement des prochaines entrées (nil : en clair) ;
+// la sauvegarde suivante compacte le journal
+func (s *JournalStore) sealWith(c *fileCipher) {
+	s.rewrite = s.rewrite || c != s.cipher
+	s.cipher = c
+}
+
+// MemoryStore garde les tâches en mémoire (tests, essais)
+type MemoryStore struct {
+	data []byte
+}
+
+// NewMemoryStore crée un stockage en mémoire vide
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load recharge le dernier état sauvegardé
+func (s *MemoryStore) Load(tm *TodoManager) error {
+	if s.data == nil {
+		return os.ErrNotExist
+	}
+	return decodeTodoData(s.data, tm)
+}
+
+// Save copie l'état courant
+func (s *MemoryStore) Save(tm *TodoManager) error {
+	data, err := encodeTodoData(tm)
+	if err != nil {
+		return err
+	}
+	s.data = data
+	return nil
+}
@@ -0,0 +1,273 @@
+// store_test.go - Tests des stockages
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestManager crée un TodoManager sur un stockage donné, sans chargement
+func newTestManager(filename string, store Store) *TodoManager {
+	return &TodoManager{
+		Tasks:    []Task{},
+		NextID:   1,
+		filename: filename,
+		store:    store,
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	tests := []struct {
+		kind    string
+		want    string
+		wantErr bool
+	}{
+		{"", "*main.JSONFileStore", false},
+		{"json", "*main.JSONFileStore", false},
+		{"JOURNAL", "*main.JournalStore", false},
+		{"memory", "*main.MemoryStore", false},
+		{"events", "*main.EventStore", false},
+		{"sqlite", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			store, err := newStore(tt.kind, "todo.json")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("newStore(%q) devrait échouer", tt.kind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newStore(%q): %v", tt.kind, err)
+			}
+			if got := fmt.Sprintf("%T", store); got != tt.want {
+				t.Errorf("newStore(%q): attendu %s, obtenu %s", tt.kind, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStores_RoundTrip(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "todo_store_test")
+	if err != nil {
+		t.Fatalf("Impossible de créer le répertoire temporaire: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	stores := map[string]func() Store{
+		"json":    func() Store { return NewJSONFileStore(filepath.Join(tempDir, "todo.json")) },
+		"journal": func() Store { return NewJournalStore(filepath.Join(tempDir, "todo.journal")) },
+		"events":  func() Store { return NewEventStore(filepath.Join(tempDir, "todo.events")) },
+	}
+	memory := NewMemoryStore()
+	stores["memory"] = func() Store { return memory }
+
+	for name, newStoreFn := range stores {
+		t.Run(name, func(t *testing.T) {
+			tm := newTestManager("", newStoreFn())
+			if err := tm.load(); err != nil {
+				t.Fatalf("Chargement d'un stockage vide: %v", err)
+			}
+
+			tm.Add("Tâche 1", []string{"+dev"}, "high", "")
+			tm.Add("Tâche 2", nil, "", "")
+			tm.Done(1)
+			tm.Remove(2)
+
+			tm2 := newTestManager("", newStoreFn())
+			if err := tm2.load(); err != nil {
+				t.Fatalf("Erreur de chargement: %v", err)
+			}
+			assertTaskCount(t, tm2, 1)
+			if !tm2.Tasks[0].IsDone() {
+				t.Error("La tâche 1 devrait être terminée après rechargement")
+			}
+			if tm2.NextID != 3 {
+				t.Errorf("NextID attendu: 3, obtenu: %d", tm2.NextID)
+			}
+		})
+	}
+}
+
+func TestJournalStore(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "todo_journal_test")
+	if err != nil {
+		t.Fatalf("Impossible de créer le répertoire temporaire: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filename := filepath.Join(tempDir, "todo.journal")
+
+	t.Run("ligne tronquée ignorée", func(t *testing.T) {
+		tm := newTestManager("", NewJournalStore(filename))
+		tm.Add("Tâche valide", nil, "", "")
+
+		file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("Impossible d'ouvrir le journal: %v", err)
+		}
+		file.WriteString(`{"tasks":[{"id":1,"text":"tron`)
+		file.Close()
+
+		tm2 := newTestManager("", NewJournalStore(filename))
+		if err := tm2.load(); err != nil {
+			t.Fatalf("Erreur de chargement: %v", err)
+		}
+		assertTaskCount(t, tm2, 1)
+	})
+
+	t.Run("version du schéma", func(t *testing.T) {
+		os.Remove(filename)
+		newTestManager("", NewJournalStore(filename)).Add("Tâche", nil, "", "")
+
+		content, _ := ioutil.ReadFile(filename)
+		var entry struct {
+			SchemaVersion int `json:"schemaVersion"`
+		}
+		if err := json.Unmarshal(bytes.TrimSpace(content), &entry); err != nil {
+			t.Fatalf("Entrée du journal illisible: %v", err)
+		}
+		if entry.SchemaVersion != currentSchemaVersion || strings.Count(string(content), "\n") != 1 {
+			t.Errorf("Une ligne en version %d attendue: %s", currentSchemaVersion, content)
+		}
+	})
+
+	t.Run("compaction", func(t *testing.T) {
+		os.Remove(filename)
+		store := NewJournalStore(filename)
+		tm := newTestManager("", store)
+		for i := 0; i < journalCompactThreshold+5; i++ {
+			if err := tm.save(); err != nil {
+				t.Fatalf("Erreur de sauvegarde: %v", err)
+			}
+		}
+
+		content, _ := ioutil.ReadFile(filename)
+		lines := strings.Count(string(content), "\n")
+		if lines > journalCompactThreshold {
+			t.Errorf("Le journal devrait être compacté, %d lignes", lines)
+		}
+	})
+
+	t.Run("journal inexistant", func(t *testing.T) {
+		store := NewJournalStore(filepath.Join(tempDir, "absent.journal"))
+		err := store.Load(newTestManager("", store))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Erreur 'inexistant' attendue, obtenu: %v", err)
+		}
+	})
+}
+
+func TestParseGlobalOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantStore string
+		wantRest  []string
+	}{
+		{"sans option", []string{"list", "--all"}, "", []string{"list", "--all"}},
+		{"avec égal", []string{"--store=journal", "add", "x"}, "journal", []string{"add", "x"}},
+		{"valeur séparée", []string{"--store", "memory", "list"}, "memory", []string{"list"}},
+		{"aide", []string{"--help"}, "", []string{"--help"}},
+	}
+
+	os.Unsetenv("TODO_STORE")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, rest, err := parseGlobalOptions(tt.args)
+			if err != nil {
+				t.Fatalf("Erreur inattendue: %v", err)
+			}
+			if opts.Store != tt.wantStore {
+				t.Errorf("Store attendu: %q, obtenu: %q", tt.wantStore, opts.Store)
+			}
+			if strings.Join(rest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("Arguments restants attendus: %v, obtenus: %v", tt.wantRest, rest)
+			}
+		})
+	}
+
+	opts, rest, _ := parseGlobalOptions([]string{"--file", "perso.json", "--list=work", "list"})
+	if opts.File != "perso.json" || opts.List != "work" || len(rest) != 1 {
+		t.Errorf("--file mal interprété: %+v %v", opts, rest)
+	}
+
+	if _, _, err := parseGlobalOptions([]string{"--store"}); err == nil {
+		t.Error("--store sans valeur devrait échouer")
+	}
+}
+
+func TestJSONFileStore_AtomicSave(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Première génération", nil, "", "")
+	tm.Add("Deuxième génération", nil, "", "")
+
+	t.Run("génération précédente conservée", func(t *testing.T) {
+		backup := newTestManager("", NewJSONFileStore(tm.filename+".bak"))
+		if err := backup.load(); err != nil {
+			t.Fatalf("Sauvegarde illisible: %v", err)
+		}
+		assertTaskCount(t, backup, 1)
+	})
+
+	t.Run("aucun fichier temporaire restant", func(t *testing.T) {
+		matches, _ := filepath.Glob(filepath.Join(tempDir, "*.tmp"))
+		if len(matches) > 0 {
+			t.Errorf("Fichiers temporaires restants: %v", matches)
+		}
+	})
+
+	t.Run("repli sur la sauvegarde", func(t *testing.T) {
+		if err := ioutil.WriteFile(tm.filename, []byte(`{"tasks": [tronqu`), 0644); err != nil {
+			t.Fatalf("Impossible de corrompre le fichier: %v", err)
+		}
+
+		store := NewJSONFileStore(tm.filename)
+		tm2 := newTestManager(tm.filename, store)
+		if err := tm2.load(); err != nil {
+			t.Fatalf("Le chargement devrait utiliser la sauvegarde: %v", err)
+		}
+		assertTaskCount(t, tm2, 1)
+
+		// La sauvegarde valide ne doit pas être remplacée par le fichier corrompu
+		tm2.Add("Après reprise", nil, "", "")
+		content, _ := ioutil.ReadFile(tm.filename + ".bak")
+		if !strings.Contains(string(content), "Première génération") {
+			t.Errorf("La sauvegarde a été écrasée: %s", content)
+		}
+
+		tm3 := newTestManager(tm.filename, nil)
+		tm3.load()
+		assertTaskCount(t, tm3, 2)
+	})
+}
+
+func TestJSONFileStore_UnchangedSave(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Première génération", nil, "", "")
+	reloadManager(t, tm.filename).Add("Deuxième génération", nil, "", "")
+
+	// Une sauvegarde sans modification ne réécrit rien : le .bak garde la génération précédente
+	unchanged := reloadManager(t, tm.filename)
+	if err := unchanged.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	backup := newTestManager("", NewJSONFileStore(tm.filename+".bak"))
+	if err := backup.load(); err != nil {
+		t.Fatalf("Sauvegarde illisible: %v", err)
+	}
+	assertTaskCount(t, backup, 1)
+}
@@ -0,0 +1,208 @@
+// subtasks.go - Sous-tâches : tâches parentes, arborescence et progression
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// findTask retourne l'index d'une tâche par ID (-1 si absente)
+func (tm *TodoManager) findTask(id int) int {
+	for i, task := range tm.Tasks {
+		if task.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// taskIndex retrouve les tâches par UUID et les sous-tâches d'une tâche sans
+// reparcourir la liste. Construit une fois par affichage (list) : une recherche
+// linéaire par tâche affichée rendrait l'affichage quadratique.
+type taskIndex struct {
+	tasks    []Task
+	byUUID   map[string]int   // Index de la première tâche portant cet UUID
+	children map[string][]int // Index des sous-tâches directes, dans l'ordre de la liste
+}
+
+// newTaskIndex indexe les tâches de tm
+func (tm *TodoManager) newTaskIndex() *taskIndex {
+	index := &taskIndex{
+		tasks:    tm.Tasks,
+		byUUID:   make(map[string]int, len(tm.Tasks)),
+		children: make(map[string][]int),
+	}
+	for i, task := range tm.Tasks {
+		if task.UUID == "" {
+			continue
+		}
+		if _, exists := index.byUUID[task.UUID]; !exists {
+			index.byUUID[task.UUID] = i
+		}
+		if task.Parent != "" {
+			index.children[task.Parent] = append(index.children[task.Parent], i)
+		}
+	}
+	return index
+}
+
+// descendants voir taskIndex.descendants (recherche ponctuelle)
+func (tm *TodoManager) descendants(uuid string) []int {
+	return tm.newTaskIndex().descendants(uuid)
+}
+
+// descendants retourne les index des sous-tâches (tous niveaux) d'une tâche
+func (index *taskIndex) descendants(uuid string) []int {
+	var result []int
+	seen := map[string]bool{uuid: true}
+	parents := []string{uuid}
+	for len(parents) > 0 {
+		parent := parents[0]
+		parents = parents[1:]
+		for _, i := range index.children[parent] {
+			if child := index.tasks[i].UUID; !seen[child] {
+				seen[child] = true
+				result = append(result, i)
+				parents = append(parents, child)
+			}
+		}
+	}
+	return result
+}
+
+// progress voir taskIndex.progress (recherche ponctuelle)
+func (tm *TodoManager) progress(task Task) (done int, total int) {
+	return tm.newTaskIndex().progress(task)
+}
+
+// progress compte les sous-tâches terminées et le total (tous niveaux)
+func (index *taskIndex) progress(task Task) (done int, total int) {
+	if task.UUID == "" {
+		return 0, 0
+	}
+	for _, i := range index.descendants(task.UUID) {
+		total++
+		if index.tasks[i].IsClosed() {
+			done++
+		}
+	}
+	return done, total
+}
+
+// AddSubtask ajoute une tâche rattachée à une tâche parente ouverte
+func (tm *TodoManager) AddSubtask(parentID int, text string, tags []string, priority string, due string) error {
+	return tm.AddTask(Task{Text: text, Tags: tags, Priority: priority, Due: due}, parentID, "")
+}
+
+// Complete marque une tâche comme terminée. Une tâche dont des sous-tâches
+// sont ouvertes n'est terminée qu'avec cascade, qui les termine aussi.
+func (tm *TodoManager) Complete(id int, cascade bool) error {
+	i := tm.findTask(id)
+	if i < 0 {
+		fmt.Printf("❌ Tâche [%d] introuvable\n", id)
+		return nil
+	}
+	task := tm.Tasks[i]
+	if task.IsDone() {
+		fmt.Printf("ℹ️ Tâche [%d] déjà terminée\n", id)
+		return nil
+	}
+
+	var open []int
+	for _, child := range tm.descendants(task.UUID) {
+		if !tm.Tasks[child].IsClosed() {
+			open = append(open, child)
+		}
+	}
+	if len(open) > 0 && !cascade {
+		return fmt.Errorf("la tâche [%d] a %d sous-tâche(s) ouverte(s), terminez-les ou utilisez --cascade", id, len(open))
+	}
+
+	for _, blocker := range tm.openBlockers(task) {
+		fmt.Printf("⚠️ Tâche [%d] encore bloquée par [%d] %s\n", id, blocker.ID, blocker.Text)
+	}
+
+	now := time.Now()
+	var spawned []Task
+	for _, j := range append(open, i) {
+		tm.stopTimer(j, now)
+		tm.Tasks[j].Status = StatusDone
+		tm.Tasks[j].Reason = ""
+		tm.Tasks[j].Updated = timestamp(now)
+		if next, ok := tm.spawnNext(j, now); ok {
+			spawned = append(spawned, next)
+		}
+	}
+
+	tm.operation = fmt.Sprintf("done [%d] %s", id, task.Text)
+	if len(open) > 0 {
+		tm.operation += fmt.Sprintf(" (+%d sous-tâches)", len(open))
+	}
+	tm.save()
+
+	fmt.Printf("✅ Tâche [%d] marquée comme terminée\n", id)
+	if len(open) > 0 {
+		fmt.Printf("   %d sous-tâche(s) terminée(s) avec elle\n", len(open))
+	}
+	for _, next := range spawned {
+		fmt.Printf("🔁 Prochaine occurrence : [%d] %s (due: %s)\n", next.ID, next.Text, formatDue(next.Due))
+	}
+	return nil
+}
+
+// printTaskTree affiche des tâches déjà triées sous forme d'arbre : chaque
+// sous-tâche suit sa tâche parente, indentée. Une sous-tâche dont la parente
+// n'est pas affichée (filtre, corbeille) apparaît au premier niveau.
+func (tm *TodoManager) printTaskTree(tasks []Task) {
+	shown := make(map[string]bool)
+	for _, task := range tasks {
+		if task.UUID != "" {
+			shown[task.UUID] = true
+		}
+	}
+
+	children := make(map[string][]Task)
+	var roots []Task
+	for _, task := range tasks {
+		if task.Parent != "" && shown[task.Parent] && task.Parent != task.UUID {
+			children[task.Parent] = append(children[task.Parent], task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+
+	index := tm.newTaskIndex()
+	printed := make(map[string]bool)
+	var walk func(task Task, depth int)
+	walk = func(task Task, depth int) {
+		if task.UUID != "" {
+			if printed[task.UUID] {
+				return
+			}
+			printed[task.UUID] = true
+		}
+		tm.printTask(task, depth, index)
+		for _, child := range children[task.UUID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, task := range roots {
+		walk(task, 0)
+	}
+
+	// Parentés circulaires (fichier modifié à la main) : rien ne doit disparaître
+	for _, task := range tasks {
+		if task.UUID != "" && !printed[task.UUID] {
+			walk(task, 0)
+		}
+	}
+}
+
+// taskIndent préfixe d'une tâche selon sa profondeur dans l'arbre
+func taskIndent(depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	return strings.Repeat("   ", depth-1) + "└─ "
+}
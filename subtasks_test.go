@@ -0,0 +1,82 @@
+// subtasks_test.go - Tests des sous-tâches
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSubtasks_Complete(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Déménager", nil, "", "")
+	if err := tm.AddSubtask(1, "Réserver le camion", nil, "", ""); err != nil {
+		t.Fatalf("AddSubtask: %v", err)
+	}
+	tm.AddSubtask(1, "Faire les cartons", nil, "", "")
+	tm.AddSubtask(3, "Acheter du scotch", nil, "", "")
+
+	if err := tm.AddSubtask(42, "Orpheline", nil, "", ""); err == nil {
+		t.Error("Une tâche parente inexistante doit être refusée")
+	}
+	if task := assertTaskExists(t, tm, 4); task.Parent != tm.Tasks[2].UUID {
+		t.Errorf("La sous-tâche 4 doit pointer vers la tâche 3: %+v", task)
+	}
+
+	tm.Done(2)
+	if done, total := tm.progress(tm.Tasks[0]); done != 1 || total != 3 {
+		t.Errorf("Progression 1/3 attendue (tous niveaux), obtenu %d/%d", done, total)
+	}
+
+	t.Run("bloquée par des sous-tâches ouvertes", func(t *testing.T) {
+		if err := tm.Complete(1, false); err == nil {
+			t.Error("Une tâche avec des sous-tâches ouvertes ne doit pas être terminée")
+		}
+		if assertTaskExists(t, tm, 1).IsDone() {
+			t.Error("La tâche parente doit rester ouverte")
+		}
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		if err := tm.Complete(1, true); err != nil {
+			t.Fatalf("Complete --cascade: %v", err)
+		}
+		for _, task := range reloadManager(t, tm.filename).Tasks {
+			if !task.IsDone() {
+				t.Errorf("Tâche [%d] devrait être terminée par la cascade", task.ID)
+			}
+		}
+		if err := tm.AddSubtask(1, "Trop tard", nil, "", ""); err == nil {
+			t.Error("Une tâche parente terminée ne doit pas recevoir de sous-tâche")
+		}
+	})
+}
+
+func TestSubtasks_CSVRoundTrip(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Projet", nil, "", "")
+	tm.AddSubtask(1, "Étape 1", nil, "", "")
+	tm.AddSubtask(2, "Détail", nil, "", "")
+
+	csvFile := filepath.Join(tempDir, "export.csv")
+	if err := tm.ExportCSV(csvFile); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+
+	imported := newTestManager(filepath.Join(tempDir, "autre.json"), nil)
+	if _, err := imported.ImportCSV(csvFile, "merge", "skip", ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	assertTaskCount(t, imported, 3)
+	for i, task := range imported.Tasks {
+		if task.Parent != tm.Tasks[i].Parent {
+			t.Errorf("Parent de [%d] perdu à l'import: %q au lieu de %q", task.ID, task.Parent, tm.Tasks[i].Parent)
+		}
+	}
+	if done, total := imported.progress(imported.Tasks[0]); done != 0 || total != 2 {
+		t.Errorf("Hiérarchie importée: progression 0/2 attendue, obtenu %d/%d", done, total)
+	}
+}
@@ -0,0 +1,278 @@
+// timelog.go - Suivi du temps (start, stop, log, timesheet)
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeEntry intervalle de travail sur une tâche. Le minuteur en cours est
+// l'entrée sans fin : enregistré avec la tâche, il survit à la fin du processus.
+type TimeEntry struct {
+	Start string `json:"start"`         // Début (RFC 3339)
+	End   string `json:"end,omitempty"` // Fin (RFC 3339), vide tant que le minuteur tourne
+}
+
+// within retourne la part de l'entrée comprise entre from et to ; une
+// entrée en cours compte jusqu'à now
+func (entry TimeEntry) within(from time.Time, to time.Time, now time.Time) time.Duration {
+	start, err := parseTimestamp(entry.Start)
+	if err != nil {
+		return 0
+	}
+	end := now
+	if entry.End != "" {
+		if end, err = parseTimestamp(entry.End); err != nil {
+			return 0
+		}
+	}
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// timeSpent temps passé sur une tâche entre from et to (zéro : sans limite)
+func timeSpent(task Task, from time.Time, to time.Time, now time.Time) time.Duration {
+	if to.IsZero() {
+		to = now
+	}
+	var total time.Duration
+	for _, entry := range task.TimeLog {
+		total += entry.within(from, to, now)
+	}
+	return total
+}
+
+// openEntry retourne l'index de l'entrée en cours d'une tâche (-1 sinon)
+func openEntry(task Task) int {
+	for i, entry := range task.TimeLog {
+		if entry.End == "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// runningTimer retourne l'index de la tâche dont le minuteur tourne (-1 sinon)
+func (tm *TodoManager) runningTimer() int {
+	for i, task := range tm.Tasks {
+		if openEntry(task) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// stopTimer arrête le minuteur de la tâche i s'il tourne et retourne la
+// durée de l'intervalle enregistré
+func (tm *TodoManager) stopTimer(i int, now time.Time) (time.Duration, bool) {
+	j := openEntry(tm.Tasks[i])
+	if j < 0 {
+		return 0, false
+	}
+	log := append([]TimeEntry(nil), tm.Tasks[i].TimeLog...) // Le journal est partagé avec l'historique
+	log[j].End = timestamp(now)
+	tm.Tasks[i].TimeLog = log
+	tm.Tasks[i].Updated = timestamp(now)
+	return log[j].within(time.Time{}, now, now), true
+}
+
+// Start démarre une tâche (statut in-progress) et lance son minuteur. Un
+// seul minuteur tourne à la fois : celui d'une autre tâche est arrêté.
+func (tm *TodoManager) Start(id int) error {
+	i := tm.findTask(id)
+	if i < 0 {
+		return fmt.Errorf("tâche [%d] introuvable", id)
+	}
+	task := tm.Tasks[i]
+	if task.IsClosed() {
+		return fmt.Errorf("la tâche [%d] est %s", id, task.Status)
+	}
+	if openEntry(task) >= 0 {
+		return fmt.Errorf("le minuteur de la tâche [%d] tourne déjà", id)
+	}
+
+	now := time.Now()
+	if running := tm.runningTimer(); running >= 0 {
+		spent, _ := tm.stopTimer(running, now)
+		fmt.Printf("⏹️ Minuteur arrêté : [%d] %s (%s)\n", tm.Tasks[running].ID, tm.Tasks[running].Text, formatDuration(spent))
+	}
+
+	tm.Tasks[i].Status = StatusInProgress
+	tm.Tasks[i].Reason = ""
+	tm.Tasks[i].TimeLog = append(append([]TimeEntry(nil), task.TimeLog...), TimeEntry{Start: timestamp(now)})
+	tm.Tasks[i].Updated = timestamp(now)
+	tm.operation = fmt.Sprintf("start [%d] %s", id, task.Text)
+	tm.save()
+
+	fmt.Printf("%s Tâche [%d] démarrée, minuteur lancé à %s\n", statusIcons[StatusInProgress], id, now.Format("15:04"))
+	return nil
+}
+
+// Stop arrête le minuteur en cours
+func (tm *TodoManager) Stop() error {
+	i := tm.runningTimer()
+	if i < 0 {
+		return fmt.Errorf("aucun minuteur en cours")
+	}
+
+	now := time.Now()
+	spent, _ := tm.stopTimer(i, now)
+	task := tm.Tasks[i]
+	tm.operation = fmt.Sprintf("stop [%d] %s", task.ID, task.Text)
+	tm.save()
+
+	fmt.Printf("⏹️ Minuteur arrêté : [%d] %s (%s, total %s)\n", task.ID, task.Text, formatDuration(spent), formatDuration(timeSpent(task, time.Time{}, now, now)))
+	return nil
+}
+
+// LogTime ajoute à une tâche une entrée manuelle de durée spent, terminée à end
+func (tm *TodoManager) LogTime(id int, spent time.Duration, end time.Time) error {
+	i := tm.findTask(id)
+	if i < 0 {
+		return fmt.Errorf("tâche [%d] introuvable", id)
+	}
+	if spent <= 0 {
+		return fmt.Errorf("durée invalide")
+	}
+
+	task := tm.Tasks[i]
+	entry := TimeEntry{Start: timestamp(end.Add(-spent)), End: timestamp(end)}
+	tm.Tasks[i].TimeLog = append(append([]TimeEntry(nil), task.TimeLog...), entry)
+	tm.Tasks[i].Updated = timestamp(time.Now())
+	tm.operation = fmt.Sprintf("log [%d] %s %s", id, formatDuration(spent), task.Text)
+	tm.save()
+
+	fmt.Printf("⏱️ %s ajoutées à la tâche [%d] %s\n", formatDuration(spent), id, task.Text)
+	return nil
+}
+
+// hoursMinutesPattern durée au format 1h30
+var hoursMinutesPattern = regexp.MustCompile(`^(\d+)h(\d{1,2})$`)
+
+// parseTimeSpent lit une durée de travail : 45m, 1h30, 1h30m, 1.5h ou un
+// nombre de minutes
+func parseTimeSpent(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if minutes, err := strconv.Atoi(value); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	if m := hoursMinutesPattern.FindStringSubmatch(value); m != nil {
+		value = m[1] + "h" + m[2] + "m"
+	}
+	spent, err := time.ParseDuration(value)
+	if err != nil || spent <= 0 {
+		return 0, fmt.Errorf("durée '%s' invalide (ex: 45m, 1h30, 2h)", value)
+	}
+	return spent, nil
+}
+
+// formatDuration affiche une durée arrondie à la minute : 45m, 1h05
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02d", minutes/60, minutes%60)
+}
+
+// timeLabel temps passé affiché par printTask (▶️ si le minuteur tourne)
+func timeLabel(task Task, now time.Time) string {
+	if len(task.TimeLog) == 0 {
+		return ""
+	}
+	spent := formatDuration(timeSpent(task, time.Time{}, now, now))
+	if openEntry(task) >= 0 {
+		return " " + ColorGreen + "▶️ " + spent + ColorReset
+	}
+	return " " + ColorGray + "⏱️ " + spent + ColorReset
+}
+
+// Timesheet affiche le temps passé entre from et to par projet (tags
+// +projet), tâches archivées comprises. Une tâche de plusieurs projets
+// compte pour chacun, mais une seule fois dans le total.
+func (tm *TodoManager) Timesheet(from time.Time, to time.Time) error {
+	archived, err := tm.ArchivedTasks()
+	if err != nil {
+		return err
+	}
+
+	type line struct {
+		task  Task
+		spent time.Duration
+	}
+	now := time.Now()
+	projects := make(map[string][]line)
+	totals := make(map[string]time.Duration)
+	var total time.Duration
+	for _, task := range append(append([]Task(nil), tm.Tasks...), archived...) {
+		spent := timeSpent(task, from, to, now)
+		if spent == 0 {
+			continue
+		}
+		total += spent
+
+		var names []string
+		for _, tag := range task.Tags {
+			if strings.HasPrefix(tag, "+") {
+				names = append(names, tag)
+			}
+		}
+		if len(names) == 0 {
+			names = []string{"(sans projet)"}
+		}
+		for _, name := range names {
+			projects[name] = append(projects[name], line{task, spent})
+			totals[name] += spent
+		}
+	}
+
+	fmt.Printf("⏱️ Temps passé du %s au %s\n", from.Format("2006-01-02"), to.Add(-time.Second).Format("2006-01-02"))
+	if total == 0 {
+		fmt.Println("📝 Aucun temps enregistré sur la période")
+		return nil
+	}
+
+	var names []string
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] != totals[names[j]] {
+			return totals[names[i]] > totals[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		fmt.Printf("\n%s%-30s %8s%s\n", ColorBlue, name, formatDuration(totals[name]), ColorReset)
+		for _, l := range projects[name] {
+			fmt.Printf("   [%d] %-25s %8s\n", l.task.ID, l.task.Text, formatDuration(l.spent))
+		}
+	}
+	fmt.Printf("\n%s%-30s %8s%s\n", ColorBold, "Total", formatDuration(total), ColorReset)
+	return nil
+}
+
+// timesheetPeriod retourne le début et la fin (exclue) de la semaine ou du
+// mois contenant now
+func timesheetPeriod(month bool, now time.Time) (time.Time, time.Time) {
+	today := startOfDay(now)
+	if month {
+		from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return from, from.AddDate(0, 1, 0)
+	}
+	from := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // Lundi
+	return from, from.AddDate(0, 0, 7)
+}
@@ -0,0 +1,123 @@
+// timelog_test.go - Tests du suivi du temps
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimer_StartStop(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Réviser Go", []string{"+dev"}, "", "")
+	tm.Add("Écrire le rapport", []string{"+doc"}, "", "")
+
+	if err := tm.Stop(); err == nil {
+		t.Error("Arrêter sans minuteur doit échouer")
+	}
+	if err := tm.Start(1); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := tm.Start(1); err == nil {
+		t.Error("Un minuteur déjà lancé doit être signalé")
+	}
+
+	t.Run("survit au rechargement", func(t *testing.T) {
+		reloaded := reloadManager(t, tm.filename)
+		if i := reloaded.runningTimer(); i < 0 || reloaded.Tasks[i].ID != 1 {
+			t.Fatalf("Minuteur de la tâche 1 attendu après rechargement")
+		}
+		if reloaded.Tasks[0].Status != StatusInProgress {
+			t.Errorf("La tâche démarrée doit être en cours: %+v", reloaded.Tasks[0])
+		}
+	})
+
+	t.Run("un seul minuteur", func(t *testing.T) {
+		if err := tm.Start(2); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		if openEntry(tm.Tasks[0]) >= 0 || openEntry(tm.Tasks[1]) < 0 {
+			t.Errorf("Le minuteur de la tâche 1 doit être arrêté: %+v", tm.Tasks)
+		}
+	})
+
+	t.Run("arrêté à la complétion", func(t *testing.T) {
+		tm.Done(2)
+		if tm.runningTimer() >= 0 {
+			t.Error("Terminer la tâche doit arrêter son minuteur")
+		}
+		if err := tm.Start(2); err == nil {
+			t.Error("Une tâche terminée ne peut pas être démarrée")
+		}
+	})
+}
+
+func TestLogTime(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Réviser Go", []string{"+dev"}, "", "")
+	end := time.Date(2025, 7, 16, 18, 0, 0, 0, time.Local)
+	if err := tm.LogTime(1, 45*time.Minute, end); err != nil {
+		t.Fatalf("LogTime: %v", err)
+	}
+	tm.LogTime(1, 90*time.Minute, end.AddDate(0, 0, -7))
+	if err := tm.LogTime(42, time.Hour, end); err == nil {
+		t.Error("Une tâche inexistante doit être refusée")
+	}
+
+	task := assertTaskExists(t, reloadManager(t, tm.filename), 1)
+	now := end.Add(time.Hour)
+	if got := timeSpent(*task, time.Time{}, time.Time{}, now); got != 135*time.Minute {
+		t.Errorf("Total de 2h15 attendu, obtenu %s", got)
+	}
+
+	from, to := timesheetPeriod(false, now)
+	if from.Format("2006-01-02") != "2025-07-14" || to.Format("2006-01-02") != "2025-07-21" {
+		t.Errorf("Semaine du lundi 14 au lundi 21 attendue: %s - %s", from, to)
+	}
+	if got := timeSpent(*task, from, to, now); got != 45*time.Minute {
+		t.Errorf("Seules les 45m de la semaine comptent, obtenu %s", got)
+	}
+	// Une entrée à cheval sur le début de la période est coupée
+	if got := timeSpent(*task, end.Add(-15*time.Minute), to, now); got != 15*time.Minute {
+		t.Errorf("15m attendues après découpe, obtenu %s", got)
+	}
+}
+
+func TestParseTimeSpent(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"45m", 45 * time.Minute},
+		{"1h30", 90 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"90", 90 * time.Minute},
+		{"2H", 2 * time.Hour},
+		{"0", 0},
+		{"-1h", 0},
+		{"beaucoup", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTimeSpent(tt.value)
+			if tt.want == 0 {
+				if err == nil {
+					t.Errorf("parseTimeSpent(%q) devrait échouer", tt.value)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseTimeSpent(%q) = %s, %v ; attendu %s", tt.value, got, err, tt.want)
+			}
+		})
+	}
+
+	if formatDuration(45*time.Minute) != "45m" || formatDuration(65*time.Minute+20*time.Second) != "1h05" {
+		t.Errorf("formatDuration inattendu: %s, %s", formatDuration(45*time.Minute), formatDuration(65*time.Minute))
+	}
+}
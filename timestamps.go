@@ -0,0 +1,106 @@
+// timestamps.go - Horodatages RFC 3339 et échéances avec heure
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// legacyTimestampLayout format des horodatages avant le schéma v4 (heure locale, sans décalage)
+const legacyTimestampLayout = "2006-01-02 15:04:05"
+
+// localTimeLayouts formats acceptés pour une date et heure saisie sans décalage (heure locale)
+var localTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+}
+
+// timestamp formate un horodatage enregistré (Created, Updated, Deleted) :
+// RFC 3339 avec le décalage horaire, comparable d'une machine à l'autre
+func timestamp(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// parseTimestamp lit un horodatage RFC 3339, ou un ancien horodatage sans
+// décalage interprété en heure locale
+func parseTimestamp(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("horodatage '%s' invalide", value)
+}
+
+// formatTimestamp affiche un horodatage dans le fuseau local
+func formatTimestamp(value string) string {
+	t, err := parseTimestamp(value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format(legacyTimestampLayout)
+}
+
+// parseDue lit une échéance : date seule (YYYY-MM-DD), date et heure
+// locales ("YYYY-MM-DD HH:MM") ou RFC 3339. hasTime est faux pour une date
+// seule, qui vaut minuit dans loc.
+func parseDue(value string, loc *time.Location) (due time.Time, hasTime bool, err error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return day, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), true, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("date '%s' invalide", value)
+}
+
+// formatDueValue enregistre une échéance : date seule, ou RFC 3339 avec heure
+func formatDueValue(due time.Time, hasTime bool) string {
+	if hasTime {
+		return due.Format(time.RFC3339)
+	}
+	return due.Format("2006-01-02")
+}
+
+// normalizeDue convertit une date saisie, absolue ou relative (voir
+// parseDate), vers sa forme enregistrée (une date et heure locale reçoit le
+// décalage du fuseau local)
+func normalizeDue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	due, hasTime, err := parseDate(value, clock())
+	if err != nil {
+		return "", err
+	}
+	return formatDueValue(due, hasTime), nil
+}
+
+// validStoredDate indique si une date enregistrée est valide : vide, date
+// seule ou date et heure. Les expressions relatives ne valent qu'à la saisie.
+func validStoredDate(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, _, err := parseDue(value, time.Local)
+	return err == nil
+}
+
+// formatDue affiche une échéance dans le fuseau local
+func formatDue(value string) string {
+	due, hasTime, err := parseDue(value, time.Local)
+	if err != nil || !hasTime {
+		return value
+	}
+	return due.Format("2006-01-02 15:04")
+}
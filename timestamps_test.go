@@ -0,0 +1,137 @@
+// timestamps_test.go - Tests des horodatages et échéances avec heure
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsNewer_TimeZones(t *testing.T) {
+	tm := &TodoManager{}
+
+	// 10h à Paris (UTC+2) est antérieur à 9h30 à Londres (UTC+1)
+	paris, london := "2025-07-20T10:00:00+02:00", "2025-07-20T09:30:00+01:00"
+	if tm.isNewer(paris, london) || !tm.isNewer(london, paris) {
+		t.Errorf("Comparaison en tenant compte du décalage attendue")
+	}
+	if !tm.isNewer(timestamp(time.Now()), "2025-07-09 10:00:00") {
+		t.Error("Un ancien horodatage sans décalage doit rester comparable")
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	paris := time.FixedZone("Paris", 2*3600)
+
+	tests := []struct {
+		value   string
+		want    string
+		hasTime bool
+		wantErr bool
+	}{
+		{"2025-07-20", "2025-07-20T00:00:00+02:00", false, false},
+		{"2025-07-20 14:00", "2025-07-20T14:00:00+02:00", true, false},
+		{"2025-07-20T14:00", "2025-07-20T14:00:00+02:00", true, false},
+		{"2025-07-20T12:00:00Z", "2025-07-20T14:00:00+02:00", true, false},
+		{"20/07/2025", "", false, true},
+		{"2025-07-20 25:00", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			due, hasTime, err := parseDue(tt.value, paris)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseDue(%q) devrait échouer", tt.value)
+				}
+				return
+			}
+			if err != nil || due.Format(time.RFC3339) != tt.want || hasTime != tt.hasTime {
+				t.Errorf("parseDue(%q) = %s, %v, %v ; attendu %s, %v", tt.value, due.Format(time.RFC3339), hasTime, err, tt.want, tt.hasTime)
+			}
+		})
+	}
+}
+
+func TestNormalizeDue(t *testing.T) {
+	if due, err := normalizeDue("2025-07-20"); err != nil || due != "2025-07-20" {
+		t.Errorf("Une date seule doit rester une date: %q, %v", due, err)
+	}
+
+	due, err := normalizeDue("2025-07-20 14:00")
+	if err != nil {
+		t.Fatalf("normalizeDue: %v", err)
+	}
+	parsed, err := time.Parse(time.RFC3339, due)
+	if err != nil || parsed.Local().Format("2006-01-02 15:04") != "2025-07-20 14:00" {
+		t.Errorf("Échéance RFC 3339 en heure locale attendue: %q, %v", due, err)
+	}
+	if formatDue(due) != "2025-07-20 14:00" {
+		t.Errorf("Affichage local attendu, obtenu %q", formatDue(due))
+	}
+}
+
+func TestMigrateTimestamps(t *testing.T) {
+	legacy := `{"schemaVersion": 3, "tasks": [
+		{"id": 1, "uuid": "a", "text": "Ancienne", "status": "todo", "created": "2025-07-09 10:00:00", "updated": "2025-07-09 11:00:00"}
+	], "trash": [{"id": 2, "uuid": "b", "text": "Supprimée", "status": "done", "created": "2025-07-01 08:00:00", "updated": "2025-07-01 08:00:00", "deleted": "2025-07-02 09:00:00"}],
+	"tombstones": [{"uuid": "c", "deleted": "2025-07-03 12:00:00"}],
+	"nextId": 3}`
+
+	data, err := migrateTodoData([]byte(legacy), nil)
+	if err != nil {
+		t.Fatalf("Migration: %v", err)
+	}
+
+	var tm TodoManager
+	if err := json.Unmarshal(data, &tm); err != nil {
+		t.Fatalf("Décodage: %v", err)
+	}
+	for _, value := range []string{tm.Tasks[0].Created, tm.Tasks[0].Updated, tm.Trash[0].Deleted, tm.Tombstones[0].Deleted} {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			t.Errorf("Horodatage RFC 3339 attendu, obtenu %q", value)
+		}
+	}
+	if got := formatTimestamp(tm.Tasks[0].Updated); got != "2025-07-09 11:00:00" {
+		t.Errorf("L'heure locale doit être conservée: %q", got)
+	}
+}
+
+func TestHistoryAndEvents_Timestamps(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	tm.Add("Tâche", nil, "", "")
+
+	history, err := tm.loadHistory()
+	if err != nil || len(history.Entries) != 1 {
+		t.Fatalf("Une entrée d'historique attendue: %v", err)
+	}
+	eventsFile := filepath.Join(tempDir, "todo.events")
+	newTestManager(filepath.Join(tempDir, "events.json"), NewEventStore(eventsFile)).Add("Tâche", nil, "", "")
+	events, err := readEvents(eventsFile)
+	if err != nil || len(events) == 0 {
+		t.Fatalf("Un événement attendu: %v", err)
+	}
+
+	for _, value := range []string{history.Entries[0].Time, events[0].Time} {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			t.Errorf("Horodatage RFC 3339 attendu, obtenu %q", value)
+		}
+	}
+	// Historiques et journaux écrits par une version précédente
+	if got := formatTimestamp("2025-07-09 10:00:00"); got != "2025-07-09 10:00:00" {
+		t.Errorf("Ancien horodatage mal affiché: %q", got)
+	}
+}
+
+func TestNextOccurrence_KeepsTime(t *testing.T) {
+	task := Task{Due: time.Date(2025, 7, 15, 14, 30, 0, 0, time.Local).Format(time.RFC3339), Recur: "daily"}
+	next, ok := nextOccurrence(task, time.Date(2025, 7, 15, 16, 0, 0, 0, time.Local))
+	if !ok {
+		t.Fatal("Occurrence suivante attendue")
+	}
+	if got := formatDue(next.Due); got != "2025-07-16 14:30" {
+		t.Errorf("L'occurrence suivante doit garder l'heure, obtenu %q", got)
+	}
+}
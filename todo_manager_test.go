@@ -42,7 +42,7 @@ func createSampleTasks() []Task {
 			ID:       1,
 			UUID:     "test-uuid-1",
 			Text:     "Tâche de test 1",
-			Done:     false,
+			Status:   StatusTodo,
 			Priority: "high",
 			Due:      "2025-07-20",
 			Tags:     []string{"+dev", "@bureau"},
@@ -53,7 +53,7 @@ func createSampleTasks() []Task {
 			ID:       2,
 			UUID:     "test-uuid-2",
 			Text:     "Tâche de test 2",
-			Done:     true,
+			Status:   StatusDone,
 			Priority: "medium",
 			Tags:     []string{"+perso", "@maison"},
 			Created:  "2025-07-08 15:30:00",
@@ -156,7 +156,7 @@ func TestTodoManager_Add(t *testing.T) {
 			if lastTask.UUID == "" {
 				t.Error("UUID ne doit pas être vide")
 			}
-			if lastTask.Done {
+			if lastTask.IsDone() {
 				t.Error("Nouvelle tâche ne doit pas être marquée comme terminée")
 			}
 		})
@@ -177,7 +177,7 @@ func TestTodoManager_Done(t *testing.T) {
 
 		// Vérifier que la tâche est marquée comme terminée
 		task := assertTaskExists(t, tm, taskID)
-		if task != nil && !task.Done {
+		if task != nil && !task.IsDone() {
 			t.Error("Tâche devrait être marquée comme terminée")
 		}
 	})
@@ -518,7 +518,7 @@ func TestIntegration_CompleteWorkflow(t *testing.T) {
 		// 2. Marquer une tâche comme terminée
 		tm.Done(1)
 		task1 := assertTaskExists(t, tm, 1)
-		if task1 != nil && !task1.Done {
+		if task1 != nil && !task1.IsDone() {
 			t.Error("Tâche 1 devrait être marquée comme terminée")
 		}
 
@@ -672,7 +672,7 @@ func TestTodoManager_ImportCSV(t *testing.T) {
 			ID:      1,
 			UUID:    "123e4567-e89b-42d3-a456-426614174000",
 			Text:    "Tâche existante",
-			Done:    false,
+			Status:  StatusTodo,
 			Created: "2025-07-09 10:00:00",
 			Updated: "2025-07-09 10:00:00",
 		}}
@@ -696,7 +696,7 @@ func TestTodoManager_ImportCSV(t *testing.T) {
 			ID:      1,
 			UUID:    "123e4567-e89b-42d3-a456-426614174000",
 			Text:    "Tâche existante",
-			Done:    false,
+			Status:  StatusTodo,
 			Created: "2025-07-09 10:00:00",
 			Updated: "2025-07-09 10:00:00",
 		}}
@@ -792,7 +792,7 @@ func TestTodoManager_ImportCSV(t *testing.T) {
 			ID:      1,
 			UUID:    "123e4567-e89b-42d3-a456-426614174000",
 			Text:    "Tâche existante",
-			Done:    false,
+			Status:    StatusTodo,
 			Created: "2025-07-09 10:00:00",
 			Updated: "2025-07-09 10:00:00",
 		}}
@@ -816,7 +816,7 @@ func TestTodoManager_ImportCSV(t *testing.T) {
 			ID:      1,
 			UUID:    "123e4567-e89b-42d3-a456-426614174000",
 			Text:    "Tâche existante",
-			Done:    false,
+			Status:    StatusTodo,
 			Created: "2025-07-09 10:00:00",
 			Updated: "2025-07-09 10:00:00",
 		}}
@@ -1101,7 +1101,7 @@ func TestExportImportRoundTrip(t *testing.T) {
 			t.Errorf("Texte différent pour tâche %d: %s vs %s",
 				i, originalTask.Text, importedTask.Text)
 		}
-		if importedTask.Done != originalTask.Done {
+		if importedTask.IsDone() != originalTask.IsDone() {
 			t.Errorf("Statut Done différent pour tâche %d", i)
 		}
 		if importedTask.Priority != originalTask.Priority {
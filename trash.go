@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tombstone trace d'une tâche définitivement supprimée : son UUID empêche
+// un import ultérieur de la faire revenir
+type Tombstone struct {
+	UUID    string `json:"uuid"`
+	Deleted string `json:"deleted"`
+}
+
+// moveToTrash place des tâches dans la corbeille avec leur date de suppression
+func (tm *TodoManager) moveToTrash(tasks ...Task) {
+	now := timestamp(time.Now())
+	for _, task := range tasks {
+		task.Deleted = now
+		tm.Trash = append(tm.Trash, task)
+	}
+}
+
+// isDeleted indique si un UUID appartient à une tâche supprimée
+// (corbeille ou suppression définitive)
+func (tm *TodoManager) isDeleted(uuid string) bool {
+	if uuid == "" {
+		return false
+	}
+	for _, task := range tm.Trash {
+		if task.UUID == uuid {
+			return true
+		}
+	}
+	for _, tombstone := range tm.Tombstones {
+		if tombstone.UUID == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+// findInTrash retrouve une tâche de la corbeille par ID ou par UUID (ou début d'UUID)
+func (tm *TodoManager) findInTrash(ref string) (int, error) {
+	var matches []int
+	if id, err := strconv.Atoi(ref); err == nil {
+		for i, task := range tm.Trash {
+			if task.ID == id {
+				matches = append(matches, i)
+			}
+		}
+	} else if len(ref) >= 4 {
+		for i, task := range tm.Trash {
+			if strings.HasPrefix(task.UUID, strings.ToLower(ref)) {
+				matches = append(matches, i)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return -1, fmt.Errorf("aucune tâche '%s' dans la corbeille", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return -1, fmt.Errorf("plusieurs tâches '%s' dans la corbeille, précisez l'UUID", ref)
+	}
+}
+
+// Restore remet une tâche de la corbeille dans la liste. Elle garde son ID
+// s'il est libre, sinon elle en reçoit un nouveau.
+func (tm *TodoManager) Restore(ref string) error {
+	index, err := tm.findInTrash(ref)
+	if err != nil {
+		return err
+	}
+
+	task := tm.Trash[index]
+	oldID := task.ID
+	task.Deleted = ""
+	for _, existing := range tm.Tasks {
+		if existing.ID == task.ID {
+			task.ID = tm.NextID
+			break
+		}
+	}
+	if task.ID >= tm.NextID {
+		tm.NextID = task.ID + 1
+	}
+
+	tm.Trash = append(tm.Trash[:index], tm.Trash[index+1:]...)
+	tm.Tasks = append(tm.Tasks, task)
+	if task.UUID != "" {
+		// Une suppression définitive annulée ne doit plus écarter la tâche (import, sync)
+		tm.Tombstones = updateTombstones(tm.Tombstones, nil, []Tombstone{{UUID: task.UUID}})
+	}
+	tm.operation = fmt.Sprintf("restore [%d] %s", task.ID, task.Text)
+	if err := tm.save(); err != nil {
+		return err
+	}
+
+	if task.ID != oldID {
+		fmt.Printf("♻️  Tâche restaurée : [%d] %s (ancien ID %d déjà pris)\n", task.ID, task.Text, oldID)
+	} else {
+		fmt.Printf("♻️  Tâche restaurée : [%d] %s\n", task.ID, task.Text)
+	}
+	return nil
+}
+
+// PrintTrash affiche la corbeille, les suppressions les plus récentes en premier
+func (tm *TodoManager) PrintTrash() {
+	if len(tm.Trash) == 0 {
+		fmt.Println("🗑️  Corbeille vide")
+		return
+	}
+
+	for i := len(tm.Trash) - 1; i >= 0; i-- {
+		task := tm.Trash[i]
+		uuid := task.UUID
+		if len(uuid) > 8 {
+			uuid = uuid[:8]
+		}
+		fmt.Printf("%s[%d] %s %s[supprimée le %s] %s%s\n",
+			ColorGray, task.ID, task.Text, ColorReset, formatTimestamp(task.Deleted), ColorGray+uuid, ColorReset)
+	}
+	fmt.Printf("\n🗑️  %d tâche(s) dans la corbeille. 'todo restore <id|uuid>' pour en récupérer une.\n", len(tm.Trash))
+}
+
+// EmptyTrash supprime définitivement les tâches de la corbeille plus anciennes
+// que olderThan (toutes si 0). Leurs UUID restent connus pour l'import.
+func (tm *TodoManager) EmptyTrash(olderThan time.Duration) int {
+	cutoff := time.Now().Add(-olderThan)
+	var kept []Task
+	var purged int
+
+	for _, task := range tm.Trash {
+		deleted, err := parseTimestamp(task.Deleted)
+		if olderThan > 0 && err == nil && deleted.After(cutoff) {
+			kept = append(kept, task)
+			continue
+		}
+		if task.UUID != "" {
+			tm.Tombstones = append(tm.Tombstones, Tombstone{UUID: task.UUID, Deleted: task.Deleted})
+		}
+		purged++
+	}
+
+	if purged == 0 {
+		fmt.Println("🗑️  Aucune tâche à supprimer définitivement")
+		return 0
+	}
+
+	tm.Trash = kept
+	tm.operation = fmt.Sprintf("trash empty (%d tâches)", purged)
+	tm.save()
+
+	fmt.Printf("🗑️  %d tâche(s) supprimée(s) définitivement\n", purged)
+	return purged
+}
+
+// parseAge convertit une durée du type 30d, 2w, 12h ou 90m
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if number, found := strings.CutSuffix(value, suffix); found {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("durée invalide '%s' (ex: 30d, 2w, 12h)", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("durée invalide '%s' (ex: 30d, 2w, 12h)", value)
+	}
+	return duration, nil
+}
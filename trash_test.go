@@ -0,0 +1,154 @@
+// trash_test.go - Tests de la corbeille
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrash_RemoveAndRestore(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Tâche 1", []string{"+dev"}, "high", "")
+	tm.Add("Tâche 2", nil, "", "")
+	tm.Remove(1)
+
+	t.Run("tâche placée dans la corbeille", func(t *testing.T) {
+		tm := reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 1)
+		if len(tm.Trash) != 1 || tm.Trash[0].Text != "Tâche 1" {
+			t.Fatalf("Corbeille inattendue: %+v", tm.Trash)
+		}
+		if tm.Trash[0].Deleted == "" {
+			t.Error("La date de suppression devrait être renseignée")
+		}
+	})
+
+	t.Run("restauration par ID", func(t *testing.T) {
+		if err := tm.Restore("1"); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+
+		tm := reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 2)
+		if task := assertTaskExists(t, tm, 1); task != nil && task.Deleted != "" {
+			t.Errorf("La date de suppression devrait être effacée: %s", task.Deleted)
+		}
+		if len(tm.Trash) != 0 {
+			t.Errorf("La corbeille devrait être vide: %+v", tm.Trash)
+		}
+	})
+
+	t.Run("restauration par UUID avec ID déjà pris", func(t *testing.T) {
+		tm.Clear(true)
+		tm.Add("Nouvelle tâche", nil, "", "")
+
+		uuid := tm.Trash[0].UUID
+		if err := tm.Restore(uuid[:8]); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+
+		tm := reloadManager(t, tm.filename)
+		assertTaskCount(t, tm, 2)
+		restored := tm.Tasks[1]
+		if restored.UUID != uuid || restored.ID == 1 {
+			t.Errorf("Nouvel ID attendu pour la tâche restaurée: %+v", restored)
+		}
+		if tm.NextID <= restored.ID {
+			t.Errorf("NextID (%d) devrait dépasser l'ID restauré (%d)", tm.NextID, restored.ID)
+		}
+	})
+
+	t.Run("référence introuvable ou ambiguë", func(t *testing.T) {
+		if err := tm.Restore("42"); err == nil {
+			t.Error("Une tâche absente de la corbeille ne peut pas être restaurée")
+		}
+		tm.Trash = append(tm.Trash, Task{ID: 7, UUID: "aaaa-1"}, Task{ID: 7, UUID: "bbbb-2"})
+		if err := tm.Restore("7"); err == nil {
+			t.Error("Un ID ambigu devrait être refusé")
+		}
+	})
+}
+
+func TestTrash_Empty(t *testing.T) {
+	tm, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Trash = []Task{
+		{ID: 1, UUID: "ancienne", Text: "Ancienne", Deleted: time.Now().AddDate(0, 0, -40).Format("2006-01-02 15:04:05")},
+		{ID: 2, UUID: "recente", Text: "Récente", Deleted: time.Now().Format("2006-01-02 15:04:05")},
+	}
+
+	if purged := tm.EmptyTrash(30 * 24 * time.Hour); purged != 1 {
+		t.Errorf("1 tâche attendue, obtenu %d", purged)
+	}
+	if len(tm.Trash) != 1 || tm.Trash[0].Text != "Récente" {
+		t.Errorf("Seule la tâche récente devrait rester: %+v", tm.Trash)
+	}
+
+	if purged := tm.EmptyTrash(0); purged != 1 {
+		t.Errorf("1 tâche attendue, obtenu %d", purged)
+	}
+
+	tm = reloadManager(t, tm.filename)
+	if len(tm.Trash) != 0 || len(tm.Tombstones) != 2 {
+		t.Errorf("Corbeille vide et 2 UUID conservés attendus: %+v %+v", tm.Trash, tm.Tombstones)
+	}
+	if !tm.isDeleted("ancienne") || tm.isDeleted("inconnue") {
+		t.Error("isDeleted devrait reconnaître les UUID supprimés")
+	}
+}
+
+func TestTrash_ImportSkipsDeleted(t *testing.T) {
+	tm, tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tm.Add("Tâche conservée", nil, "", "")
+	tm.Add("Tâche supprimée", nil, "", "")
+
+	csvFile := filepath.Join(tempDir, "export.csv")
+	if err := tm.ExportCSV(csvFile); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	tm.Remove(2)
+	tm.EmptyTrash(0)
+
+	result, err := tm.ImportCSV(csvFile, "merge", "update", ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if result.NewTasks != 0 || result.SkippedTasks != 1 {
+		t.Errorf("La tâche supprimée doit être ignorée: %+v", result)
+	}
+
+	for _, task := range reloadManager(t, tm.filename).Tasks {
+		if task.Text == "Tâche supprimée" {
+			t.Error("La tâche supprimée a été réimportée")
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"trente", 0, true},
+		{"-3d", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAge(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v", tt.value, got, err)
+		}
+	}
+}